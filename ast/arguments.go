@@ -0,0 +1,29 @@
+package ast
+
+import "github.com/ekediala/jian/token"
+
+// KeywordArgument passes an argument by parameter name, as in f(b: 2).
+type KeywordArgument struct {
+	Token token.Token // the name token
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode()      {}
+func (ka *KeywordArgument) TokenLiteral() string { return ka.Token.Literal }
+func (ka *KeywordArgument) String() string {
+	return ka.Name.String() + ": " + ka.Value.String()
+}
+
+// SpreadExpression passes the elements of an array as separate arguments,
+// as in f(...args).
+type SpreadExpression struct {
+	Token token.Token // the ... token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
@@ -26,10 +26,21 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
+// IndexExpression reads an element of an array, hash or string, as in
+// xs[0], or a slice of an array or string, as in xs[1:-1:2]. An optional
+// index expression, as in xs?[0], is null if xs is null.
 type IndexExpression struct {
-	Token token.Token // the [ Token
-	Left  Expression
-	Index Expression
+	Token    token.Token // the [ or ?[ Token
+	Left     Expression
+	Index    Expression // nil for slices
+	Optional bool
+
+	// Slice is set for slices, whose Start, End and Step are nil when they
+	// are omitted.
+	Slice bool
+	Start Expression
+	End   Expression
+	Step  Expression
 }
 
 func (ie *IndexExpression) expressionNode() {}
@@ -41,10 +52,30 @@ func (ie *IndexExpression) String() string {
 
 	s.WriteByte('(')
 	s.WriteString(ie.Left.String())
+	if ie.Optional {
+		s.WriteByte('?')
+	}
 	s.WriteByte('[')
-	s.WriteString(ie.Index.String())
+	if ie.Slice {
+		writeOptional(&s, ie.Start)
+		s.WriteByte(':')
+		writeOptional(&s, ie.End)
+		if ie.Step != nil {
+			s.WriteByte(':')
+			s.WriteString(ie.Step.String())
+		}
+	} else {
+		s.WriteString(ie.Index.String())
+	}
 	s.WriteByte(']')
 	s.WriteByte(')')
 
 	return s.String()
 }
+
+// writeOptional writes exp to s unless it is nil.
+func writeOptional(s *strings.Builder, exp Expression) {
+	if exp != nil {
+		s.WriteString(exp.String())
+	}
+}
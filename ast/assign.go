@@ -0,0 +1,17 @@
+package ast
+
+import "github.com/ekediala/jian/token"
+
+// AssignStatement stores Value in the place Target names, such as a struct
+// field in p.x = 1 or an element in xs[0] = 1.
+type AssignStatement struct {
+	Token  token.Token // the = token
+	Target Expression
+	Value  Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignStatement) String() string {
+	return as.Target.String() + " = " + as.Value.String() + ";"
+}
@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// ClassStatement declares a class, as in
+//
+//	class Dog extends Animal { fn speak() { ... } }
+//
+// and binds it to Name. Calling the class creates an instance and runs its
+// init method, if it has one.
+type ClassStatement struct {
+	Token   token.Token // the class token
+	Name    *Identifier
+	Super   *Identifier // the class extended, if any
+	Methods []*FunctionStatement
+}
+
+func (cs *ClassStatement) statementNode()       {}
+func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ClassStatement) String() string {
+	var out strings.Builder
+	out.WriteString("class ")
+	out.WriteString(cs.Name.String())
+	if cs.Super != nil {
+		out.WriteString(" extends ")
+		out.WriteString(cs.Super.String())
+	}
+	out.WriteString(" {")
+	for _, method := range cs.Methods {
+		out.WriteString(" ")
+		out.WriteString(method.String())
+	}
+	if len(cs.Methods) > 0 {
+		out.WriteString(" ")
+	}
+	out.WriteString("}")
+	return out.String()
+}
@@ -9,23 +9,91 @@ import (
 type FunctionLiteral struct {
 	Token      token.Token // the fn token
 	Parameters []*Identifier
+	Defaults   []Expression     // parallel to Parameters; nil entries are required parameters
+	Patterns   []Pattern        // parallel to Parameters; non-nil entries destructure their argument
+	Rest       *Identifier      // collects extra arguments into an array, if set
+	ParamTypes []TypeExpression // parallel to Parameters; nil entries are unannotated
+	RestType   TypeExpression   // annotation of Rest, if any
+	ReturnType TypeExpression   // annotation of the result, if any
 	Body       *BlockStatement
+	Generator  bool // whether Body yields; see Yields
+}
+
+// Default returns the default value of the i-th parameter, or nil if the
+// parameter is required.
+func (fl *FunctionLiteral) Default(i int) Expression {
+	if i < len(fl.Defaults) {
+		return fl.Defaults[i]
+	}
+	return nil
+}
+
+// ParamType returns the annotated type of the i-th parameter, or nil if it
+// has none.
+func (fl *FunctionLiteral) ParamType(i int) TypeExpression {
+	if i < len(fl.ParamTypes) {
+		return fl.ParamTypes[i]
+	}
+	return nil
+}
+
+// Pattern returns the pattern the i-th parameter destructures its argument
+// with, or nil if the parameter is a plain name. The Identifier of such a
+// parameter is named after the pattern, as in [a, b].
+func (fl *FunctionLiteral) Pattern(i int) Pattern {
+	if i < len(fl.Patterns) {
+		return fl.Patterns[i]
+	}
+	return nil
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
-	var out strings.Builder
+	return fl.TokenLiteral() + fl.parameterList() + " " + fl.Body.String()
+}
+
+// parameterList formats the parameters and return type as written, such
+// as (a: int, b = 10) -> int.
+func (fl *FunctionLiteral) parameterList() string {
 	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+	for i, p := range fl.Parameters {
+		param := p.String()
+		if typ := fl.ParamType(i); typ != nil {
+			param += ": " + typ.String()
+		}
+		if def := fl.Default(i); def != nil {
+			param += " = " + def.String()
+		}
+		params = append(params, param)
 	}
-	out.WriteString(fl.TokenLiteral())
-	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") ")
-	out.WriteString(fl.Body.String())
-	return out.String()
+	if fl.Rest != nil {
+		rest := "..." + fl.Rest.String()
+		if fl.RestType != nil {
+			rest += ": " + fl.RestType.String()
+		}
+		params = append(params, rest)
+	}
+	out := "(" + strings.Join(params, ", ") + ")"
+	if fl.ReturnType != nil {
+		out += " -> " + fl.ReturnType.String()
+	}
+	return out
+}
+
+// FunctionStatement declares a named function, as in fn add(a, b) { a + b }.
+// The name is bound before any statement of the enclosing block runs, so
+// declarations can call each other regardless of their order.
+type FunctionStatement struct {
+	Token    token.Token // the fn token
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (fs *FunctionStatement) statementNode()       {}
+func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *FunctionStatement) String() string {
+	return fs.TokenLiteral() + " " + fs.Name.String() + fs.Function.parameterList() + " " + fs.Function.Body.String()
 }
 
 type CallExpression struct {
@@ -1,12 +1,15 @@
 package ast
 
 import (
+	"math/big"
+
 	"github.com/ekediala/jian/token"
 )
 
 type IntegerLiteral struct {
 	Token token.Token // token.INT
 	Value int64
+	Big   *big.Int // set instead of Value when the literal does not fit in an int64
 }
 
 func (il *IntegerLiteral) expressionNode() {}
@@ -6,10 +6,15 @@ import (
 	"github.com/ekediala/jian/token"
 )
 
+// LetStatement binds a name, or the names in a pattern, to a value. Const
+// statements are let statements whose bindings cannot be redeclared.
 type LetStatement struct {
-	Token token.Token // token.LET
-	Name  *Identifier
-	Value Expression
+	Token   token.Token // token.LET or token.CONST
+	Const   bool
+	Name    *Identifier
+	Pattern Pattern        // destructures Value instead of binding it to Name, if set
+	Type    TypeExpression // annotation of the bound value, if any
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode() {}
@@ -23,7 +28,15 @@ func (ls *LetStatement) String() string {
 
 	out.WriteString(ls.TokenLiteral())
 	out.WriteString(" ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
+	if ls.Type != nil {
+		out.WriteString(": ")
+		out.WriteString(ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
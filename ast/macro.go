@@ -0,0 +1,34 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// MacroLiteral is a macro definition. Macros receive their arguments as
+// unevaluated, quoted AST nodes and return the quoted code that replaces the
+// call site.
+//
+//	let unless = macro(cond, then) { quote(if (!(unquote(cond))) { unquote(then) }) };
+type MacroLiteral struct {
+	Token      token.Token // the macro token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) String() string {
+	var out strings.Builder
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(ml.Body.String())
+	return out.String()
+}
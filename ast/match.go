@@ -0,0 +1,53 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// MatchExpression tests a value against the patterns of its arms in order
+// and evaluates the body of the first arm that matches.
+//
+//	match (shape) {
+//	  {"kind": "circle", "r": r} => 3 * r * r,
+//	  [x, y] if x == y => "square",
+//	  _ => "unknown"
+//	}
+type MatchExpression struct {
+	Token   token.Token // the match token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	arms := make([]string, 0, len(me.Arms))
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+	return "match (" + me.Subject.String() + ") { " + strings.Join(arms, ", ") + " }"
+}
+
+// MatchArm is a single arm of a match expression. Guard is nil unless the
+// arm has an if clause.
+type MatchArm struct {
+	Token   token.Token // the first token of the pattern
+	Pattern Pattern
+	Guard   Expression
+	Body    Expression
+}
+
+func (ma *MatchArm) TokenLiteral() string { return ma.Token.Literal }
+func (ma *MatchArm) String() string {
+	var out strings.Builder
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+	return out.String()
+}
@@ -0,0 +1,385 @@
+package ast
+
+// ModifierFunc rewrites a single node. It is called on every node after its
+// children have been modified, and its result replaces the node in its
+// parent.
+type ModifierFunc func(Node) Node
+
+// Modify rewrites the tree rooted at node bottom-up with modifier and returns
+// the new root. The original tree is left untouched: every node with
+// children is copied before its children are replaced, while leaves are
+// passed to modifier as they are. A child replaced by a node of the wrong
+// kind (a statement where an expression belongs, say) is kept unchanged.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch node := node.(type) {
+	case *Program:
+		n := *node
+		n.Statements = modifyStatements(node.Statements, modifier)
+		return modifier(&n)
+
+	case *ExpressionStatement:
+		n := *node
+		n.Expression = modifyExpression(node.Expression, modifier)
+		return modifier(&n)
+
+	case *BlockStatement:
+		n := *node
+		n.Statements = modifyStatements(node.Statements, modifier)
+		return modifier(&n)
+
+	case *LetStatement:
+		n := *node
+		n.Name = modifyIdentifier(node.Name, modifier)
+		n.Pattern = modifyPattern(node.Pattern, modifier)
+		n.Type = modifyType(node.Type, modifier)
+		n.Value = modifyExpression(node.Value, modifier)
+		return modifier(&n)
+
+	case *FunctionStatement:
+		n := *node
+		n.Name = modifyIdentifier(node.Name, modifier)
+		if modified, ok := Modify(node.Function, modifier).(*FunctionLiteral); ok {
+			n.Function = modified
+		}
+		return modifier(&n)
+
+	case *StructStatement:
+		n := *node
+		n.Name = modifyIdentifier(node.Name, modifier)
+		n.Fields = modifyIdentifiers(node.Fields, modifier)
+		return modifier(&n)
+
+	case *ClassStatement:
+		n := *node
+		n.Name = modifyIdentifier(node.Name, modifier)
+		n.Super = modifyIdentifier(node.Super, modifier)
+		if node.Methods != nil {
+			n.Methods = make([]*FunctionStatement, len(node.Methods))
+			for i, method := range node.Methods {
+				n.Methods[i] = method
+				if method == nil {
+					continue
+				}
+				if modified, ok := Modify(method, modifier).(*FunctionStatement); ok {
+					n.Methods[i] = modified
+				}
+			}
+		}
+		return modifier(&n)
+
+	case *AssignStatement:
+		n := *node
+		n.Target = modifyExpression(node.Target, modifier)
+		n.Value = modifyExpression(node.Value, modifier)
+		return modifier(&n)
+
+	case *ReturnStatement:
+		n := *node
+		n.ReturnValue = modifyExpression(node.ReturnValue, modifier)
+		return modifier(&n)
+
+	case *YieldStatement:
+		n := *node
+		n.Value = modifyExpression(node.Value, modifier)
+		return modifier(&n)
+
+	case *PrefixExpression:
+		n := *node
+		n.Right = modifyExpression(node.Right, modifier)
+		return modifier(&n)
+
+	case *InfixExpression:
+		n := *node
+		n.Left = modifyExpression(node.Left, modifier)
+		n.Right = modifyExpression(node.Right, modifier)
+		return modifier(&n)
+
+	case *IndexExpression:
+		n := *node
+		n.Left = modifyExpression(node.Left, modifier)
+		n.Index = modifyExpression(node.Index, modifier)
+		n.Start = modifyExpression(node.Start, modifier)
+		n.End = modifyExpression(node.End, modifier)
+		n.Step = modifyExpression(node.Step, modifier)
+		return modifier(&n)
+
+	case *FieldExpression:
+		n := *node
+		n.Left = modifyExpression(node.Left, modifier)
+		n.Field = modifyIdentifier(node.Field, modifier)
+		return modifier(&n)
+
+	case *IfExpression:
+		n := *node
+		n.Condition = modifyExpression(node.Condition, modifier)
+		n.Consequence = modifyBlock(node.Consequence, modifier)
+		n.Alternative = modifyBlock(node.Alternative, modifier)
+		return modifier(&n)
+
+	case *FunctionLiteral:
+		n := *node
+		n.Parameters = modifyIdentifiers(node.Parameters, modifier)
+		n.Defaults = modifyExpressions(node.Defaults, modifier)
+		n.Patterns = modifyPatterns(node.Patterns, modifier)
+		n.Rest = modifyIdentifier(node.Rest, modifier)
+		n.ParamTypes = modifyTypes(node.ParamTypes, modifier)
+		n.RestType = modifyType(node.RestType, modifier)
+		n.ReturnType = modifyType(node.ReturnType, modifier)
+		n.Body = modifyBlock(node.Body, modifier)
+		n.Generator = n.Body != nil && Yields(n.Body)
+		return modifier(&n)
+
+	case *MacroLiteral:
+		n := *node
+		n.Parameters = modifyIdentifiers(node.Parameters, modifier)
+		n.Body = modifyBlock(node.Body, modifier)
+		return modifier(&n)
+
+	case *CallExpression:
+		n := *node
+		n.Function = modifyExpression(node.Function, modifier)
+		n.Arguments = modifyExpressions(node.Arguments, modifier)
+		return modifier(&n)
+
+	case *KeywordArgument:
+		n := *node
+		n.Name = modifyIdentifier(node.Name, modifier)
+		n.Value = modifyExpression(node.Value, modifier)
+		return modifier(&n)
+
+	case *SpreadExpression:
+		n := *node
+		n.Value = modifyExpression(node.Value, modifier)
+		return modifier(&n)
+
+	case *ArrayLiteral:
+		n := *node
+		n.Elements = modifyExpressions(node.Elements, modifier)
+		return modifier(&n)
+
+	case *HashLiteral:
+		n := *node
+		n.Keys = make([]Expression, len(node.Keys))
+		n.Pairs = make(map[Expression]Expression, len(node.Pairs))
+		for i, key := range node.Keys {
+			n.Keys[i] = modifyExpression(key, modifier)
+			n.Pairs[n.Keys[i]] = modifyExpression(node.Pairs[key], modifier)
+		}
+		return modifier(&n)
+
+	case *SelectExpression:
+		n := *node
+		n.Cases = make([]*SelectCase, len(node.Cases))
+		for i, c := range node.Cases {
+			n.Cases[i] = c
+			if modified, ok := Modify(c, modifier).(*SelectCase); ok {
+				n.Cases[i] = modified
+			}
+		}
+		n.Default = modifyBlock(node.Default, modifier)
+		return modifier(&n)
+
+	case *SelectCase:
+		n := *node
+		n.Binding = modifyIdentifier(node.Binding, modifier)
+		if modified, ok := Modify(node.Operation, modifier).(*CallExpression); ok {
+			n.Operation = modified
+		}
+		n.Body = modifyBlock(node.Body, modifier)
+		return modifier(&n)
+
+	case *MatchExpression:
+		n := *node
+		n.Subject = modifyExpression(node.Subject, modifier)
+		n.Arms = make([]*MatchArm, len(node.Arms))
+		for i, arm := range node.Arms {
+			n.Arms[i] = arm
+			if modified, ok := Modify(arm, modifier).(*MatchArm); ok {
+				n.Arms[i] = modified
+			}
+		}
+		return modifier(&n)
+
+	case *MatchArm:
+		n := *node
+		n.Pattern = modifyPattern(node.Pattern, modifier)
+		n.Guard = modifyExpression(node.Guard, modifier)
+		n.Body = modifyExpression(node.Body, modifier)
+		return modifier(&n)
+
+	case *LiteralPattern:
+		n := *node
+		n.Value = modifyExpression(node.Value, modifier)
+		return modifier(&n)
+
+	case *BindingPattern:
+		n := *node
+		n.Name = modifyIdentifier(node.Name, modifier)
+		return modifier(&n)
+
+	case *ArrayPattern:
+		n := *node
+		n.Elements = modifyPatterns(node.Elements, modifier)
+		n.Rest = modifyIdentifier(node.Rest, modifier)
+		return modifier(&n)
+
+	case *HashPattern:
+		n := *node
+		n.Keys = modifyExpressions(node.Keys, modifier)
+		n.Values = modifyPatterns(node.Values, modifier)
+		return modifier(&n)
+
+	case *DefaultPattern:
+		n := *node
+		n.Pattern = modifyPattern(node.Pattern, modifier)
+		n.Default = modifyExpression(node.Default, modifier)
+		return modifier(&n)
+
+	case *AlternativePattern:
+		n := *node
+		n.Alternatives = modifyPatterns(node.Alternatives, modifier)
+		return modifier(&n)
+
+	case *ArrayType:
+		n := *node
+		n.Element = modifyType(node.Element, modifier)
+		return modifier(&n)
+
+	case *HashType:
+		n := *node
+		n.Key = modifyType(node.Key, modifier)
+		n.Value = modifyType(node.Value, modifier)
+		return modifier(&n)
+
+	case *FunctionType:
+		n := *node
+		n.Params = modifyTypes(node.Params, modifier)
+		n.Rest = modifyType(node.Rest, modifier)
+		n.Return = modifyType(node.Return, modifier)
+		return modifier(&n)
+
+	case *UnionType:
+		n := *node
+		n.Types = modifyTypes(node.Types, modifier)
+		return modifier(&n)
+
+	case *NullableType:
+		n := *node
+		n.Type = modifyType(node.Type, modifier)
+		return modifier(&n)
+
+	default:
+		return modifier(node)
+	}
+}
+
+func modifyStatements(stmts []Statement, modifier ModifierFunc) []Statement {
+	if stmts == nil {
+		return nil
+	}
+	modified := make([]Statement, len(stmts))
+	for i, stmt := range stmts {
+		modified[i] = stmt
+		if stmt == nil {
+			continue
+		}
+		if m, ok := Modify(stmt, modifier).(Statement); ok {
+			modified[i] = m
+		}
+	}
+	return modified
+}
+
+func modifyExpressions(exps []Expression, modifier ModifierFunc) []Expression {
+	if exps == nil {
+		return nil
+	}
+	modified := make([]Expression, len(exps))
+	for i, exp := range exps {
+		modified[i] = modifyExpression(exp, modifier)
+	}
+	return modified
+}
+
+func modifyIdentifiers(idents []*Identifier, modifier ModifierFunc) []*Identifier {
+	if idents == nil {
+		return nil
+	}
+	modified := make([]*Identifier, len(idents))
+	for i, ident := range idents {
+		modified[i] = modifyIdentifier(ident, modifier)
+	}
+	return modified
+}
+
+func modifyPatterns(patterns []Pattern, modifier ModifierFunc) []Pattern {
+	if patterns == nil {
+		return nil
+	}
+	modified := make([]Pattern, len(patterns))
+	for i, p := range patterns {
+		modified[i] = modifyPattern(p, modifier)
+	}
+	return modified
+}
+
+func modifyPattern(p Pattern, modifier ModifierFunc) Pattern {
+	if p == nil {
+		return nil
+	}
+	if modified, ok := Modify(p, modifier).(Pattern); ok {
+		return modified
+	}
+	return p
+}
+
+func modifyTypes(types []TypeExpression, modifier ModifierFunc) []TypeExpression {
+	if types == nil {
+		return nil
+	}
+	modified := make([]TypeExpression, len(types))
+	for i, t := range types {
+		modified[i] = modifyType(t, modifier)
+	}
+	return modified
+}
+
+func modifyType(t TypeExpression, modifier ModifierFunc) TypeExpression {
+	if t == nil {
+		return nil
+	}
+	if modified, ok := Modify(t, modifier).(TypeExpression); ok {
+		return modified
+	}
+	return t
+}
+
+func modifyExpression(exp Expression, modifier ModifierFunc) Expression {
+	if exp == nil {
+		return nil
+	}
+	if modified, ok := Modify(exp, modifier).(Expression); ok {
+		return modified
+	}
+	return exp
+}
+
+func modifyBlock(block *BlockStatement, modifier ModifierFunc) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+	if modified, ok := Modify(block, modifier).(*BlockStatement); ok {
+		return modified
+	}
+	return block
+}
+
+func modifyIdentifier(ident *Identifier, modifier ModifierFunc) *Identifier {
+	if ident == nil {
+		return nil
+	}
+	if modified, ok := Modify(ident, modifier).(*Identifier); ok {
+		return modified
+	}
+	return ident
+}
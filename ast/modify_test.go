@@ -0,0 +1,145 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/parser"
+	"github.com/ekediala/jian/token"
+)
+
+func TestModify(t *testing.T) {
+	one := func() ast.Expression { return &ast.IntegerLiteral{Token: token.Token{Literal: "1"}, Value: 1} }
+	two := func() ast.Expression { return &ast.IntegerLiteral{Token: token.Token{Literal: "2"}, Value: 2} }
+	block := func(exp ast.Expression) *ast.BlockStatement {
+		return &ast.BlockStatement{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: exp}}}
+	}
+
+	turnOneIntoTwo := func(node ast.Node) ast.Node {
+		integer, ok := node.(*ast.IntegerLiteral)
+		if !ok || integer.Value != 1 {
+			return node
+		}
+		return two()
+	}
+
+	tests := []struct {
+		input    ast.Node
+		expected string
+	}{
+		{one(), "2"},
+		{&ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: one()}}}, "2"},
+		{&ast.InfixExpression{Left: one(), Operator: "+", Right: two()}, "(2 + 2)"},
+		{&ast.InfixExpression{Left: two(), Operator: "+", Right: one()}, "(2 + 2)"},
+		{&ast.PrefixExpression{Operator: "-", Right: one()}, "(-2)"},
+		{&ast.IndexExpression{Left: one(), Index: one()}, "(2[2])"},
+		{&ast.IndexExpression{Left: one(), Slice: true, Start: one(), Step: one()}, "(2[2::2])"},
+		{&ast.IfExpression{Condition: one(), Consequence: block(one()), Alternative: block(one())}, "if2 2else 2"},
+		{&ast.ReturnStatement{Token: token.Token{Literal: "return"}, ReturnValue: one()}, "return 2;"},
+		{&ast.YieldStatement{Token: token.Token{Literal: "yield"}, Value: one()}, "yield 2;"},
+		{&ast.LetStatement{Token: token.Token{Literal: "let"}, Name: &ast.Identifier{Value: "x"}, Value: one()}, "let x = 2;"},
+		{&ast.FunctionLiteral{Token: token.Token{Literal: "fn"}, Body: block(one())}, "fn() 2"},
+		{&ast.MacroLiteral{Token: token.Token{Literal: "macro"}, Body: block(one())}, "macro() 2"},
+		{&ast.CallExpression{Function: &ast.Identifier{Value: "f"}, Arguments: []ast.Expression{one(), two()}}, "f(2, 2)"},
+		{&ast.ArrayLiteral{Elements: []ast.Expression{one(), one()}}, "[2, 2]"},
+		{block(one()), "2"},
+		{&ast.ExpressionStatement{Expression: one()}, "2"},
+		{&ast.SelectExpression{
+			Cases: []*ast.SelectCase{{
+				Operation: &ast.CallExpression{Function: &ast.Identifier{Value: "send"}, Arguments: []ast.Expression{one(), one()}},
+				Body:      block(one()),
+			}},
+			Default: block(one()),
+		}, "select { case send(2, 2) { 2 } default { 2 } }"},
+	}
+
+	for _, tt := range tests {
+		before := tt.input.String()
+		modified := ast.Modify(tt.input, turnOneIntoTwo)
+		if got := modified.String(); got != tt.expected {
+			t.Errorf("not equal. got=%q, want=%q", got, tt.expected)
+		}
+		if got := tt.input.String(); got != before {
+			t.Errorf("input was modified. got=%q, want=%q", got, before)
+		}
+	}
+
+	key := one()
+	hash := &ast.HashLiteral{Pairs: map[ast.Expression]ast.Expression{key: one()}, Keys: []ast.Expression{key}}
+	modified, ok := ast.Modify(hash, turnOneIntoTwo).(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("expected *ast.HashLiteral, got %T", modified)
+	}
+	for _, key := range modified.Keys {
+		if key.(*ast.IntegerLiteral).Value != 2 {
+			t.Errorf("key is not 2, got %s", key)
+		}
+		if value := modified.Pairs[key].(*ast.IntegerLiteral); value.Value != 2 {
+			t.Errorf("value is not 2, got %s", value)
+		}
+	}
+	if hash.String() != "{1:1}" {
+		t.Errorf("input was modified. got=%q", hash.String())
+	}
+}
+
+func TestModifyRenamesIdentifiers(t *testing.T) {
+	p := parser.New(lexer.New("let x = fn(x) { x + y }; x(y);"))
+	program := p.ParseProgram()
+
+	modified := ast.Modify(program, func(node ast.Node) ast.Node {
+		if ident, ok := node.(*ast.Identifier); ok && ident.Value == "x" {
+			return &ast.Identifier{Token: ident.Token, Value: "z"}
+		}
+		return node
+	})
+
+	if got, want := modified.String(), "let z = fn(z) (z + y);z(y)"; got != want {
+		t.Errorf("not equal. got=%q, want=%q", got, want)
+	}
+}
+
+func TestModifyKeepsMismatchedReplacements(t *testing.T) {
+	p := parser.New(lexer.New("1 + 2;"))
+	program := p.ParseProgram()
+
+	// A statement cannot replace an expression, so the infix operands stay.
+	modified := ast.Modify(program, func(node ast.Node) ast.Node {
+		if integer, ok := node.(*ast.IntegerLiteral); ok {
+			return &ast.ExpressionStatement{Expression: integer}
+		}
+		return node
+	})
+
+	if got, want := modified.String(), "(1 + 2)"; got != want {
+		t.Errorf("not equal. got=%q, want=%q", got, want)
+	}
+}
+
+func TestModifyUpdatesGenerator(t *testing.T) {
+	p := parser.New(lexer.New("fn() { next_value(); fn() { 1 } }"))
+	program := p.ParseProgram()
+
+	// Replacing a statement with a yield, as a macro expanding to one does,
+	// makes the function a generator function, but not the one nested in it.
+	modified := ast.Modify(program, func(node ast.Node) ast.Node {
+		stmt, ok := node.(*ast.ExpressionStatement)
+		if !ok {
+			return node
+		}
+		if call, ok := stmt.Expression.(*ast.CallExpression); ok {
+			return &ast.YieldStatement{Token: token.Token{Type: token.YIELD, Literal: "yield"}, Value: call}
+		}
+		return node
+	}).(*ast.Program)
+
+	outer := modified.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if !outer.Generator {
+		t.Errorf("expected %s to be a generator function", outer)
+	}
+	inner := outer.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if inner.Generator {
+		t.Errorf("expected %s not to be a generator function", inner)
+	}
+}
@@ -0,0 +1,151 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// Pattern describes the shape of a value. Matching a value against a
+// pattern either fails or binds the names the pattern contains.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// LiteralPattern matches values equal to an integer, string or boolean
+// literal. Value is the literal, or a negated integer literal.
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// WildcardPattern, written _, matches any value without binding it.
+type WildcardPattern struct {
+	Token token.Token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// BindingPattern matches any value and binds it to Name.
+type BindingPattern struct {
+	Token token.Token
+	Name  *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Token.Literal }
+func (bp *BindingPattern) String() string       { return bp.Name.String() }
+
+// ArrayPattern matches arrays whose elements match Elements in order. With
+// a Rest identifier it matches longer arrays too and binds the remaining
+// elements to Rest, unless Rest is _.
+type ArrayPattern struct {
+	Token    token.Token // the [ token
+	Elements []Pattern
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	elements := make([]string, 0, len(ap.Elements)+1)
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// HashPattern matches hashes that have every key in Keys, with a value
+// matching the pattern at the same position in Values. Other keys are
+// ignored.
+type HashPattern struct {
+	Token  token.Token // the { token
+	Keys   []Expression
+	Values []Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	pairs := make([]string, 0, len(hp.Keys))
+	for i, key := range hp.Keys {
+		pairs = append(pairs, key.String()+": "+hp.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// AlternativePattern, written p1 | p2, matches values that match any of its
+// alternatives. Every alternative binds the same names.
+type AlternativePattern struct {
+	Token        token.Token // the first token of the first alternative
+	Alternatives []Pattern
+}
+
+func (ap *AlternativePattern) patternNode()         {}
+func (ap *AlternativePattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *AlternativePattern) String() string {
+	alternatives := make([]string, 0, len(ap.Alternatives))
+	for _, alt := range ap.Alternatives {
+		alternatives = append(alternatives, alt.String())
+	}
+	return strings.Join(alternatives, " | ")
+}
+
+// DefaultPattern is an element of an array or hash pattern with a value to
+// match in place of a missing element, as in [a, b = 0]. When the element is
+// present, it is matched against Pattern alone.
+type DefaultPattern struct {
+	Token   token.Token // the = token
+	Pattern Pattern
+	Default Expression
+}
+
+func (dp *DefaultPattern) patternNode()         {}
+func (dp *DefaultPattern) TokenLiteral() string { return dp.Token.Literal }
+func (dp *DefaultPattern) String() string {
+	return dp.Pattern.String() + " = " + dp.Default.String()
+}
+
+// PatternBindings returns the identifiers a pattern binds, in source order.
+// Since every alternative binds the same names, only those of the first
+// alternative are returned.
+func PatternBindings(p Pattern) []*Identifier {
+	switch p := p.(type) {
+	case *BindingPattern:
+		return []*Identifier{p.Name}
+	case *ArrayPattern:
+		var idents []*Identifier
+		for _, el := range p.Elements {
+			idents = append(idents, PatternBindings(el)...)
+		}
+		if p.Rest != nil && p.Rest.Value != "_" {
+			idents = append(idents, p.Rest)
+		}
+		return idents
+	case *HashPattern:
+		var idents []*Identifier
+		for _, value := range p.Values {
+			idents = append(idents, PatternBindings(value)...)
+		}
+		return idents
+	case *DefaultPattern:
+		return PatternBindings(p.Pattern)
+	case *AlternativePattern:
+		if len(p.Alternatives) == 0 {
+			return nil
+		}
+		return PatternBindings(p.Alternatives[0])
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// SelectExpression waits on several channel operations and runs the body of
+// the first one that can proceed.
+//
+//	select {
+//	  case let v = recv(ch) { v }
+//	  case send(out, 1) { "sent" }
+//	  default { 0 }
+//	}
+type SelectExpression struct {
+	Token   token.Token // the select token
+	Cases   []*SelectCase
+	Default *BlockStatement
+}
+
+func (se *SelectExpression) expressionNode()      {}
+func (se *SelectExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SelectExpression) String() string {
+	var out strings.Builder
+	out.WriteString("select { ")
+	for _, c := range se.Cases {
+		out.WriteString(c.String())
+		out.WriteString(" ")
+	}
+	if se.Default != nil {
+		out.WriteString("default { ")
+		out.WriteString(se.Default.String())
+		out.WriteString(" } ")
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// SelectCase is a single arm of a select expression. Operation is always a
+// call to recv(ch) or send(ch, value); Binding is only set for receives.
+type SelectCase struct {
+	Token     token.Token // the case token
+	Binding   *Identifier
+	Operation *CallExpression
+	Body      *BlockStatement
+}
+
+func (sc *SelectCase) IsSend() bool {
+	return sc.Operation.Function.String() == "send"
+}
+
+func (sc *SelectCase) TokenLiteral() string { return sc.Token.Literal }
+func (sc *SelectCase) String() string {
+	var out strings.Builder
+	out.WriteString("case ")
+	if sc.Binding != nil {
+		out.WriteString("let ")
+		out.WriteString(sc.Binding.String())
+		out.WriteString(" = ")
+	}
+	out.WriteString(sc.Operation.String())
+	out.WriteString(" { ")
+	out.WriteString(sc.Body.String())
+	out.WriteString(" }")
+	return out.String()
+}
@@ -0,0 +1,47 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// StructStatement declares a struct type, as in struct Point { x, y }, and
+// binds its constructor to Name.
+type StructStatement struct {
+	Token  token.Token // the struct token
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) String() string {
+	if len(ss.Fields) == 0 {
+		return "struct " + ss.Name.String() + " {}"
+	}
+	fields := make([]string, 0, len(ss.Fields))
+	for _, field := range ss.Fields {
+		fields = append(fields, field.String())
+	}
+	return "struct " + ss.Name.String() + " { " + strings.Join(fields, ", ") + " }"
+}
+
+// FieldExpression reads a field of a struct, as in p.x. An optional field
+// expression, as in p?.x, is null if p is null.
+type FieldExpression struct {
+	Token    token.Token // the . or ?. token
+	Left     Expression
+	Field    *Identifier
+	Optional bool
+}
+
+func (fe *FieldExpression) expressionNode()      {}
+func (fe *FieldExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *FieldExpression) String() string {
+	dot := "."
+	if fe.Optional {
+		dot = "?."
+	}
+	return "(" + fe.Left.String() + dot + fe.Field.String() + ")"
+}
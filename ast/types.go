@@ -0,0 +1,114 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// TypeExpression is a type annotation, as in let x: int = 1 or
+// fn(a: [string]) -> bool. Annotations are ignored at runtime unless
+// strict mode is on.
+type TypeExpression interface {
+	Node
+	typeNode()
+}
+
+// NamedType names a builtin type such as int, string, bool, null or any, or
+// a struct or class.
+type NamedType struct {
+	Token token.Token
+	Name  string
+}
+
+func (nt *NamedType) typeNode()            {}
+func (nt *NamedType) TokenLiteral() string { return nt.Token.Literal }
+func (nt *NamedType) String() string       { return nt.Name }
+
+// ArrayType, written [T], is the type of arrays whose elements are all T.
+type ArrayType struct {
+	Token   token.Token // the [ token
+	Element TypeExpression
+}
+
+func (at *ArrayType) typeNode()            {}
+func (at *ArrayType) TokenLiteral() string { return at.Token.Literal }
+func (at *ArrayType) String() string       { return "[" + at.Element.String() + "]" }
+
+// HashType, written {K: V}, is the type of hashes with keys of type K and
+// values of type V.
+type HashType struct {
+	Token token.Token // the { token
+	Key   TypeExpression
+	Value TypeExpression
+}
+
+func (ht *HashType) typeNode()            {}
+func (ht *HashType) TokenLiteral() string { return ht.Token.Literal }
+func (ht *HashType) String() string {
+	return "{" + ht.Key.String() + ": " + ht.Value.String() + "}"
+}
+
+// FunctionType, written fn(A, B, ...[C]) -> R, is the type of functions
+// taking Params, then any number of Rest arguments if Rest is set, and
+// returning Return. A missing Return means the result is not checked.
+type FunctionType struct {
+	Token  token.Token // the fn token
+	Params []TypeExpression
+	Rest   TypeExpression // an array type, if set
+	Return TypeExpression
+}
+
+func (ft *FunctionType) typeNode()            {}
+func (ft *FunctionType) TokenLiteral() string { return ft.Token.Literal }
+func (ft *FunctionType) String() string {
+	params := make([]string, 0, len(ft.Params)+1)
+	for _, param := range ft.Params {
+		params = append(params, param.String())
+	}
+	if ft.Rest != nil {
+		params = append(params, "..."+ft.Rest.String())
+	}
+	out := "fn(" + strings.Join(params, ", ") + ")"
+	if ft.Return != nil {
+		out += " -> " + ft.Return.String()
+	}
+	return out
+}
+
+// UnionType, written A | B, is the type of values that are either A or B.
+type UnionType struct {
+	Token token.Token // the token of the first alternative
+	Types []TypeExpression
+}
+
+func (ut *UnionType) typeNode()            {}
+func (ut *UnionType) TokenLiteral() string { return ut.Token.Literal }
+func (ut *UnionType) String() string {
+	types := make([]string, 0, len(ut.Types))
+	for _, t := range ut.Types {
+		if _, ok := t.(*FunctionType); ok {
+			// fn() -> A | B would read as a function returning A | B
+			types = append(types, "("+t.String()+")")
+			continue
+		}
+		types = append(types, t.String())
+	}
+	return strings.Join(types, " | ")
+}
+
+// NullableType, written T?, is shorthand for T | null.
+type NullableType struct {
+	Token token.Token // the ? token
+	Type  TypeExpression
+}
+
+func (nt *NullableType) typeNode()            {}
+func (nt *NullableType) TokenLiteral() string { return nt.Token.Literal }
+func (nt *NullableType) String() string {
+	switch nt.Type.(type) {
+	case *UnionType, *FunctionType:
+		return "(" + nt.Type.String() + ")?"
+	}
+	return nt.Type.String() + "?"
+}
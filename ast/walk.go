@@ -0,0 +1,290 @@
+package ast
+
+// A Visitor's Visit method is called for each node found by Walk. If the
+// visitor w it returns is not nil, Walk visits each child of the node with
+// w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order. It starts by
+// calling v.Visit(node); node must not be nil. Children are visited in
+// source order, and the keys and values of a hash literal alternate.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+
+	case *ExpressionStatement:
+		walkExpression(v, n.Expression)
+
+	case *LetStatement:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkPattern(v, n.Pattern)
+		walkType(v, n.Type)
+		walkExpression(v, n.Value)
+
+	case *FunctionStatement:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		if n.Function != nil {
+			Walk(v, n.Function)
+		}
+
+	case *StructStatement:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkIdentifiers(v, n.Fields)
+
+	case *ClassStatement:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		if n.Super != nil {
+			Walk(v, n.Super)
+		}
+		for _, method := range n.Methods {
+			if method != nil {
+				Walk(v, method)
+			}
+		}
+
+	case *AssignStatement:
+		walkExpression(v, n.Target)
+		walkExpression(v, n.Value)
+
+	case *ReturnStatement:
+		walkExpression(v, n.ReturnValue)
+
+	case *YieldStatement:
+		walkExpression(v, n.Value)
+
+	case *PrefixExpression:
+		walkExpression(v, n.Right)
+
+	case *InfixExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+
+	case *IndexExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Index)
+		walkExpression(v, n.Start)
+		walkExpression(v, n.End)
+		walkExpression(v, n.Step)
+
+	case *FieldExpression:
+		walkExpression(v, n.Left)
+		if n.Field != nil {
+			Walk(v, n.Field)
+		}
+
+	case *IfExpression:
+		walkExpression(v, n.Condition)
+		walkBlock(v, n.Consequence)
+		walkBlock(v, n.Alternative)
+
+	case *FunctionLiteral:
+		for i, param := range n.Parameters {
+			if pattern := n.Pattern(i); pattern != nil {
+				Walk(v, pattern)
+			} else if param != nil {
+				Walk(v, param)
+			}
+			walkType(v, n.ParamType(i))
+			walkExpression(v, n.Default(i))
+		}
+		if n.Rest != nil {
+			Walk(v, n.Rest)
+		}
+		walkType(v, n.RestType)
+		walkType(v, n.ReturnType)
+		walkBlock(v, n.Body)
+
+	case *MacroLiteral:
+		walkIdentifiers(v, n.Parameters)
+		walkBlock(v, n.Body)
+
+	case *CallExpression:
+		walkExpression(v, n.Function)
+		walkExpressions(v, n.Arguments)
+
+	case *KeywordArgument:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkExpression(v, n.Value)
+
+	case *SpreadExpression:
+		walkExpression(v, n.Value)
+
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+
+	case *HashLiteral:
+		for _, key := range n.Keys {
+			walkExpression(v, key)
+			walkExpression(v, n.Pairs[key])
+		}
+
+	case *SelectExpression:
+		for _, c := range n.Cases {
+			if c != nil {
+				Walk(v, c)
+			}
+		}
+		walkBlock(v, n.Default)
+
+	case *MatchExpression:
+		walkExpression(v, n.Subject)
+		for _, arm := range n.Arms {
+			if arm != nil {
+				Walk(v, arm)
+			}
+		}
+
+	case *MatchArm:
+		walkPattern(v, n.Pattern)
+		walkExpression(v, n.Guard)
+		walkExpression(v, n.Body)
+
+	case *LiteralPattern:
+		walkExpression(v, n.Value)
+
+	case *BindingPattern:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+
+	case *ArrayPattern:
+		for _, el := range n.Elements {
+			walkPattern(v, el)
+		}
+		if n.Rest != nil {
+			Walk(v, n.Rest)
+		}
+
+	case *HashPattern:
+		for i, key := range n.Keys {
+			walkExpression(v, key)
+			if i < len(n.Values) {
+				walkPattern(v, n.Values[i])
+			}
+		}
+
+	case *DefaultPattern:
+		walkPattern(v, n.Pattern)
+		walkExpression(v, n.Default)
+
+	case *AlternativePattern:
+		for _, alt := range n.Alternatives {
+			walkPattern(v, alt)
+		}
+
+	case *ArrayType:
+		walkType(v, n.Element)
+
+	case *HashType:
+		walkType(v, n.Key)
+		walkType(v, n.Value)
+
+	case *FunctionType:
+		for _, param := range n.Params {
+			walkType(v, param)
+		}
+		walkType(v, n.Rest)
+		walkType(v, n.Return)
+
+	case *UnionType:
+		for _, t := range n.Types {
+			walkType(v, t)
+		}
+
+	case *NullableType:
+		walkType(v, n.Type)
+
+	case *SelectCase:
+		if n.Binding != nil {
+			Walk(v, n.Binding)
+		}
+		if n.Operation != nil {
+			Walk(v, n.Operation)
+		}
+		walkBlock(v, n.Body)
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			Walk(v, stmt)
+		}
+	}
+}
+
+func walkExpressions(v Visitor, exps []Expression) {
+	for _, exp := range exps {
+		walkExpression(v, exp)
+	}
+}
+
+func walkExpression(v Visitor, exp Expression) {
+	if exp != nil {
+		Walk(v, exp)
+	}
+}
+
+func walkPattern(v Visitor, p Pattern) {
+	if p != nil {
+		Walk(v, p)
+	}
+}
+
+func walkType(v Visitor, t TypeExpression) {
+	if t != nil {
+		Walk(v, t)
+	}
+}
+
+func walkBlock(v Visitor, block *BlockStatement) {
+	if block != nil {
+		Walk(v, block)
+	}
+}
+
+func walkIdentifiers(v Visitor, idents []*Identifier) {
+	for _, ident := range idents {
+		if ident != nil {
+			Walk(v, ident)
+		}
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node in depth-first order like Walk.
+// It calls f(node) for each node; if f returns true, Inspect visits the
+// node's children, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
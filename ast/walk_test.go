@@ -0,0 +1,165 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, errs)
+	}
+	return program
+}
+
+// nodeNames lists the nodes visited by Inspect below the program and its
+// first statement, in order, as "Type(String())".
+func nodeNames(program *ast.Program) []string {
+	var names []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.Program:
+		default:
+			names = append(names, fmt.Sprintf("%s(%s)", strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."), node))
+		}
+		return true
+	})
+	return names
+}
+
+func TestInspectVisitsEveryNode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 5;", []string{"LetStatement(let x = 5;)", "Identifier(x)", "IntegerLiteral(5)"}},
+		{"return y;", []string{"ReturnStatement(return y;)", "Identifier(y)"}},
+		{`"hi"; true`, []string{"ExpressionStatement(hi)", "StringLiteral(hi)", "ExpressionStatement(true)", "Boolean(true)"}},
+		{"-a", []string{"ExpressionStatement((-a))", "PrefixExpression((-a))", "Identifier(a)"}},
+		{"a + b", []string{"ExpressionStatement((a + b))", "InfixExpression((a + b))", "Identifier(a)", "Identifier(b)"}},
+		{"a[1]", []string{"ExpressionStatement((a[1]))", "IndexExpression((a[1]))", "Identifier(a)", "IntegerLiteral(1)"}},
+		{"a[1::b]", []string{"ExpressionStatement((a[1::b]))", "IndexExpression((a[1::b]))", "Identifier(a)", "IntegerLiteral(1)", "Identifier(b)"}},
+		{"if (c) { a } else { b }", []string{
+			"ExpressionStatement(ifc aelse b)", "IfExpression(ifc aelse b)", "Identifier(c)",
+			"BlockStatement(a)", "ExpressionStatement(a)", "Identifier(a)",
+			"BlockStatement(b)", "ExpressionStatement(b)", "Identifier(b)",
+		}},
+		{"fn(x) { return x; }", []string{
+			"ExpressionStatement(fn(x) return x;)", "FunctionLiteral(fn(x) return x;)", "Identifier(x)",
+			"BlockStatement(return x;)", "ReturnStatement(return x;)", "Identifier(x)",
+		}},
+		{"macro(x) { x }", []string{
+			"ExpressionStatement(macro(x) x)", "MacroLiteral(macro(x) x)", "Identifier(x)",
+			"BlockStatement(x)", "ExpressionStatement(x)", "Identifier(x)",
+		}},
+		{"f(1, g)", []string{"ExpressionStatement(f(1, g))", "CallExpression(f(1, g))", "Identifier(f)", "IntegerLiteral(1)", "Identifier(g)"}},
+		{"[1, a]", []string{"ExpressionStatement([1, a])", "ArrayLiteral([1, a])", "IntegerLiteral(1)", "Identifier(a)"}},
+		{"yield x;", []string{"YieldStatement(yield x;)", "Identifier(x)"}},
+		{`{"b": 1, "a": 2}`, []string{
+			"ExpressionStatement({b:1, a:2})", "HashLiteral({b:1, a:2})",
+			"StringLiteral(b)", "IntegerLiteral(1)", "StringLiteral(a)", "IntegerLiteral(2)",
+		}},
+		{"select { case let v = recv(ch) { v } default { 0 } }", []string{
+			"ExpressionStatement(select { case let v = recv(ch) { v } default { 0 } })",
+			"SelectExpression(select { case let v = recv(ch) { v } default { 0 } })",
+			"SelectCase(case let v = recv(ch) { v })", "Identifier(v)",
+			"CallExpression(recv(ch))", "Identifier(recv)", "Identifier(ch)",
+			"BlockStatement(v)", "ExpressionStatement(v)", "Identifier(v)",
+			"BlockStatement(0)", "ExpressionStatement(0)", "IntegerLiteral(0)",
+		}},
+	}
+
+	for _, tt := range tests {
+		got := nodeNames(parse(t, tt.input))
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("%s: visited nodes wrong.\ngot:\n\t%s\nwant:\n\t%s", tt.input,
+				strings.Join(got, "\n\t"), strings.Join(tt.expected, "\n\t"))
+		}
+	}
+}
+
+func TestInspectPrunes(t *testing.T) {
+	program := parse(t, "let f = fn(x) { x + 1 }; f(2) + 3;")
+
+	var integers []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FunctionLiteral); ok {
+			return false
+		}
+		if integer, ok := node.(*ast.IntegerLiteral); ok {
+			integers = append(integers, integer.String())
+		}
+		return true
+	})
+
+	if got := strings.Join(integers, ","); got != "2,3" {
+		t.Errorf("expected to find 2,3 outside the function, got %s", got)
+	}
+}
+
+func TestYields(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"fn() { yield 1; }", true},
+		{"fn(n) { if (n > 0) { yield n; } }", true},
+		{"fn() { match (1) { _ => if (true) { yield 1; } } }", true},
+		{"fn() { 1 }", false},
+		{"fn() { fn() { yield 1; } }", false},
+		{"fn() { let inner = fn() { yield 1; }; yield 2; }", true},
+		{"fn() { macro() { yield 1; } }", false},
+	}
+
+	for _, tt := range tests {
+		stmt := parse(t, tt.input).Statements[0].(*ast.ExpressionStatement)
+		fn := stmt.Expression.(*ast.FunctionLiteral)
+		if got := ast.Yields(fn.Body); got != tt.expected {
+			t.Errorf("%s: expected %t, got %t", tt.input, tt.expected, got)
+		}
+		if got := ast.Yields(fn); got != tt.expected {
+			t.Errorf("%s: expected the literal itself to give %t, got %t", tt.input, tt.expected, got)
+		}
+		if fn.Generator != tt.expected {
+			t.Errorf("%s: expected the parser to set Generator to %t, got %t", tt.input, tt.expected, fn.Generator)
+		}
+	}
+}
+
+type depthVisitor struct {
+	depth    *int
+	maxDepth *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*v.depth--
+		return nil
+	}
+	*v.depth++
+	if *v.depth > *v.maxDepth {
+		*v.maxDepth = *v.depth
+	}
+	return v
+}
+
+func TestWalkCallsVisitNilAfterChildren(t *testing.T) {
+	depth, maxDepth := 0, 0
+	ast.Walk(depthVisitor{&depth, &maxDepth}, parse(t, "[[1]]"))
+
+	if depth != 0 {
+		t.Errorf("expected every Visit(node) to be matched by Visit(nil), depth ended at %d", depth)
+	}
+	// Program, ExpressionStatement, ArrayLiteral, ArrayLiteral, IntegerLiteral
+	if maxDepth != 5 {
+		t.Errorf("expected max depth 5, got %d", maxDepth)
+	}
+}
@@ -0,0 +1,46 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/token"
+)
+
+// YieldStatement hands a value to the caller of a generator and pauses the
+// generator until its next value is asked for. A function whose body
+// contains a yield statement is a generator function.
+type YieldStatement struct {
+	Token token.Token // the yield token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) TokenLiteral() string { return ys.Token.Literal }
+
+func (ys *YieldStatement) String() string {
+	var out strings.Builder
+
+	out.WriteString(ys.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(ys.Value.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// Yields reports whether node contains a yield statement of its own, not
+// counting those inside the function and macro literals nested in it. A
+// function literal is a generator function if its body yields.
+func Yields(node Node) bool {
+	found := false
+	Inspect(node, func(n Node) bool {
+		switch n.(type) {
+		case *YieldStatement:
+			found = true
+		case *FunctionLiteral, *MacroLiteral:
+			return n == node
+		}
+		return !found
+	})
+	return found
+}
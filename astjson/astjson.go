@@ -0,0 +1,988 @@
+// Package astjson converts parsed programs to and from JSON.
+//
+// Every node is a JSON object whose "kind" names the ast type it came from,
+// such as "InfixExpression". Nodes carry their token, with its type, literal
+// and position, followed by their children:
+//
+//	{"kind": "Identifier", "token": {"type": "IDENT", "literal": "x", "line": 1, "column": 5}, "value": "x"}
+//
+// Unmarshal rebuilds the ast from this form, so programs can be generated or
+// transformed by other tools and then run with evaluator.Eval.
+package astjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/token"
+)
+
+type jsonToken struct {
+	Type    token.TokenType `json:"type"`
+	Literal string          `json:"literal"`
+	Line    int             `json:"line,omitempty"`
+	Column  int             `json:"column,omitempty"`
+}
+
+type jsonPair struct {
+	Key   *jsonNode `json:"key"`
+	Value *jsonNode `json:"value"`
+}
+
+// jsonNode holds the fields of every kind of node; each kind uses a subset.
+// Value is a scalar for literals and identifiers, and a node for let,
+// return and yield statements, assignments, keyword arguments, spreads and
+// literal patterns. Defaults, Patterns and ParamTypes are parallel to
+// Parameters, with null for parameters without a default, a pattern or a
+// type. A named type stores its name in Value, and a hash type stores its
+// key and value types in Types.
+// Const is set on let statements that declare constants, and Optional on
+// the field and index expressions written with ?. and ?[. Slice is set on
+// index expressions that are slices, which store their bounds in Start, End
+// and Step, each left out when omitted.
+type jsonNode struct {
+	Kind     string     `json:"kind"`
+	Token    *jsonToken `json:"token,omitempty"`
+	Const    bool       `json:"const,omitempty"`
+	Optional bool       `json:"optional,omitempty"`
+	Slice    bool       `json:"slice,omitempty"`
+
+	Name         *jsonNode       `json:"name,omitempty"`
+	Value        json.RawMessage `json:"value,omitempty"`
+	Operator     string          `json:"operator,omitempty"`
+	Left         *jsonNode       `json:"left,omitempty"`
+	Right        *jsonNode       `json:"right,omitempty"`
+	Index        *jsonNode       `json:"index,omitempty"`
+	Start        *jsonNode       `json:"start,omitempty"`
+	End          *jsonNode       `json:"end,omitempty"`
+	Step         *jsonNode       `json:"step,omitempty"`
+	Expression   *jsonNode       `json:"expression,omitempty"`
+	Condition    *jsonNode       `json:"condition,omitempty"`
+	Consequence  *jsonNode       `json:"consequence,omitempty"`
+	Alternative  *jsonNode       `json:"alternative,omitempty"`
+	Function     *jsonNode       `json:"function,omitempty"`
+	Parameters   []*jsonNode     `json:"parameters,omitempty"`
+	Defaults     []*jsonNode     `json:"defaults,omitempty"`
+	Patterns     []*jsonNode     `json:"patterns,omitempty"`
+	Rest         *jsonNode       `json:"rest,omitempty"`
+	Arguments    []*jsonNode     `json:"arguments,omitempty"`
+	Elements     []*jsonNode     `json:"elements,omitempty"`
+	Pairs        []jsonPair      `json:"pairs,omitempty"`
+	Statements   []*jsonNode     `json:"statements,omitempty"`
+	Cases        []*jsonNode     `json:"cases,omitempty"`
+	Binding      *jsonNode       `json:"binding,omitempty"`
+	Operation    *jsonNode       `json:"operation,omitempty"`
+	Body         *jsonNode       `json:"body,omitempty"`
+	Default      *jsonNode       `json:"default,omitempty"`
+	Subject      *jsonNode       `json:"subject,omitempty"`
+	Arms         []*jsonNode     `json:"arms,omitempty"`
+	Pattern      *jsonNode       `json:"pattern,omitempty"`
+	Guard        *jsonNode       `json:"guard,omitempty"`
+	Alternatives []*jsonNode     `json:"alternatives,omitempty"`
+	Fields       []*jsonNode     `json:"fields,omitempty"`
+	Field        *jsonNode       `json:"field,omitempty"`
+	Target       *jsonNode       `json:"target,omitempty"`
+	Superclass   *jsonNode       `json:"superclass,omitempty"`
+	Methods      []*jsonNode     `json:"methods,omitempty"`
+	Type         *jsonNode       `json:"type,omitempty"`
+	Types        []*jsonNode     `json:"types,omitempty"`
+	ParamTypes   []*jsonNode     `json:"param_types,omitempty"`
+	RestType     *jsonNode       `json:"rest_type,omitempty"`
+	ReturnType   *jsonNode       `json:"return_type,omitempty"`
+}
+
+// Marshal returns the JSON encoding of node.
+func Marshal(node ast.Node) ([]byte, error) {
+	n, err := encode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(n)
+}
+
+// MarshalIndent is like Marshal but indents the output.
+func MarshalIndent(node ast.Node, prefix, indent string) ([]byte, error) {
+	n, err := encode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(n, prefix, indent)
+}
+
+// Unmarshal rebuilds a node from its JSON encoding.
+func Unmarshal(data []byte) (ast.Node, error) {
+	var n jsonNode
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, fmt.Errorf("astjson: %w", err)
+	}
+	return decode(&n)
+}
+
+// UnmarshalProgram rebuilds a program from its JSON encoding. It fails if
+// the encoded node is not a Program.
+func UnmarshalProgram(data []byte) (*ast.Program, error) {
+	node, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	program, ok := node.(*ast.Program)
+	if !ok {
+		return nil, fmt.Errorf("astjson: expected Program, got %s", kindOf(node))
+	}
+	return program, nil
+}
+
+func kindOf(node ast.Node) string {
+	return fmt.Sprintf("%T", node)[len("*ast."):]
+}
+
+func encodeToken(t token.Token) *jsonToken {
+	return &jsonToken{Type: t.Type, Literal: t.Literal, Line: t.Line, Column: t.Column}
+}
+
+func encodeRaw(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v) // strings, bools and numbers always encode
+	return data
+}
+
+func encode(node ast.Node) (*jsonNode, error) {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return nil, nil
+	}
+
+	var err error
+	child := func(node ast.Node) *jsonNode {
+		if err != nil {
+			return nil
+		}
+		var n *jsonNode
+		n, err = encode(node)
+		return n
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		n := &jsonNode{Kind: "Program"}
+		for _, stmt := range node.Statements {
+			n.Statements = append(n.Statements, child(stmt))
+		}
+		return n, err
+
+	case *ast.BlockStatement:
+		n := &jsonNode{Kind: "BlockStatement", Token: encodeToken(node.Token)}
+		for _, stmt := range node.Statements {
+			n.Statements = append(n.Statements, child(stmt))
+		}
+		return n, err
+
+	case *ast.ExpressionStatement:
+		n := &jsonNode{Kind: "ExpressionStatement", Token: encodeToken(node.Token)}
+		n.Expression = child(node.Expression)
+		return n, err
+
+	case *ast.LetStatement:
+		n := &jsonNode{Kind: "LetStatement", Token: encodeToken(node.Token), Const: node.Const}
+		n.Name = child(node.Name)
+		n.Pattern = child(node.Pattern)
+		n.Type = child(node.Type)
+		if value := child(node.Value); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.FunctionStatement:
+		n := &jsonNode{Kind: "FunctionStatement", Token: encodeToken(node.Token)}
+		n.Name = child(node.Name)
+		n.Function = child(node.Function)
+		return n, err
+
+	case *ast.StructStatement:
+		n := &jsonNode{Kind: "StructStatement", Token: encodeToken(node.Token)}
+		n.Name = child(node.Name)
+		for _, field := range node.Fields {
+			n.Fields = append(n.Fields, child(field))
+		}
+		return n, err
+
+	case *ast.ClassStatement:
+		n := &jsonNode{Kind: "ClassStatement", Token: encodeToken(node.Token)}
+		n.Name = child(node.Name)
+		n.Superclass = child(node.Super)
+		for _, method := range node.Methods {
+			n.Methods = append(n.Methods, child(method))
+		}
+		return n, err
+
+	case *ast.AssignStatement:
+		n := &jsonNode{Kind: "AssignStatement", Token: encodeToken(node.Token)}
+		n.Target = child(node.Target)
+		if value := child(node.Value); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.ReturnStatement:
+		n := &jsonNode{Kind: "ReturnStatement", Token: encodeToken(node.Token)}
+		if value := child(node.ReturnValue); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.YieldStatement:
+		n := &jsonNode{Kind: "YieldStatement", Token: encodeToken(node.Token)}
+		if value := child(node.Value); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.Identifier:
+		return &jsonNode{Kind: "Identifier", Token: encodeToken(node.Token), Value: encodeRaw(node.Value)}, nil
+
+	case *ast.IntegerLiteral:
+		value := json.Number(fmt.Sprint(node.Value))
+		if node.Big != nil {
+			value = json.Number(node.Big.String())
+		}
+		return &jsonNode{Kind: "IntegerLiteral", Token: encodeToken(node.Token), Value: encodeRaw(value)}, nil
+
+	case *ast.StringLiteral:
+		return &jsonNode{Kind: "StringLiteral", Token: encodeToken(node.Token), Value: encodeRaw(node.Value)}, nil
+
+	case *ast.Boolean:
+		return &jsonNode{Kind: "Boolean", Token: encodeToken(node.Token), Value: encodeRaw(node.Value)}, nil
+
+	case *ast.PrefixExpression:
+		n := &jsonNode{Kind: "PrefixExpression", Token: encodeToken(node.Token), Operator: node.Operator}
+		n.Right = child(node.Right)
+		return n, err
+
+	case *ast.InfixExpression:
+		n := &jsonNode{Kind: "InfixExpression", Token: encodeToken(node.Token), Operator: node.Operator}
+		n.Left = child(node.Left)
+		n.Right = child(node.Right)
+		return n, err
+
+	case *ast.IndexExpression:
+		n := &jsonNode{Kind: "IndexExpression", Token: encodeToken(node.Token), Optional: node.Optional, Slice: node.Slice}
+		n.Left = child(node.Left)
+		n.Index = child(node.Index)
+		n.Start = child(node.Start)
+		n.End = child(node.End)
+		n.Step = child(node.Step)
+		return n, err
+
+	case *ast.FieldExpression:
+		n := &jsonNode{Kind: "FieldExpression", Token: encodeToken(node.Token), Optional: node.Optional}
+		n.Left = child(node.Left)
+		n.Field = child(node.Field)
+		return n, err
+
+	case *ast.IfExpression:
+		n := &jsonNode{Kind: "IfExpression", Token: encodeToken(node.Token)}
+		n.Condition = child(node.Condition)
+		n.Consequence = child(node.Consequence)
+		n.Alternative = child(node.Alternative)
+		return n, err
+
+	case *ast.FunctionLiteral:
+		n := &jsonNode{Kind: "FunctionLiteral", Token: encodeToken(node.Token)}
+		for _, param := range node.Parameters {
+			n.Parameters = append(n.Parameters, child(param))
+		}
+		for _, def := range node.Defaults {
+			n.Defaults = append(n.Defaults, child(def))
+		}
+		for _, pattern := range node.Patterns {
+			n.Patterns = append(n.Patterns, child(pattern))
+		}
+		n.Rest = child(node.Rest)
+		for _, typ := range node.ParamTypes {
+			n.ParamTypes = append(n.ParamTypes, child(typ))
+		}
+		n.RestType = child(node.RestType)
+		n.ReturnType = child(node.ReturnType)
+		n.Body = child(node.Body)
+		return n, err
+
+	case *ast.MacroLiteral:
+		n := &jsonNode{Kind: "MacroLiteral", Token: encodeToken(node.Token)}
+		for _, param := range node.Parameters {
+			n.Parameters = append(n.Parameters, child(param))
+		}
+		n.Body = child(node.Body)
+		return n, err
+
+	case *ast.CallExpression:
+		n := &jsonNode{Kind: "CallExpression", Token: encodeToken(node.Token)}
+		n.Function = child(node.Function)
+		for _, arg := range node.Arguments {
+			n.Arguments = append(n.Arguments, child(arg))
+		}
+		return n, err
+
+	case *ast.KeywordArgument:
+		n := &jsonNode{Kind: "KeywordArgument", Token: encodeToken(node.Token)}
+		n.Name = child(node.Name)
+		if value := child(node.Value); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.SpreadExpression:
+		n := &jsonNode{Kind: "SpreadExpression", Token: encodeToken(node.Token)}
+		if value := child(node.Value); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.ArrayLiteral:
+		n := &jsonNode{Kind: "ArrayLiteral", Token: encodeToken(node.Token)}
+		for _, el := range node.Elements {
+			n.Elements = append(n.Elements, child(el))
+		}
+		return n, err
+
+	case *ast.HashLiteral:
+		n := &jsonNode{Kind: "HashLiteral", Token: encodeToken(node.Token)}
+		for _, key := range node.Keys {
+			n.Pairs = append(n.Pairs, jsonPair{Key: child(key), Value: child(node.Pairs[key])})
+		}
+		return n, err
+
+	case *ast.SelectExpression:
+		n := &jsonNode{Kind: "SelectExpression", Token: encodeToken(node.Token)}
+		for _, c := range node.Cases {
+			n.Cases = append(n.Cases, child(c))
+		}
+		n.Default = child(node.Default)
+		return n, err
+
+	case *ast.SelectCase:
+		n := &jsonNode{Kind: "SelectCase", Token: encodeToken(node.Token)}
+		n.Binding = child(node.Binding)
+		n.Operation = child(node.Operation)
+		n.Body = child(node.Body)
+		return n, err
+
+	case *ast.MatchExpression:
+		n := &jsonNode{Kind: "MatchExpression", Token: encodeToken(node.Token)}
+		n.Subject = child(node.Subject)
+		for _, arm := range node.Arms {
+			n.Arms = append(n.Arms, child(arm))
+		}
+		return n, err
+
+	case *ast.MatchArm:
+		n := &jsonNode{Kind: "MatchArm", Token: encodeToken(node.Token)}
+		n.Pattern = child(node.Pattern)
+		n.Guard = child(node.Guard)
+		n.Body = child(node.Body)
+		return n, err
+
+	case *ast.LiteralPattern:
+		n := &jsonNode{Kind: "LiteralPattern", Token: encodeToken(node.Token)}
+		if value := child(node.Value); value != nil && err == nil {
+			n.Value, err = json.Marshal(value)
+		}
+		return n, err
+
+	case *ast.WildcardPattern:
+		return &jsonNode{Kind: "WildcardPattern", Token: encodeToken(node.Token)}, nil
+
+	case *ast.BindingPattern:
+		n := &jsonNode{Kind: "BindingPattern", Token: encodeToken(node.Token)}
+		n.Name = child(node.Name)
+		return n, err
+
+	case *ast.ArrayPattern:
+		n := &jsonNode{Kind: "ArrayPattern", Token: encodeToken(node.Token)}
+		for _, el := range node.Elements {
+			n.Elements = append(n.Elements, child(el))
+		}
+		n.Rest = child(node.Rest)
+		return n, err
+
+	case *ast.HashPattern:
+		n := &jsonNode{Kind: "HashPattern", Token: encodeToken(node.Token)}
+		for i, key := range node.Keys {
+			n.Pairs = append(n.Pairs, jsonPair{Key: child(key), Value: child(node.Values[i])})
+		}
+		return n, err
+
+	case *ast.DefaultPattern:
+		n := &jsonNode{Kind: "DefaultPattern", Token: encodeToken(node.Token)}
+		n.Pattern = child(node.Pattern)
+		n.Default = child(node.Default)
+		return n, err
+
+	case *ast.AlternativePattern:
+		n := &jsonNode{Kind: "AlternativePattern", Token: encodeToken(node.Token)}
+		for _, alt := range node.Alternatives {
+			n.Alternatives = append(n.Alternatives, child(alt))
+		}
+		return n, err
+
+	case *ast.NamedType:
+		return &jsonNode{Kind: "NamedType", Token: encodeToken(node.Token), Value: encodeRaw(node.Name)}, nil
+
+	case *ast.ArrayType:
+		n := &jsonNode{Kind: "ArrayType", Token: encodeToken(node.Token)}
+		n.Type = child(node.Element)
+		return n, err
+
+	case *ast.HashType:
+		n := &jsonNode{Kind: "HashType", Token: encodeToken(node.Token)}
+		n.Types = []*jsonNode{child(node.Key), child(node.Value)}
+		return n, err
+
+	case *ast.FunctionType:
+		n := &jsonNode{Kind: "FunctionType", Token: encodeToken(node.Token)}
+		for _, param := range node.Params {
+			n.ParamTypes = append(n.ParamTypes, child(param))
+		}
+		n.RestType = child(node.Rest)
+		n.ReturnType = child(node.Return)
+		return n, err
+
+	case *ast.UnionType:
+		n := &jsonNode{Kind: "UnionType", Token: encodeToken(node.Token)}
+		for _, t := range node.Types {
+			n.Types = append(n.Types, child(t))
+		}
+		return n, err
+
+	case *ast.NullableType:
+		n := &jsonNode{Kind: "NullableType", Token: encodeToken(node.Token)}
+		n.Type = child(node.Type)
+		return n, err
+
+	default:
+		return nil, fmt.Errorf("astjson: cannot encode %T", node)
+	}
+}
+
+func decodeToken(t *jsonToken) token.Token {
+	if t == nil {
+		return token.Token{}
+	}
+	return token.Token{Type: t.Type, Literal: t.Literal, Line: t.Line, Column: t.Column}
+}
+
+// operatorToken returns the token an infix operator is written with, for
+// infix expressions decoded without one. The evaluator tells keyword
+// operators such as is apart by their token type.
+func operatorToken(op string) token.Token {
+	typ := token.TokenType(op)
+	if keyword := token.LookupIdent(op); keyword != token.IDENT {
+		typ = keyword
+	}
+	return token.Token{Type: typ, Literal: op}
+}
+
+func decode(n *jsonNode) (ast.Node, error) {
+	if n == nil {
+		return nil, fmt.Errorf("astjson: missing node")
+	}
+
+	d := decoder{kind: n.Kind}
+	tok := decodeToken(n.Token)
+
+	switch n.Kind {
+	case "Program":
+		return &ast.Program{Statements: d.statements(n.Statements)}, d.err
+
+	case "BlockStatement":
+		return &ast.BlockStatement{Token: tok, Statements: d.statements(n.Statements)}, d.err
+
+	case "ExpressionStatement":
+		return &ast.ExpressionStatement{Token: tok, Expression: d.expression("expression", n.Expression)}, d.err
+
+	case "LetStatement":
+		stmt := &ast.LetStatement{Token: tok, Const: n.Const}
+		if n.Pattern != nil {
+			stmt.Pattern = d.pattern("pattern", n.Pattern)
+		} else {
+			stmt.Name = d.identifier("name", n.Name)
+		}
+		if n.Type != nil {
+			stmt.Type = d.typ("type", n.Type)
+		}
+		stmt.Value = d.expression("value", d.rawNode("value", n.Value))
+		return stmt, d.err
+
+	case "FunctionStatement":
+		stmt := &ast.FunctionStatement{Token: tok, Name: d.identifier("name", n.Name)}
+		fn, ok := d.node("function", n.Function).(*ast.FunctionLiteral)
+		if !ok && d.err == nil {
+			d.err = fmt.Errorf("astjson: FunctionStatement: function must be FunctionLiteral, got %s", n.Function.Kind)
+		}
+		stmt.Function = fn
+		return stmt, d.err
+
+	case "StructStatement":
+		stmt := &ast.StructStatement{Token: tok, Name: d.identifier("name", n.Name)}
+		for _, field := range n.Fields {
+			stmt.Fields = append(stmt.Fields, d.identifier("fields", field))
+		}
+		return stmt, d.err
+
+	case "ClassStatement":
+		stmt := &ast.ClassStatement{Token: tok, Name: d.identifier("name", n.Name)}
+		if n.Superclass != nil {
+			stmt.Super = d.identifier("superclass", n.Superclass)
+		}
+		for _, m := range n.Methods {
+			method, ok := d.node("methods", m).(*ast.FunctionStatement)
+			if !ok && d.err == nil {
+				d.err = fmt.Errorf("astjson: ClassStatement: methods must be FunctionStatement, got %s", m.Kind)
+			}
+			stmt.Methods = append(stmt.Methods, method)
+		}
+		return stmt, d.err
+
+	case "AssignStatement":
+		return &ast.AssignStatement{Token: tok, Target: d.expression("target", n.Target), Value: d.expression("value", d.rawNode("value", n.Value))}, d.err
+
+	case "ReturnStatement":
+		return &ast.ReturnStatement{Token: tok, ReturnValue: d.expression("value", d.rawNode("value", n.Value))}, d.err
+
+	case "YieldStatement":
+		return &ast.YieldStatement{Token: tok, Value: d.expression("value", d.rawNode("value", n.Value))}, d.err
+
+	case "Identifier":
+		var value string
+		d.scalar(n.Value, &value)
+		return &ast.Identifier{Token: tok, Value: value}, d.err
+
+	case "IntegerLiteral":
+		var value json.Number
+		d.scalar(n.Value, &value)
+		if d.err != nil {
+			return nil, d.err
+		}
+		literal := &ast.IntegerLiteral{Token: tok}
+		v, err := strconv.ParseInt(value.String(), 10, 64)
+		if err == nil {
+			literal.Value = v
+			return literal, nil
+		}
+		b, ok := new(big.Int).SetString(value.String(), 10)
+		if !ok {
+			return nil, fmt.Errorf("astjson: IntegerLiteral: invalid value %s", value)
+		}
+		literal.Big = b
+		return literal, nil
+
+	case "StringLiteral":
+		var value string
+		d.scalar(n.Value, &value)
+		return &ast.StringLiteral{Token: tok, Value: value}, d.err
+
+	case "Boolean":
+		var value bool
+		d.scalar(n.Value, &value)
+		return &ast.Boolean{Token: tok, Value: value}, d.err
+
+	case "PrefixExpression":
+		return &ast.PrefixExpression{Token: tok, Operator: n.Operator, Right: d.expression("right", n.Right)}, d.err
+
+	case "InfixExpression":
+		if n.Token == nil {
+			tok = operatorToken(n.Operator)
+		}
+		return &ast.InfixExpression{
+			Token:    tok,
+			Left:     d.expression("left", n.Left),
+			Operator: n.Operator,
+			Right:    d.expression("right", n.Right),
+		}, d.err
+
+	case "IndexExpression":
+		exp := &ast.IndexExpression{Token: tok, Left: d.expression("left", n.Left), Optional: n.Optional, Slice: n.Slice}
+		if !n.Slice {
+			exp.Index = d.expression("index", n.Index)
+			return exp, d.err
+		}
+		if n.Start != nil {
+			exp.Start = d.expression("start", n.Start)
+		}
+		if n.End != nil {
+			exp.End = d.expression("end", n.End)
+		}
+		if n.Step != nil {
+			exp.Step = d.expression("step", n.Step)
+		}
+		return exp, d.err
+
+	case "FieldExpression":
+		return &ast.FieldExpression{Token: tok, Left: d.expression("left", n.Left), Field: d.identifier("field", n.Field), Optional: n.Optional}, d.err
+
+	case "IfExpression":
+		exp := &ast.IfExpression{
+			Token:       tok,
+			Condition:   d.expression("condition", n.Condition),
+			Consequence: d.block("consequence", n.Consequence),
+		}
+		if n.Alternative != nil {
+			exp.Alternative = d.block("alternative", n.Alternative)
+		}
+		return exp, d.err
+
+	case "FunctionLiteral":
+		fn := &ast.FunctionLiteral{Token: tok, Parameters: d.identifiers(n.Parameters)}
+		if len(n.Defaults) > len(n.Parameters) {
+			return nil, fmt.Errorf("astjson: FunctionLiteral: more defaults than parameters")
+		}
+		for _, def := range n.Defaults {
+			if def == nil {
+				fn.Defaults = append(fn.Defaults, nil)
+				continue
+			}
+			fn.Defaults = append(fn.Defaults, d.expression("defaults", def))
+		}
+		if len(n.Patterns) > len(n.Parameters) {
+			return nil, fmt.Errorf("astjson: FunctionLiteral: more patterns than parameters")
+		}
+		for _, pattern := range n.Patterns {
+			if pattern == nil {
+				fn.Patterns = append(fn.Patterns, nil)
+				continue
+			}
+			fn.Patterns = append(fn.Patterns, d.pattern("patterns", pattern))
+		}
+		if n.Rest != nil {
+			fn.Rest = d.identifier("rest", n.Rest)
+		}
+		if len(n.ParamTypes) > len(n.Parameters) {
+			return nil, fmt.Errorf("astjson: FunctionLiteral: more parameter types than parameters")
+		}
+		fn.ParamTypes = d.optionalTypes("param_types", n.ParamTypes)
+		if n.RestType != nil {
+			fn.RestType = d.typ("rest_type", n.RestType)
+		}
+		if n.ReturnType != nil {
+			fn.ReturnType = d.typ("return_type", n.ReturnType)
+		}
+		fn.Body = d.block("body", n.Body)
+		fn.Generator = fn.Body != nil && ast.Yields(fn.Body)
+		return fn, d.err
+
+	case "MacroLiteral":
+		return &ast.MacroLiteral{Token: tok, Parameters: d.identifiers(n.Parameters), Body: d.block("body", n.Body)}, d.err
+
+	case "CallExpression":
+		return &ast.CallExpression{Token: tok, Function: d.expression("function", n.Function), Arguments: d.expressions("arguments", n.Arguments)}, d.err
+
+	case "KeywordArgument":
+		return &ast.KeywordArgument{Token: tok, Name: d.identifier("name", n.Name), Value: d.expression("value", d.rawNode("value", n.Value))}, d.err
+
+	case "SpreadExpression":
+		return &ast.SpreadExpression{Token: tok, Value: d.expression("value", d.rawNode("value", n.Value))}, d.err
+
+	case "ArrayLiteral":
+		return &ast.ArrayLiteral{Token: tok, Elements: d.expressions("elements", n.Elements)}, d.err
+
+	case "HashLiteral":
+		hash := &ast.HashLiteral{Token: tok, Pairs: map[ast.Expression]ast.Expression{}}
+		for _, pair := range n.Pairs {
+			key := d.expression("key", pair.Key)
+			value := d.expression("value", pair.Value)
+			if d.err != nil {
+				return nil, d.err
+			}
+			hash.Keys = append(hash.Keys, key)
+			hash.Pairs[key] = value
+		}
+		return hash, nil
+
+	case "SelectExpression":
+		exp := &ast.SelectExpression{Token: tok}
+		for _, c := range n.Cases {
+			if selectCase, ok := d.node("cases", c).(*ast.SelectCase); ok {
+				exp.Cases = append(exp.Cases, selectCase)
+			} else if d.err == nil {
+				d.err = fmt.Errorf("astjson: SelectExpression: cases must be SelectCase, got %s", c.Kind)
+			}
+		}
+		if n.Default != nil {
+			exp.Default = d.block("default", n.Default)
+		}
+		return exp, d.err
+
+	case "SelectCase":
+		c := &ast.SelectCase{Token: tok, Body: d.block("body", n.Body)}
+		if n.Binding != nil {
+			c.Binding = d.identifier("binding", n.Binding)
+		}
+		operation, ok := d.node("operation", n.Operation).(*ast.CallExpression)
+		if !ok && d.err == nil {
+			d.err = fmt.Errorf("astjson: SelectCase: operation must be CallExpression, got %s", n.Operation.Kind)
+		}
+		c.Operation = operation
+		return c, d.err
+
+	case "MatchExpression":
+		exp := &ast.MatchExpression{Token: tok, Subject: d.expression("subject", n.Subject)}
+		for _, arm := range n.Arms {
+			if matchArm, ok := d.node("arms", arm).(*ast.MatchArm); ok {
+				exp.Arms = append(exp.Arms, matchArm)
+			} else if d.err == nil {
+				d.err = fmt.Errorf("astjson: MatchExpression: arms must be MatchArm, got %s", arm.Kind)
+			}
+		}
+		return exp, d.err
+
+	case "MatchArm":
+		arm := &ast.MatchArm{Token: tok, Pattern: d.pattern("pattern", n.Pattern)}
+		if n.Guard != nil {
+			arm.Guard = d.expression("guard", n.Guard)
+		}
+		arm.Body = d.expression("body", n.Body)
+		return arm, d.err
+
+	case "LiteralPattern":
+		return &ast.LiteralPattern{Token: tok, Value: d.expression("value", d.rawNode("value", n.Value))}, d.err
+
+	case "WildcardPattern":
+		return &ast.WildcardPattern{Token: tok}, nil
+
+	case "BindingPattern":
+		return &ast.BindingPattern{Token: tok, Name: d.identifier("name", n.Name)}, d.err
+
+	case "ArrayPattern":
+		pattern := &ast.ArrayPattern{Token: tok}
+		for _, el := range n.Elements {
+			pattern.Elements = append(pattern.Elements, d.pattern("elements", el))
+		}
+		if n.Rest != nil {
+			pattern.Rest = d.identifier("rest", n.Rest)
+		}
+		return pattern, d.err
+
+	case "HashPattern":
+		pattern := &ast.HashPattern{Token: tok}
+		for _, pair := range n.Pairs {
+			pattern.Keys = append(pattern.Keys, d.expression("key", pair.Key))
+			pattern.Values = append(pattern.Values, d.pattern("value", pair.Value))
+		}
+		return pattern, d.err
+
+	case "DefaultPattern":
+		return &ast.DefaultPattern{Token: tok, Pattern: d.pattern("pattern", n.Pattern), Default: d.expression("default", n.Default)}, d.err
+
+	case "AlternativePattern":
+		pattern := &ast.AlternativePattern{Token: tok}
+		for _, alt := range n.Alternatives {
+			pattern.Alternatives = append(pattern.Alternatives, d.pattern("alternatives", alt))
+		}
+		return pattern, d.err
+
+	case "NamedType":
+		var name string
+		d.scalar(n.Value, &name)
+		return &ast.NamedType{Token: tok, Name: name}, d.err
+
+	case "ArrayType":
+		return &ast.ArrayType{Token: tok, Element: d.typ("type", n.Type)}, d.err
+
+	case "HashType":
+		if len(n.Types) != 2 {
+			return nil, fmt.Errorf("astjson: HashType: types must hold a key and a value type")
+		}
+		return &ast.HashType{Token: tok, Key: d.typ("types", n.Types[0]), Value: d.typ("types", n.Types[1])}, d.err
+
+	case "FunctionType":
+		typ := &ast.FunctionType{Token: tok}
+		for _, param := range n.ParamTypes {
+			typ.Params = append(typ.Params, d.typ("param_types", param))
+		}
+		if n.RestType != nil {
+			typ.Rest = d.typ("rest_type", n.RestType)
+		}
+		if n.ReturnType != nil {
+			typ.Return = d.typ("return_type", n.ReturnType)
+		}
+		return typ, d.err
+
+	case "UnionType":
+		typ := &ast.UnionType{Token: tok}
+		for _, t := range n.Types {
+			typ.Types = append(typ.Types, d.typ("types", t))
+		}
+		return typ, d.err
+
+	case "NullableType":
+		return &ast.NullableType{Token: tok, Type: d.typ("type", n.Type)}, d.err
+
+	case "":
+		return nil, fmt.Errorf("astjson: node without a kind")
+
+	default:
+		return nil, fmt.Errorf("astjson: unknown node kind %q", n.Kind)
+	}
+}
+
+// decoder decodes the children of a node of the given kind. It records the
+// first error and turns later calls into no-ops, so a node can be built in a
+// single expression and the error checked once.
+type decoder struct {
+	kind string
+	err  error
+}
+
+func (d *decoder) node(field string, n *jsonNode) ast.Node {
+	if d.err != nil {
+		return nil
+	}
+	if n == nil {
+		d.err = fmt.Errorf("astjson: %s: missing %s", d.kind, field)
+		return nil
+	}
+	node, err := decode(n)
+	if err != nil {
+		d.err = err
+		return nil
+	}
+	return node
+}
+
+func (d *decoder) expression(field string, n *jsonNode) ast.Expression {
+	node := d.node(field, n)
+	if d.err != nil {
+		return nil
+	}
+	exp, ok := node.(ast.Expression)
+	if !ok {
+		d.err = fmt.Errorf("astjson: %s: %s must be an expression, got %s", d.kind, field, n.Kind)
+	}
+	return exp
+}
+
+func (d *decoder) pattern(field string, n *jsonNode) ast.Pattern {
+	node := d.node(field, n)
+	if d.err != nil {
+		return nil
+	}
+	pattern, ok := node.(ast.Pattern)
+	if !ok {
+		d.err = fmt.Errorf("astjson: %s: %s must be a pattern, got %s", d.kind, field, n.Kind)
+	}
+	return pattern
+}
+
+func (d *decoder) typ(field string, n *jsonNode) ast.TypeExpression {
+	node := d.node(field, n)
+	if d.err != nil {
+		return nil
+	}
+	typ, ok := node.(ast.TypeExpression)
+	if !ok {
+		d.err = fmt.Errorf("astjson: %s: %s must be a type, got %s", d.kind, field, n.Kind)
+	}
+	return typ
+}
+
+// optionalTypes decodes a list of types in which null stands for a missing
+// type. It returns nil for an empty list.
+func (d *decoder) optionalTypes(field string, ns []*jsonNode) []ast.TypeExpression {
+	var types []ast.TypeExpression
+	for _, n := range ns {
+		if n == nil {
+			types = append(types, nil)
+			continue
+		}
+		types = append(types, d.typ(field, n))
+	}
+	return types
+}
+
+func (d *decoder) expressions(field string, ns []*jsonNode) []ast.Expression {
+	exps := []ast.Expression{}
+	for _, n := range ns {
+		exps = append(exps, d.expression(field, n))
+	}
+	return exps
+}
+
+func (d *decoder) statements(ns []*jsonNode) []ast.Statement {
+	stmts := []ast.Statement{}
+	for _, n := range ns {
+		node := d.node("statement", n)
+		if d.err != nil {
+			return nil
+		}
+		stmt, ok := node.(ast.Statement)
+		if !ok {
+			d.err = fmt.Errorf("astjson: %s: statements must be statements, got %s", d.kind, n.Kind)
+			return nil
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+func (d *decoder) block(field string, n *jsonNode) *ast.BlockStatement {
+	node := d.node(field, n)
+	if d.err != nil {
+		return nil
+	}
+	block, ok := node.(*ast.BlockStatement)
+	if !ok {
+		d.err = fmt.Errorf("astjson: %s: %s must be BlockStatement, got %s", d.kind, field, n.Kind)
+	}
+	return block
+}
+
+func (d *decoder) identifier(field string, n *jsonNode) *ast.Identifier {
+	node := d.node(field, n)
+	if d.err != nil {
+		return nil
+	}
+	ident, ok := node.(*ast.Identifier)
+	if !ok {
+		d.err = fmt.Errorf("astjson: %s: %s must be Identifier, got %s", d.kind, field, n.Kind)
+	}
+	return ident
+}
+
+func (d *decoder) identifiers(ns []*jsonNode) []*ast.Identifier {
+	idents := []*ast.Identifier{}
+	for _, n := range ns {
+		idents = append(idents, d.identifier("parameters", n))
+	}
+	return idents
+}
+
+// rawNode decodes a node stored in a json.RawMessage field.
+func (d *decoder) rawNode(field string, raw json.RawMessage) *jsonNode {
+	if d.err != nil || raw == nil {
+		return nil
+	}
+	var n jsonNode
+	if err := json.Unmarshal(raw, &n); err != nil {
+		d.err = fmt.Errorf("astjson: %s: %s must be a node: %w", d.kind, field, err)
+		return nil
+	}
+	return &n
+}
+
+// scalar decodes the value of a literal or identifier into v.
+func (d *decoder) scalar(raw json.RawMessage, v interface{}) {
+	if d.err != nil {
+		return
+	}
+	if raw == nil {
+		d.err = fmt.Errorf("astjson: %s: missing value", d.kind)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		d.err = fmt.Errorf("astjson: %s: invalid value %s", d.kind, raw)
+	}
+}
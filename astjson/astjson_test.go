@@ -0,0 +1,217 @@
+package astjson_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/astjson"
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, errs)
+	}
+	return program
+}
+
+func TestMarshal(t *testing.T) {
+	program := parse(t, "let x = -5;\nx + 1")
+
+	data, err := astjson.Marshal(program)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+
+	expected := `{"kind":"Program","statements":[` +
+		`{"kind":"LetStatement","token":{"type":"LET","literal":"let","line":1,"column":1},` +
+		`"name":{"kind":"Identifier","token":{"type":"IDENT","literal":"x","line":1,"column":5},"value":"x"},` +
+		`"value":{"kind":"PrefixExpression","token":{"type":"-","literal":"-","line":1,"column":9},"operator":"-",` +
+		`"right":{"kind":"IntegerLiteral","token":{"type":"INT","literal":"5","line":1,"column":10},"value":5}}},` +
+		`{"kind":"ExpressionStatement","token":{"type":"IDENT","literal":"x","line":2,"column":1},` +
+		`"expression":{"kind":"InfixExpression","token":{"type":"+","literal":"+","line":2,"column":3},"operator":"+",` +
+		`"left":{"kind":"Identifier","token":{"type":"IDENT","literal":"x","line":2,"column":1},"value":"x"},` +
+		`"right":{"kind":"IntegerLiteral","token":{"type":"INT","literal":"1","line":2,"column":5},"value":1}}}]}`
+
+	if string(data) != expected {
+		t.Errorf("wrong JSON.\ngot:  %s\nwant: %s", data, expected)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	inputs := []string{
+		`let five = 5; let ten = 10;`,
+		`return five;`,
+		`"hello world"; true; false`,
+		`!true; -a; a + b * c; a < b; a == b; a != b`,
+		`let add = fn(x, y) { x + y; }; add(1, 2 * 3)`,
+		`fn() { return 1; }`,
+		`if (x < y) { x } else { y }; if (x) { 1 }`,
+		`[1, "two", [3]][0]`,
+		`{"b": 1, "a": 2, 3: [4]}`,
+		`{}`,
+		`123456789012345678901234567890`,
+		`let m = macro(x) { quote(unquote(x) + 1) };`,
+		`select { case let v = recv(ch) { v } case send(ch, 1) { 2 } default { 3 } }`,
+		`select { case recv(ch) { 1 } }`,
+		`fn(a, b = a * 2, ...rest) { rest }; fn(...args) { args }`,
+		`f(1, ...xs, b: 2)`,
+		`fn add(a, b = 1) { a + b } add(1)`,
+		`match (x) { 0 | -1 => 1, [h, ...t] if h > 1 => h, {"k": [_, v], 2: true} => v, n => n }`,
+		`let [a, {"b": b = 1}, ...c] = xs; fn(x, [y, z = 2] = []) { y }`,
+		`struct Point { x, y } struct Empty {} let p = Point(1, y: 2); p.x = p.y + 1; p.x.y`,
+		`class A { fn init(x) { self.x = x; } } class B extends A { fn f() { super.init(1) } } class C {} B(1) is A`,
+		`a?.b?["c"].d ?? [1][0]`,
+		`xs[1:-1]; xs[:]; xs[::2]; s?[:n:-1]; xs[0]`,
+		`fn gen(n) { yield n; yield n + 1; } let g = fn() { if (true) { yield 1; } };`,
+		`const c = 1; const [d]: [int] = [c]; xs[0] = h["k"];`,
+		`let x: int? = 1; fn f(a: [int], b: {string: int | bool} = {}, ...r: [fn(int, ...[string]) -> any]) -> (fn() -> int)? { a }`,
+	}
+
+	for _, input := range inputs {
+		program := parse(t, input)
+		data, err := astjson.Marshal(program)
+		if err != nil {
+			t.Fatalf("%s: Marshal returned error: %s", input, err)
+		}
+
+		decoded, err := astjson.UnmarshalProgram(data)
+		if err != nil {
+			t.Fatalf("%s: UnmarshalProgram returned error: %s", input, err)
+		}
+		if decoded.String() != program.String() {
+			t.Errorf("%s: round trip changed the program.\ngot:  %s\nwant: %s", input, decoded.String(), program.String())
+		}
+
+		again, err := astjson.Marshal(decoded)
+		if err != nil {
+			t.Fatalf("%s: Marshal of decoded program returned error: %s", input, err)
+		}
+		if !bytes.Equal(again, data) {
+			t.Errorf("%s: round trip changed the JSON.\ngot:  %s\nwant: %s", input, again, data)
+		}
+	}
+}
+
+func TestDecodedProgramRuns(t *testing.T) {
+	source := `
+	let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+	let squares = {"a": 1 * 1, "b": 2 * 2};
+	fn pair() { yield 1; yield 2; }
+	[fib(10), squares["b"], 9223372036854775807 + 1, collect(pair())]
+	`
+	data, err := astjson.MarshalIndent(parse(t, source), "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent returned error: %s", err)
+	}
+
+	program, err := astjson.UnmarshalProgram(data)
+	if err != nil {
+		t.Fatalf("UnmarshalProgram returned error: %s", err)
+	}
+
+	evaluated := evaluator.Eval(program, object.NewEnvironment())
+	if got, want := evaluated.Inspect(), "[55, 4, 9223372036854775808, [1, 2]]"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestUnmarshalHandWrittenProgram(t *testing.T) {
+	// Tokens and positions are optional.
+	data := `{"kind": "Program", "statements": [
+		{"kind": "ExpressionStatement", "expression": {
+			"kind": "CallExpression",
+			"function": {"kind": "Identifier", "value": "len"},
+			"arguments": [{"kind": "StringLiteral", "value": "jian"}]
+		}}
+	]}`
+
+	program, err := astjson.UnmarshalProgram([]byte(data))
+	if err != nil {
+		t.Fatalf("UnmarshalProgram returned error: %s", err)
+	}
+
+	evaluated := evaluator.Eval(program, object.NewEnvironment())
+	if got := evaluated.Inspect(); got != "4" {
+		t.Errorf("expected 4, got %s", got)
+	}
+}
+
+func TestDecodedProgramWithoutTokensRuns(t *testing.T) {
+	data, err := astjson.Marshal(parse(t, `struct P { x } let p = P(1); [p is P, 1 is P, p.x + 1 == 2]`))
+	if err != nil {
+		t.Fatalf("Marshal returned error: %s", err)
+	}
+
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %s", err)
+	}
+	var strip func(v interface{})
+	strip = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			delete(v, "token")
+			for _, child := range v {
+				strip(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				strip(child)
+			}
+		}
+	}
+	strip(tree)
+	if data, err = json.Marshal(tree); err != nil {
+		t.Fatalf("json.Marshal returned error: %s", err)
+	}
+
+	program, err := astjson.UnmarshalProgram(data)
+	if err != nil {
+		t.Fatalf("UnmarshalProgram returned error: %s", err)
+	}
+	evaluated := evaluator.Eval(program, object.NewEnvironment())
+	if got, want := evaluated.Inspect(), "[true, false, true]"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[`, "astjson: unexpected end of JSON input"},
+		{`{}`, "astjson: node without a kind"},
+		{`{"kind": "Loop"}`, `astjson: unknown node kind "Loop"`},
+		{`{"kind": "Identifier", "value": "x"}`, "astjson: expected Program, got Identifier"},
+		{`{"kind": "Program", "statements": [{"kind": "Identifier", "value": "x"}]}`, "astjson: Program: statements must be statements, got Identifier"},
+		{`{"kind": "Program", "statements": [{"kind": "ExpressionStatement"}]}`, "astjson: ExpressionStatement: missing expression"},
+		{`{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "InfixExpression", "operator": "+", "left": {"kind": "IntegerLiteral", "value": 1}}}]}`, "astjson: InfixExpression: missing right"},
+		{`{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "IntegerLiteral", "value": "one"}}]}`, `astjson: IntegerLiteral: invalid value "one"`},
+		{`{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "IntegerLiteral", "value": 1.5}}]}`, "astjson: IntegerLiteral: invalid value 1.5"},
+		{`{"kind": "Program", "statements": [{"kind": "LetStatement", "name": {"kind": "StringLiteral", "value": "x"}, "value": {"kind": "Boolean", "value": true}}]}`, "astjson: LetStatement: name must be Identifier, got StringLiteral"},
+		{`{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "BlockStatement"}}]}`, "astjson: ExpressionStatement: expression must be an expression, got BlockStatement"},
+		{`{"kind": "Program", "statements": [{"kind": "LetStatement", "name": {"kind": "Identifier", "value": "x"}, "type": {"kind": "Identifier", "value": "int"}, "value": {"kind": "Boolean", "value": true}}]}`, "astjson: LetStatement: type must be a type, got Identifier"},
+	}
+
+	for _, tt := range tests {
+		_, err := astjson.UnmarshalProgram([]byte(tt.input))
+		if err == nil {
+			t.Errorf("%s: expected error %q, got none", tt.input, tt.expected)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, err.Error())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/evaluator"
+)
+
+// builtinTypes gives the parameter and result types of the builtins whose
+// types are known. Other builtins take arguments of any type, as many as
+// their signature allows, and return any.
+var builtinTypes = map[string]struct {
+	params []Type
+	result Type
+}{
+	"len":            {[]Type{union(String, &Array{Element: Any})}, Int},
+	"first":          {[]Type{&Array{Element: Any}}, Any},
+	"last":           {[]Type{&Array{Element: Any}}, Any},
+	"rest":           {[]Type{&Array{Element: Any}}, &Array{Element: Any}},
+	"push":           {[]Type{&Array{Element: Any}, Any}, &Array{Element: Any}},
+	"type_of":        {nil, String},
+	"instanceof":     {nil, Bool},
+	"contains":       {nil, Bool},
+	"index_of":       {nil, Int},
+	"puts":           {nil, Null},
+	"print":          {nil, Null},
+	"eprint":         {nil, Null},
+	"input":          {nil, union(String, Null)},
+	"read_line":      {nil, union(String, Null)},
+	"read_file":      {[]Type{String}, String},
+	"write_file":     {[]Type{String, String}, Any},
+	"append_file":    {[]Type{String, String}, Any},
+	"list_dir":       {[]Type{String}, &Array{Element: String}},
+	"exists":         {[]Type{String}, Bool},
+	"remove":         {[]Type{String}, Any},
+	"path_join":      {nil, String},
+	"path_base":      {[]Type{String}, String},
+	"path_dir":       {[]Type{String}, String},
+	"path_ext":       {[]Type{String}, String},
+	"json_parse":     {[]Type{String}, Any},
+	"json_stringify": {nil, String},
+	"is_done":        {nil, Bool},
+	"collect":        {nil, &Array{Element: Any}},
+	"take":           {[]Type{Any, Int}, Any},
+	"count_from":     {[]Type{Int, Int}, Any},
+}
+
+// builtinType returns the type of the builtin called name, if there is
+// one.
+func builtinType(name string) (*Func, bool) {
+	builtin, ok := evaluator.LookupBuiltin(name)
+	if !ok {
+		return nil, false
+	}
+
+	fn := &Func{Rest: Any, Return: Any}
+	if sig := builtin.Signature; sig != nil {
+		fn.Required = sig.Required
+		for _, param := range sig.Params {
+			fn.Params = append(fn.Params, Param{Name: param, Type: Any})
+		}
+		if sig.Variadic == "" {
+			fn.Rest = nil
+		}
+	}
+
+	if known, ok := builtinTypes[name]; ok {
+		for i, t := range known.params {
+			fn.Params[i].Type = t
+		}
+		fn.Return = known.result
+	}
+	return fn, true
+}
+
+// builtinResult works out the result of a call to first, last, rest or
+// push from the type of the array passed to it, and of a call to freeze from
+// the type of its argument, which the declared types of those builtins do
+// not capture.
+func builtinResult(callee ast.Expression, s *scope, args []Type) (Type, bool) {
+	ident, ok := callee.(*ast.Identifier)
+	if !ok || len(args) == 0 {
+		return nil, false
+	}
+	if _, shadowed := s.lookup(ident.Value); shadowed {
+		return nil, false
+	}
+	if ident.Value == "freeze" {
+		return args[0], true
+	}
+	arr, ok := args[0].(*Array)
+	if !ok {
+		return nil, false
+	}
+
+	switch ident.Value {
+	case "first", "last":
+		return arr.Element, true
+	case "rest":
+		return arr, true
+	case "push":
+		if len(args) < 2 {
+			return nil, false
+		}
+		if assignable(args[1], arr.Element) {
+			return arr, true
+		}
+		return &Array{Element: element(arr.Element, args[1])}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,1026 @@
+// Package checker type-checks programs before they run.
+//
+// Checking is gradual: annotated bindings, parameters and return values are
+// checked against their annotations, the types of unannotated locals are
+// inferred from the values bound to them, and anything the checker cannot
+// know, such as an unannotated parameter, has type any and is accepted
+// everywhere. Programs should have their macros expanded before they are
+// checked.
+package checker
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/token"
+)
+
+// Error is a type error found in a program.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Check type-checks program and returns the errors it finds in source
+// order.
+func Check(program *ast.Program) []*Error {
+	c := &checker{signatures: map[*ast.FunctionLiteral]*Func{}, literals: map[ast.Expression]Type{}}
+	c.block(program.Statements, newScope(nil))
+	return c.errors
+}
+
+type checker struct {
+	errors []*Error
+	// signatures holds the types of declared functions and methods, which
+	// are worked out before their bodies are checked.
+	signatures map[*ast.FunctionLiteral]*Func
+	// literals holds the types of the array and hash literals that mix
+	// element types, with the union of those types as their element type.
+	// Such literals have elements of type any where nothing is annotated,
+	// but are checked against annotations with these types.
+	literals map[ast.Expression]Type
+}
+
+func (c *checker) errorf(node ast.Node, format string, args ...interface{}) {
+	tok := tokenOf(node)
+	c.errors = append(c.errors, &Error{Line: tok.Line, Column: tok.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// tokenOf returns the token of node, which every node but Program has.
+func tokenOf(node ast.Node) token.Token {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return token.Token{}
+	}
+	if tok, ok := v.Elem().FieldByName("Token").Interface().(token.Token); ok {
+		return tok
+	}
+	return token.Token{}
+}
+
+// function is the function whose body is being checked. The declared
+// return type of a generator function is the type of the values it yields,
+// and its return statements are not checked.
+type function struct {
+	name      string // prefixes the errors about its result, if set
+	result    Type   // the declared return type, or nil
+	returns   []Type // the types of its return statements
+	generator bool
+}
+
+type scope struct {
+	vars   map[string]Type
+	types  map[string]*Named
+	consts map[string]bool // names declared with const, which cannot be declared again
+	outer  *scope
+	fn     *function
+}
+
+func newScope(outer *scope) *scope {
+	s := &scope{vars: map[string]Type{}, types: map[string]*Named{}, consts: map[string]bool{}, outer: outer}
+	if outer != nil {
+		s.fn = outer.fn
+	}
+	return s
+}
+
+func (s *scope) lookup(name string) (Type, bool) {
+	for ; s != nil; s = s.outer {
+		if t, ok := s.vars[name]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) lookupType(name string) (*Named, bool) {
+	for ; s != nil; s = s.outer {
+		if t, ok := s.types[name]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// block checks stmts in s and returns the type of the value they produce.
+// Like the evaluator, it binds function declarations before checking any
+// statement. Names bound later in the block are declared up front with type
+// any, so that functions referring to them are not reported.
+func (c *checker) block(stmts []ast.Statement, s *scope) Type {
+	c.declare(stmts, s)
+
+	var result Type = Null
+	for _, stmt := range stmts {
+		result = c.statement(stmt, s)
+	}
+	return result
+}
+
+// declare binds the names stmts declare in s: struct and class types and
+// their constructors, the signatures of function declarations, and any for
+// the names bound by let statements, including those in nested if blocks.
+func (c *checker) declare(stmts []ast.Statement, s *scope) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.StructStatement:
+			named := &Named{Name: stmt.Name.Value}
+			ctor := &Func{Return: named}
+			for _, field := range stmt.Fields {
+				named.Fields = append(named.Fields, field.Value)
+				ctor.Params = append(ctor.Params, Param{Name: field.Value, Type: Any})
+			}
+			ctor.Required = len(ctor.Params)
+			s.types[named.Name] = named
+			s.vars[named.Name] = ctor
+		case *ast.ClassStatement:
+			s.types[stmt.Name.Value] = &Named{Name: stmt.Name.Value, Class: true, Methods: map[string]*Func{}}
+		}
+	}
+
+	var classes []*ast.ClassStatement
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.ClassStatement:
+			named := s.types[stmt.Name.Value]
+			if stmt.Super != nil {
+				if super, ok := s.lookupType(stmt.Super.Value); ok && super.Class && !super.extends(named) {
+					named.Super = super
+				}
+			}
+			for _, method := range stmt.Methods {
+				named.Methods[method.Name.Value] = c.declareFunction(method.Function, s)
+			}
+			classes = append(classes, stmt)
+		case *ast.FunctionStatement:
+			s.vars[stmt.Name.Value] = c.declareFunction(stmt.Function, s)
+		}
+	}
+	// Constructors take the parameters of init, which may be inherited from
+	// a class declared after the one being constructed.
+	for _, stmt := range classes {
+		named := s.types[stmt.Name.Value]
+		ctor := &Func{Return: named}
+		if init, ok := named.method("init"); ok {
+			ctor = &Func{Params: init.Params, Required: init.Required, Rest: init.Rest, Return: named}
+		}
+		s.vars[named.Name] = ctor
+	}
+
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FunctionLiteral, *ast.MatchArm, *ast.SelectCase, *ast.ClassStatement:
+				return false
+			case *ast.CallExpression:
+				ident, ok := node.Function.(*ast.Identifier)
+				return !ok || ident.Value != "quote"
+			case *ast.LetStatement:
+				for _, name := range letBindings(node) {
+					if _, ok := s.vars[name.Value]; !ok {
+						s.vars[name.Value] = Any
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// declareFunction records and returns the signature of a declared function
+// or method.
+func (c *checker) declareFunction(fn *ast.FunctionLiteral, s *scope) *Func {
+	sig := c.signature(fn, s)
+	c.signatures[fn] = sig
+	return sig
+}
+
+func letBindings(stmt *ast.LetStatement) []*ast.Identifier {
+	if stmt.Pattern != nil {
+		return ast.PatternBindings(stmt.Pattern)
+	}
+	return []*ast.Identifier{stmt.Name}
+}
+
+func (c *checker) statement(stmt ast.Statement, s *scope) Type {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		return c.expression(stmt.Expression, s)
+
+	case *ast.LetStatement:
+		for _, name := range letBindings(stmt) {
+			c.checkRedeclaration(name, s)
+		}
+		t := c.expression(stmt.Value, s)
+		if stmt.Type != nil {
+			want := c.resolve(stmt.Type, s)
+			if got := c.annotated(stmt.Value, t); !assignable(got, want) {
+				var name ast.Node = stmt.Name
+				if stmt.Pattern != nil {
+					name = stmt.Pattern
+				}
+				c.errorf(stmt.Value, "%s must be %s, got %s", name, want, got)
+			}
+			t = want
+		}
+		if stmt.Pattern != nil {
+			c.bind(stmt.Pattern, t, s)
+		} else {
+			s.vars[stmt.Name.Value] = t
+		}
+		if stmt.Const {
+			for _, name := range letBindings(stmt) {
+				s.consts[name.Value] = true
+			}
+		}
+
+	case *ast.ReturnStatement:
+		t := c.expression(stmt.ReturnValue, s)
+		if fn := s.fn; fn != nil {
+			fn.returns = append(fn.returns, t)
+			c.checkResult(fn, c.annotated(stmt.ReturnValue, t), stmt.ReturnValue)
+		}
+		return never
+
+	case *ast.YieldStatement:
+		t := c.annotated(stmt.Value, c.expression(stmt.Value, s))
+		fn := s.fn
+		if fn == nil || !fn.generator {
+			c.errorf(stmt, "yield outside of a generator")
+		} else if fn.result != nil && !assignable(t, fn.result) {
+			c.errorf(stmt.Value, "%syielded value must be %s, got %s", prefix(fn.name), fn.result, t)
+		}
+
+	case *ast.FunctionStatement:
+		c.checkRedeclaration(stmt.Name, s)
+		s.vars[stmt.Name.Value] = c.function(stmt.Function, s, stmt.Name.Value)
+
+	case *ast.StructStatement:
+		c.checkRedeclaration(stmt.Name, s)
+
+	case *ast.ClassStatement:
+		c.checkRedeclaration(stmt.Name, s)
+		c.class(stmt, s)
+
+	case *ast.AssignStatement:
+		c.assign(stmt, s)
+	}
+	return Null
+}
+
+// annotated returns the type to check exp, of type t, against an
+// annotation with. It differs from t for array and hash literals that mix
+// element types, whose every element must fit the annotation.
+func (c *checker) annotated(exp ast.Expression, t Type) Type {
+	if literal, ok := c.literals[exp]; ok {
+		return literal
+	}
+	return t
+}
+
+// checkRedeclaration reports a declaration of name in s after a constant
+// with the same name.
+func (c *checker) checkRedeclaration(name *ast.Identifier, s *scope) {
+	if s.consts[name.Value] {
+		c.errorf(name, "cannot redeclare constant %s", name)
+	}
+}
+
+// checkResult reports a result of type t of fn that does not fit its
+// declared return type.
+func (c *checker) checkResult(fn *function, t Type, node ast.Node) {
+	if fn.result == nil || fn.generator || assignable(t, fn.result) {
+		return
+	}
+	c.errorf(node, "%sreturn value must be %s, got %s", prefix(fn.name), fn.result, t)
+}
+
+func prefix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return name + ": "
+}
+
+// class checks the methods of a class declared in s, with self bound to an
+// instance of the class and super to its superclass.
+func (c *checker) class(stmt *ast.ClassStatement, s *scope) {
+	named := s.types[stmt.Name.Value]
+	if stmt.Super != nil {
+		c.expression(stmt.Super, s)
+	}
+
+	methodScope := newScope(s)
+	methodScope.vars["self"] = named
+	if named.Super != nil {
+		methodScope.vars["super"] = named.Super
+	}
+	for _, method := range stmt.Methods {
+		c.function(method.Function, methodScope, stmt.Name.Value+"."+method.Name.Value)
+	}
+}
+
+func (c *checker) assign(stmt *ast.AssignStatement, s *scope) {
+	switch target := stmt.Target.(type) {
+	case *ast.FieldExpression:
+		c.expression(stmt.Value, s)
+		c.assignField(target, s)
+	case *ast.IndexExpression:
+		if target.Slice {
+			c.errorf(target, "cannot assign to %s", target)
+			return
+		}
+		c.assignIndex(target, stmt.Value, s)
+	}
+}
+
+// assignIndex checks the assignment of node to an array element or hash
+// entry.
+func (c *checker) assignIndex(target *ast.IndexExpression, node ast.Expression, s *scope) {
+	left := c.expression(target.Left, s)
+	index := c.expression(target.Index, s)
+	value := c.annotated(node, c.expression(node, s))
+	for _, t := range members(left) {
+		switch t := t.(type) {
+		case *Array:
+			if !assignable(index, Int) {
+				c.errorf(target.Index, "array index must be int, got %s", index)
+			}
+			if !assignable(value, t.Element) {
+				c.errorf(node, "element of %s must be %s, got %s", t, t.Element, value)
+			}
+		case *Hash:
+			if !assignable(index, t.Key) {
+				c.errorf(target.Index, "hash key must be %s, got %s", t.Key, index)
+			}
+			if !assignable(value, t.Value) {
+				c.errorf(node, "value of %s must be %s, got %s", t, t.Value, value)
+			}
+		default:
+			if t != Any {
+				c.errorf(target, "index assignment not supported: %s", t)
+			}
+		}
+	}
+}
+
+func (c *checker) assignField(target *ast.FieldExpression, s *scope) {
+	left := c.expression(target.Left, s)
+	for _, t := range members(left) {
+		switch t := t.(type) {
+		case *Named:
+			if !t.Class && !slices.Contains(t.Fields, target.Field.Value) {
+				c.errorf(target, "%s has no field %s", t, target.Field.Value)
+			}
+		case Basic:
+			if t != Any {
+				c.errorf(target, "field assignment not supported: %s", t)
+			}
+		default:
+			c.errorf(target, "field assignment not supported: %s", t)
+		}
+	}
+}
+
+// signature returns the type of fn as its annotations declare it. Missing
+// annotations are any, as is the result of a generator function.
+func (c *checker) signature(fn *ast.FunctionLiteral, s *scope) *Func {
+	sig := &Func{Return: Any}
+	for i, param := range fn.Parameters {
+		t := Type(Any)
+		if annotation := fn.ParamType(i); annotation != nil {
+			t = c.resolve(annotation, s)
+		}
+		sig.Params = append(sig.Params, Param{Name: param.Value, Type: t})
+		if fn.Default(i) == nil {
+			sig.Required = i + 1
+		}
+	}
+	if fn.Rest != nil {
+		sig.Rest = Any
+		if fn.RestType != nil {
+			if arr, ok := c.resolve(fn.RestType, s).(*Array); ok {
+				sig.Rest = arr.Element
+			}
+		}
+	}
+	if fn.ReturnType != nil && !isGenerator(fn) {
+		sig.Return = c.resolve(fn.ReturnType, s)
+	}
+	return sig
+}
+
+// isGenerator reports whether fn is a generator function, whose calls
+// return a generator rather than running its body.
+func isGenerator(fn *ast.FunctionLiteral) bool {
+	return fn.Generator
+}
+
+// function checks the body of fn, which is called name if it has one, and
+// returns its type. Without a declared return type the result is inferred
+// from the body.
+func (c *checker) function(fn *ast.FunctionLiteral, s *scope, name string) *Func {
+	sig, ok := c.signatures[fn]
+	if !ok {
+		sig = c.signature(fn, s)
+	}
+	inner := newScope(s)
+	inner.fn = &function{name: name, generator: isGenerator(fn)}
+	if fn.ReturnType != nil {
+		inner.fn.result = sig.Return
+		if inner.fn.generator {
+			inner.fn.result = c.resolve(fn.ReturnType, s)
+		}
+	}
+
+	for i, param := range fn.Parameters {
+		t := sig.Params[i].Type
+		if def := fn.Default(i); def != nil {
+			dt := c.annotated(def, c.expression(def, inner))
+			if !assignable(dt, t) {
+				c.errorf(def, "%sdefault value of `%s` must be %s, got %s", prefix(name), param.Value, t, dt)
+			}
+		}
+		if pattern := fn.Pattern(i); pattern != nil {
+			c.bind(pattern, t, inner)
+		} else {
+			inner.vars[param.Value] = t
+		}
+	}
+	if fn.Rest != nil {
+		inner.vars[fn.Rest.Value] = &Array{Element: sig.Rest}
+	}
+
+	var result Type = Null
+	if fn.Body != nil {
+		result = c.block(fn.Body.Statements, inner)
+		if n := len(fn.Body.Statements); n > 0 && result != never {
+			last := fn.Body.Statements[n-1]
+			if stmt, ok := last.(*ast.ExpressionStatement); ok {
+				c.checkResult(inner.fn, c.annotated(stmt.Expression, result), last)
+			} else {
+				c.checkResult(inner.fn, result, last)
+			}
+		}
+	}
+	if fn.ReturnType == nil && !inner.fn.generator {
+		sig.Return = union(append(inner.fn.returns, result)...)
+		if sig.Return == never {
+			sig.Return = Null
+		}
+	}
+	return sig
+}
+
+// resolve returns the type an annotation stands for.
+func (c *checker) resolve(annotation ast.TypeExpression, s *scope) Type {
+	switch annotation := annotation.(type) {
+	case *ast.NamedType:
+		switch annotation.Name {
+		case "int":
+			return Int
+		case "float":
+			return Float
+		case "string":
+			return String
+		case "bool":
+			return Bool
+		case "null":
+			return Null
+		case "any":
+			return Any
+		}
+		if named, ok := s.lookupType(annotation.Name); ok {
+			return named
+		}
+		c.errorf(annotation, "unknown type %s", annotation.Name)
+		return Any
+
+	case *ast.ArrayType:
+		return &Array{Element: c.resolve(annotation.Element, s)}
+
+	case *ast.HashType:
+		return &Hash{Key: c.resolve(annotation.Key, s), Value: c.resolve(annotation.Value, s)}
+
+	case *ast.FunctionType:
+		fn := &Func{Required: len(annotation.Params), Return: Any}
+		for _, param := range annotation.Params {
+			fn.Params = append(fn.Params, Param{Type: c.resolve(param, s)})
+		}
+		if annotation.Rest != nil {
+			fn.Rest = Any
+			if arr, ok := c.resolve(annotation.Rest, s).(*Array); ok {
+				fn.Rest = arr.Element
+			}
+		}
+		if annotation.Return != nil {
+			fn.Return = c.resolve(annotation.Return, s)
+		}
+		return fn
+
+	case *ast.UnionType:
+		types := make([]Type, 0, len(annotation.Types))
+		for _, t := range annotation.Types {
+			types = append(types, c.resolve(t, s))
+		}
+		return union(types...)
+
+	case *ast.NullableType:
+		return union(c.resolve(annotation.Type, s), Null)
+
+	default:
+		return Any
+	}
+}
+
+// bind binds the names in pattern, which matches values of type t, in s.
+func (c *checker) bind(pattern ast.Pattern, t Type, s *scope) {
+	switch pattern := pattern.(type) {
+	case *ast.BindingPattern:
+		s.vars[pattern.Name.Value] = t
+
+	case *ast.ArrayPattern:
+		var element Type = Any
+		if arr, ok := t.(*Array); ok {
+			element = arr.Element
+		}
+		for _, el := range pattern.Elements {
+			c.bind(el, element, s)
+		}
+		if pattern.Rest != nil && pattern.Rest.Value != "_" {
+			s.vars[pattern.Rest.Value] = &Array{Element: element}
+		}
+
+	case *ast.HashPattern:
+		var value Type = Any
+		if hash, ok := t.(*Hash); ok {
+			value = hash.Value
+		}
+		for i, key := range pattern.Keys {
+			c.expression(key, s)
+			c.bind(pattern.Values[i], value, s)
+		}
+
+	case *ast.DefaultPattern:
+		c.bind(pattern.Pattern, union(t, c.expression(pattern.Default, s)), s)
+
+	case *ast.AlternativePattern:
+		for _, alt := range pattern.Alternatives {
+			c.bind(alt, t, s)
+		}
+	}
+}
+
+func (c *checker) expression(exp ast.Expression, s *scope) Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+
+	case *ast.StringLiteral:
+		return String
+
+	case *ast.Boolean:
+		return Bool
+
+	case *ast.Identifier:
+		if t, ok := s.lookup(exp.Value); ok {
+			return t
+		}
+		if t, ok := builtinType(exp.Value); ok {
+			return t
+		}
+		c.errorf(exp, "identifier not found: %s", exp.Value)
+		return Any
+
+	case *ast.PrefixExpression:
+		return c.prefix(exp, c.expression(exp.Right, s))
+
+	case *ast.InfixExpression:
+		left := c.expression(exp.Left, s)
+		right := c.expression(exp.Right, s)
+		return c.infix(exp, left, right)
+
+	case *ast.IndexExpression, *ast.FieldExpression, *ast.CallExpression:
+		t, optional := c.chain(exp, s)
+		if optional {
+			return union(t, Null)
+		}
+		return t
+
+	case *ast.IfExpression:
+		c.expression(exp.Condition, s)
+		consequence := c.block(exp.Consequence.Statements, s)
+		var alternative Type = Null
+		if exp.Alternative != nil {
+			alternative = c.block(exp.Alternative.Statements, s)
+		}
+		return union(consequence, alternative)
+
+	case *ast.FunctionLiteral:
+		return c.function(exp, s, "")
+
+	case *ast.ArrayLiteral:
+		if len(exp.Elements) == 0 {
+			return &Array{Element: Any}
+		}
+		var elements, exact []Type
+		for _, el := range exp.Elements {
+			t := c.expression(el, s)
+			elements = append(elements, t)
+			exact = append(exact, c.annotated(el, t))
+		}
+		t := &Array{Element: element(elements...)}
+		if literal := (&Array{Element: union(exact...)}); literal.String() != t.String() {
+			c.literals[exp] = literal
+		}
+		return t
+
+	case *ast.HashLiteral:
+		if len(exp.Keys) == 0 {
+			return &Hash{Key: Any, Value: Any}
+		}
+		var keys, values, exactKeys, exactValues []Type
+		for _, key := range exp.Keys {
+			k, v := c.expression(key, s), c.expression(exp.Pairs[key], s)
+			keys, values = append(keys, k), append(values, v)
+			exactKeys = append(exactKeys, c.annotated(key, k))
+			exactValues = append(exactValues, c.annotated(exp.Pairs[key], v))
+		}
+		t := &Hash{Key: element(keys...), Value: element(values...)}
+		if literal := (&Hash{Key: union(exactKeys...), Value: union(exactValues...)}); literal.String() != t.String() {
+			c.literals[exp] = literal
+		}
+		return t
+
+	case *ast.MatchExpression:
+		subject := c.expression(exp.Subject, s)
+		var arms []Type
+		for _, arm := range exp.Arms {
+			armScope := newScope(s)
+			c.bind(arm.Pattern, subject, armScope)
+			if arm.Guard != nil {
+				c.expression(arm.Guard, armScope)
+			}
+			arms = append(arms, c.expression(arm.Body, armScope))
+		}
+		return union(arms...)
+
+	case *ast.SelectExpression:
+		var cases []Type
+		for _, sc := range exp.Cases {
+			caseScope := newScope(s)
+			c.expression(sc.Operation, s)
+			if sc.Binding != nil {
+				caseScope.vars[sc.Binding.Value] = Any
+			}
+			cases = append(cases, c.block(sc.Body.Statements, caseScope))
+		}
+		if exp.Default != nil {
+			cases = append(cases, c.block(exp.Default.Statements, s))
+		}
+		return union(cases...)
+
+	default:
+		return Any
+	}
+}
+
+func (c *checker) prefix(exp *ast.PrefixExpression, right Type) Type {
+	if exp.Operator == token.BANG {
+		return Bool
+	}
+	for _, t := range members(right) {
+		if t != Int && t != Float && t != Any {
+			c.errorf(exp, "unknown operator: %s%s", exp.Operator, right)
+			return Any
+		}
+	}
+	return right
+}
+
+// infix returns the type of an infix operation on operands of type left
+// and right, reporting operations that fail at runtime whatever values the
+// operands hold.
+func (c *checker) infix(exp *ast.InfixExpression, left, right Type) Type {
+	if exp.Token.Type == token.IS {
+		return Bool
+	}
+	switch exp.Operator {
+	case token.EQ, token.NOT_EQ:
+		return Bool
+	case token.COALESCE:
+		return union(withoutNull(left), right)
+	}
+
+	var results []Type
+	for _, l := range members(left) {
+		for _, r := range members(right) {
+			t, err := operate(l, exp.Operator, r)
+			if err != "" {
+				c.errorf(exp, "%s: %s %s %s", err, operand(left), exp.Operator, operand(right))
+				return Any
+			}
+			results = append(results, t)
+		}
+	}
+	return union(results...)
+}
+
+// operand formats the type of an operand, with parentheses around unions
+// so that they do not read as part of the operation.
+func operand(t Type) string {
+	if u, ok := t.(*Union); ok && !strings.HasSuffix(u.String(), "?") {
+		return "(" + u.String() + ")"
+	}
+	return t.String()
+}
+
+// operate returns the type of l op r for operands that are not unions, or
+// the kind of error the operation raises.
+func operate(l Type, op string, r Type) (Type, string) {
+	comparison := op == token.LT || op == token.GT
+	if l == Any || r == Any {
+		if comparison {
+			return Bool, ""
+		}
+		if l == Int || r == Int {
+			return Int, ""
+		}
+		if l == Float || r == Float {
+			return Float, ""
+		}
+		return Any, ""
+	}
+	if kind(l) != kind(r) {
+		return nil, "type mismatch"
+	}
+
+	switch {
+	case (l == Int || l == Float) && comparison:
+		return Bool, ""
+	case l == Int || l == Float:
+		return l, ""
+	case l == String && op == token.PLUS:
+		return String, ""
+	case (l == String || kind(l) == object.ARRAY) && comparison:
+		return Bool, ""
+	default:
+		return nil, "unknown operator"
+	}
+}
+
+// kind returns the object type of values of type t, which the evaluator
+// compares to decide whether an operation is a type mismatch.
+func kind(t Type) object.ObjectType {
+	switch t := t.(type) {
+	case *Array:
+		return object.ARRAY
+	case *Hash:
+		return object.HASH
+	case *Func:
+		return object.FUNCTION
+	case *Named:
+		if t.Class {
+			return object.INSTANCE
+		}
+		return object.STRUCT
+	default:
+		return object.ObjectType(t.String())
+	}
+}
+
+func (c *checker) index(exp *ast.IndexExpression, left, index Type) Type {
+	var results []Type
+	for _, t := range members(left) {
+		switch t := t.(type) {
+		case *Array:
+			if !assignable(index, Int) {
+				c.errorf(exp.Index, "array index must be int, got %s", index)
+			}
+			results = append(results, t.Element)
+		case *Hash:
+			if !assignable(index, t.Key) {
+				c.errorf(exp.Index, "hash key must be %s, got %s", t.Key, index)
+			}
+			results = append(results, t.Value)
+		default:
+			switch t {
+			case Any:
+				results = append(results, Any)
+			case String:
+				if !assignable(index, Int) {
+					c.errorf(exp.Index, "string index must be int, got %s", index)
+				}
+				results = append(results, String)
+			default:
+				c.errorf(exp, "index operator not supported: %s", left)
+				return Any
+			}
+		}
+	}
+	return union(results...)
+}
+
+// slice checks the bounds of a slice of a value of type left, and returns
+// the type of the slice, which is the type of left for arrays and strings.
+func (c *checker) slice(exp *ast.IndexExpression, left Type, s *scope) Type {
+	for _, bound := range []ast.Expression{exp.Start, exp.End, exp.Step} {
+		if bound == nil {
+			continue
+		}
+		if t := c.expression(bound, s); !assignable(t, union(Int, Null)) {
+			c.errorf(bound, "slice bound must be int, got %s", t)
+		}
+	}
+	if left == never {
+		return never
+	}
+
+	var results []Type
+	for _, t := range members(left) {
+		if _, ok := t.(*Array); !ok && t != String && t != Any {
+			c.errorf(exp, "slice operator not supported: %s", left)
+			return Any
+		}
+		results = append(results, t)
+	}
+	return union(results...)
+}
+
+// chain returns the type of exp, which may be a link in a chain of field
+// accesses, indexes and calls such as a?.b[0].c(), leaving out the null that
+// an optional link (?. or ?[) produces when it is applied to null. It also
+// reports whether the chain has an optional link.
+func (c *checker) chain(exp ast.Expression, s *scope) (Type, bool) {
+	switch exp := exp.(type) {
+	case *ast.IndexExpression:
+		left, optional := c.link(exp.Left, exp.Optional, s)
+		if exp.Slice {
+			return c.slice(exp, left, s), optional
+		}
+		index := c.expression(exp.Index, s)
+		if left == never {
+			return never, optional
+		}
+		return c.index(exp, left, index), optional
+
+	case *ast.FieldExpression:
+		left, optional := c.link(exp.Left, exp.Optional, s)
+		if left == never {
+			return never, optional
+		}
+		return c.field(exp, left), optional
+
+	case *ast.CallExpression:
+		if ident, ok := exp.Function.(*ast.Identifier); ok && ident.Value == "quote" {
+			return Any, false
+		}
+		callee, optional := c.link(exp.Function, false, s)
+		result := c.call(exp, callee, s)
+		if callee == never {
+			return never, optional
+		}
+		return result, optional
+
+	default:
+		return c.expression(exp, s), false
+	}
+}
+
+// link returns the type of the operand of a link in a chain, without null
+// if the link is optional, and whether the chain up to and including the
+// link has an optional link. The type is never if the operand is always
+// null, so the link is always skipped.
+func (c *checker) link(exp ast.Expression, optional bool, s *scope) (Type, bool) {
+	t, chained := c.chain(exp, s)
+	if optional {
+		t = withoutNull(t)
+	}
+	return t, chained || optional
+}
+
+func (c *checker) field(exp *ast.FieldExpression, left Type) Type {
+	var results []Type
+	for _, t := range members(left) {
+		named, ok := t.(*Named)
+		switch {
+		case t == Any:
+			results = append(results, Any)
+		case !ok:
+			c.errorf(exp, "field access not supported: %s", left)
+			return Any
+		case named.Class:
+			if method, ok := named.method(exp.Field.Value); ok {
+				results = append(results, method)
+			} else {
+				results = append(results, Any)
+			}
+		case slices.Contains(named.Fields, exp.Field.Value):
+			results = append(results, Any)
+		default:
+			c.errorf(exp, "%s has no field %s", named, exp.Field.Value)
+			return Any
+		}
+	}
+	return union(results...)
+}
+
+// call checks the arguments of a call against the parameters of the
+// function called, if its type is known, and returns the type of the
+// result.
+func (c *checker) call(call *ast.CallExpression, callee Type, s *scope) Type {
+	var args []Type
+	var positional []ast.Expression
+	var keywords []*ast.KeywordArgument
+	spread := false
+	for _, arg := range call.Arguments {
+		switch arg := arg.(type) {
+		case *ast.KeywordArgument:
+			keywords = append(keywords, arg)
+		case *ast.SpreadExpression:
+			t := c.expression(arg.Value, s)
+			if !assignable(t, &Array{Element: Any}) {
+				c.errorf(arg, "spread argument must be an array, got %s", t)
+			}
+			spread = true
+		default:
+			if !spread {
+				args = append(args, c.expression(arg, s))
+				positional = append(positional, arg)
+			} else {
+				c.expression(arg, s)
+			}
+		}
+	}
+	keywordTypes := make([]Type, len(keywords))
+	for i, kw := range keywords {
+		keywordTypes[i] = c.expression(kw.Value, s)
+	}
+
+	fn, ok := callee.(*Func)
+	if !ok {
+		if _, isUnion := callee.(*Union); callee != Any && callee != never && !isUnion {
+			c.errorf(call, "not a function: %s", callee)
+		}
+		return Any
+	}
+
+	name := ""
+	if ident, ok := call.Function.(*ast.Identifier); ok {
+		name = ident.Value
+	}
+
+	if !spread && len(args) > len(fn.Params) && fn.Rest == nil {
+		c.errorf(call, "%sexpected %s arguments, got %d", prefix(name), arity(fn), len(args))
+		return fn.Return
+	}
+	for i, t := range args {
+		c.checkArgument(fn, name, i, c.annotated(positional[i], t), positional[i])
+	}
+
+	filled := len(args)
+	for i, kw := range keywords {
+		param := slices.IndexFunc(fn.Params, func(p Param) bool { return p.Name == kw.Name.Value })
+		if param < 0 {
+			c.errorf(kw, "%sunexpected keyword argument `%s`", prefix(name), kw.Name.Value)
+			continue
+		}
+		c.checkArgument(fn, name, param, c.annotated(kw.Value, keywordTypes[i]), kw.Value)
+		filled = max(filled, param+1)
+	}
+	if !spread && filled < fn.Required {
+		c.errorf(call, "%sexpected %s arguments, got %d", prefix(name), arity(fn), len(args)+len(keywords))
+	}
+
+	if result, ok := builtinResult(call.Function, s, args); ok {
+		return result
+	}
+	return fn.Return
+}
+
+func (c *checker) checkArgument(fn *Func, name string, i int, t Type, arg ast.Node) {
+	want := fn.paramType(i)
+	if assignable(t, want) {
+		return
+	}
+	param := fmt.Sprint(i + 1)
+	if i < len(fn.Params) && fn.Params[i].Name != "" {
+		param = "`" + fn.Params[i].Name + "`"
+	}
+	c.errorf(arg, "%sargument %s must be %s, got %s", prefix(name), param, want, t)
+}
+
+func arity(fn *Func) string {
+	if fn.Rest != nil {
+		return object.DescribeArity(fn.Required, -1)
+	}
+	return object.DescribeArity(fn.Required, len(fn.Params))
+}
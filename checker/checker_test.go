@@ -0,0 +1,216 @@
+package checker_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/checker"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/parser"
+)
+
+func check(t *testing.T, input string) []*checker.Error {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("%s: parser errors: %v", input, errs)
+	}
+	return checker.Check(program)
+}
+
+func TestWellTypedPrograms(t *testing.T) {
+	inputs := []string{
+		`let x: int = 5; let s: string = "a"; let b: bool = x > 1;`,
+		`let x = 5; let y: int = x * 2 - 1;`,
+		`let xs: [int] = [1, 2, 3]; let ys: [int] = push(xs, 4); let n: int = first(ys) + len(xs);`,
+		`let xs: [int] = []; let h: {string: int} = {};`,
+		`let h: {string: int | bool} = {"a": 1, "b": true};`,
+		`let x: int? = if (true) { 1 };`,
+		`let x: int | string = if (true) { 1 } else { "a" };`,
+		`let f: fn(int) -> int = fn(a: int) -> int { a + 1 }; f(1);`,
+		`let f: fn(int, int) -> any = fn(a, b = 2) { a };`,
+		`let f: fn(...[int]) -> [int] = fn(...xs: [int]) { xs };`,
+		`fn add(a: int, b: int = 1) -> int { a + b } add(1); add(1, 2); add(a: 1, b: 2);`,
+		`fn sum(...xs: [int]) -> int { if (len(xs) == 0) { return 0; } first(xs) + sum(...rest(xs)) } sum(1, 2, 3);`,
+		`fn f(n) { n + 1 } f("any argument is fine for an unannotated parameter");`,
+		`fn even(n: int) -> bool { if (n == 0) { true } else { odd(n - 1) } } fn odd(n: int) -> bool { if (n == 0) { false } else { even(n - 1) } }`,
+		`let g = fn() { later }; let later = 1;`,
+		`let [a, b]: [int] = [1, 2]; let c: int = a + b;`,
+		`let {"k": v} = {"k": 1}; let w: int = v;`,
+		`fn f([a, b = 1]: [int]) -> int { a + b }`,
+		`struct P { x, y } let p: P = P(1, y: 2); p.x; p.y = 3;`,
+		`class A { fn init(n: int) { self.n = n } fn get() -> int { self.n } } class B extends A { fn get() -> int { super.get() + 1 } } let a: A = B(1); let n: int = a.get();`,
+		`class A {} let a: A? = if (true) { A() }; a is A;`,
+		`let r = match (1) { 0 => "zero", n => n }; let s: int | string = r;`,
+		`let f: fn() -> int = fn() { return 1; };`,
+		`let s: string = "a" + "b"; let b: bool = "a" < "b"; let c: bool = [1] < [2];`,
+		`let x = 1; puts(x == "a", x != true);`,
+		`let t = type_of(1); let ok: bool = contains([1], 1); let line: string? = read_line();`,
+		`let unless = macro(c, a) { quote(if (!(unquote(c))) { unquote(a) }) };`,
+		`let v: any = 1; let s: string = v;`,
+		`const limit: int = 3; let f = fn() { let limit = "shadowed"; limit };`,
+		`const xs: [int] = freeze([1]); let n: int = xs[0]; let ys = []; ys[0] = "a";`,
+		`let h: {string: int} = {}; h["a"] = 1; let grid: [[int]] = [[0]]; grid[0][0] = 1;`,
+		`let v: any = 1; v[0] = 2;`,
+		`let h: {string: {string: int}} = {}; let n: int = h["a"]?["b"] ?? 0;`,
+		`let h: {string: int}? = if (true) { {} }; let n: int? = h?["a"]; let m: int = h?["a"] ?? 1;`,
+		`struct P { next } fn f(p: P?) { p?.next.next } f(P(1));`,
+		`class C { fn get() -> int { 1 } } let c: C? = if (true) { C() }; let n: int = c?.get() ?? 0;`,
+		`let s: string = puts()?.x ?? "quiet"; let t: string = puts()?.f().g ?? "quiet";`,
+		`let xs: [int] = [1, 2, 3]; let ys: [int] = xs[1:]; let zs: [int] = xs[::-1]; let n: int = xs[-1];`,
+		`let s: string = "abc"; let t: string = s[1:-1]; let c: string = s[0];`,
+		`let xs: [int]? = if (true) { [1] }; let ys: [int]? = xs?[:1]; let v: any = 1; v[1:];`,
+		`let n: int? = if (true) { 1 }; let xs = [1, 2]; xs[n:n:n];`,
+		`fn gen(n: int) -> int { yield n; yield n + 1; } let g = gen(1); let v = next(g); let xs: [any] = collect(g);`,
+		`fn gen() -> int { if (false) { return "stop"; } yield 1; "ignored" } let done: bool = is_done(next(gen()));`,
+		`let evens = filter_iter(map_iter(count_from(), fn(x) { x * 2 }), fn(x) { x > 2 }); collect(take(evens, 3));`,
+		`fn gen() { yield 1; } let n: int = len(gen()); let f = first(gen()); let r = rest(gen());`,
+		`{"name": "Alice", "age": 30}["name"] + "!";`,
+		`let person = {"name": "Alice", "age": 30}; len(person["name"]); person["age"] + 1;`,
+		`[1, "a"][0] + 1; [1, "a"][1] + "b"; let s: string = [1, "a"][1];`,
+		`freeze({"ports": [80, 443], "debug": false})["ports"][0] + 1;`,
+		`let xs = push([1], "a"); xs[1] + "b"; let ys: [int] = push([1], 2);`,
+		`fn scale(x: float, by: float) -> float { -x * by / by - x } let b: bool = scale(json_parse("1.5"), json_parse("2.0")) < json_parse("0.5");`,
+	}
+
+	for _, input := range inputs {
+		if errs := check(t, input); len(errs) != 0 {
+			t.Errorf("%s: expected no errors, got %v", input, errs)
+		}
+	}
+}
+
+func TestTypeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let x: int = "a";`, "1:14: x must be int, got string"},
+		{`let [a, b]: [int] = "ab";`, "1:21: [a, b] must be [int], got string"},
+		{`let x = 1;
+let y = x + "a";`, "2:11: type mismatch: int + string"},
+		{`let x: int | string = 1; x - 1;`, "1:28: type mismatch: (int | string) - int"},
+		{`"a" - "b"`, "1:5: unknown operator: string - string"},
+		{`-true`, "1:1: unknown operator: -bool"},
+		{`fn f(x: float) { x + 1 }`, "1:20: type mismatch: float + int"},
+		{`let x: int? = 1; x + 1`, "1:20: type mismatch: int? + int"},
+		{`y`, "1:1: identifier not found: y"},
+		{`fn f(a: int) {} f("s")`, "1:19: f: argument `a` must be int, got string"},
+		{`fn f(a: int) {} f(a: true)`, "1:22: f: argument `a` must be int, got bool"},
+		{`fn f(a) {} f(1, 2)`, "1:13: f: expected 1 arguments, got 2"},
+		{`fn f(a, b = 1) {} f()`, "1:20: f: expected 1 or 2 arguments, got 0"},
+		{`fn f(a) {} f(b: 1)`, "1:14: f: unexpected keyword argument `b`"},
+		{`fn f(...xs: [int]) {} f(1, "a")`, "1:28: f: argument 2 must be int, got string"},
+		{`fn f(a: int = "x") {}`, "1:15: f: default value of `a` must be int, got string"},
+		{`fn f() -> int { "s" }`, "1:17: f: return value must be int, got string"},
+		{`fn f(x) -> int { if (x) { return "s"; } 1 }`, "1:34: f: return value must be int, got string"},
+		{`fn f() -> string { 1 } let x: int = f();`, "1:20: f: return value must be string, got int"},
+		{`fn f() -> string { "s" } let x: int = f();`, "1:40: x must be int, got string"},
+		{`let f = fn(a: int) { a }; f("s")`, "1:29: f: argument `a` must be int, got string"},
+		{`let f: fn(string) -> int = fn(a: int) { a };`, "1:28: f must be fn(string) -> int, got fn(int) -> int"},
+		{`let f: fn() -> int = fn(a) { a };`, "1:22: f must be fn() -> int, got fn(any) -> any"},
+		{`let f: fn() -> int = fn(a = 1) { a };`, ""},
+		{`let f: fn(int) = fn() { 1 };`, "1:18: f must be fn(int) -> any, got fn() -> int"},
+		{`1(2)`, "1:2: not a function: int"},
+		{`len(1)`, "1:5: len: argument `arg` must be string | [any], got int"},
+		{`len()`, "1:4: len: expected 1 arguments, got 0"},
+		{`let xs = [1]; xs["a"]`, "1:18: array index must be int, got string"},
+		{`let h = {"a": 1}; h[1]`, "1:21: hash key must be string, got int"},
+		{`let n = 1; n[0]`, "1:13: index operator not supported: int"},
+		{`let h: {string: int} = {"a": "b"};`, "1:24: h must be {string: int}, got {string: string}"},
+		{`let xs: [string] = [1, "a"];`, "1:20: xs must be [string], got [int | string]"},
+		{`let xs: [string] = [1, 2];`, "1:20: xs must be [string], got [int]"},
+		{`let h: {string: string} = {"name": "Alice", "age": 30};`, "1:27: h must be {string: string}, got {string: string | int}"},
+		{`let grid: [[int]] = [[1, "a"]];`, "1:21: grid must be [[int]], got [[int | string]]"},
+		{`fn f(xs: [int]) {} f([1, "a"]);`, "1:22: f: argument `xs` must be [int], got [int | string]"},
+		{`fn f(xs: [int] = [1, "a"]) {}`, "1:18: f: default value of `xs` must be [int], got [int | string]"},
+		{`fn f() -> [int] { [1, "a"] }`, "1:19: f: return value must be [int], got [int | string]"},
+		{`fn f() -> [int] { return [1, "a"]; }`, "1:26: f: return value must be [int], got [int | string]"},
+		{`let xs: [[int]] = [[1]]; xs[0] = [1, "a"];`, "1:34: element of [[int]] must be [int], got [int | string]"},
+		{`let xs = push([1], 2); let s: string = xs[0];`, "1:42: s must be string, got int"},
+		{`struct P { x } P(1).y`, "1:20: P has no field y"},
+		{`struct P { x } let p = P(1); p.y = 2;`, "1:31: P has no field y"},
+		{`struct P { x } P()`, "1:17: P: expected 1 arguments, got 0"},
+		{`1.x`, "1:2: field access not supported: int"},
+		{`let n = 1; n.x = 1;`, "1:13: field assignment not supported: int"},
+		{`class A {} class B {} let a: A = B();`, "1:35: a must be A, got B"},
+		{`class A {} class B extends A {} let b: B = A();`, "1:45: b must be B, got A"},
+		{`class A { fn init(n: int) {} } A("s")`, "1:34: A: argument `n` must be int, got string"},
+		{`class A { fn init(n: int) {} } class B extends A {} B()`, "1:54: B: expected 1 arguments, got 0"},
+		{`class A { fn get() -> int { "s" } }`, "1:29: A.get: return value must be int, got string"},
+		{`let x: Foo = 1;`, "1:8: unknown type Foo"},
+		{`fn f(p: Point) {} struct Point { x }`, ""},
+		{`let s: string = match (1) { 0 => "zero", n => n };`, "1:17: s must be string, got string | int"},
+		{`len(...1)`, "1:5: spread argument must be an array, got int"},
+		{`let h: {string: int}? = if (true) { {} }; h["a"]`, "1:44: index operator not supported: {string: int}?"},
+		{`let h: {string: int}? = if (true) { {} }; let n: int = h?["a"];`, "1:57: n must be int, got int?"},
+		{`let x: int? = if (true) { 1 }; let s: string = x ?? "a";`, "1:50: s must be string, got int | string"},
+		{`let x: int? = if (true) { 1 }; x?.y`, "1:33: field access not supported: int"},
+		{`let x: int? = if (true) { 1 }; (x ?? 0) + "a"`, "1:41: type mismatch: int + string"},
+		{`const x = 1; let x = 2;`, "1:18: cannot redeclare constant x"},
+		{`const [a, b] = [1, 2]; const b = 3;`, "1:30: cannot redeclare constant b"},
+		{`const f = 1; fn f() {}`, "1:17: cannot redeclare constant f"},
+		{`const P = 1; struct P { x }`, "1:21: cannot redeclare constant P"},
+		{`const s: string = freeze(1);`, "1:25: s must be string, got int"},
+		{`let xs = [1]; xs[0] = "a";`, "1:23: element of [int] must be int, got string"},
+		{`let xs: [int] = []; xs["a"] = 1;`, "1:24: array index must be int, got string"},
+		{`let h = {"a": 1}; h["b"] = true;`, "1:28: value of {string: int} must be int, got bool"},
+		{`let h = {"a": 1}; h[1] = 1;`, "1:21: hash key must be string, got int"},
+		{`let s = "a"; s[0] = "b";`, "1:15: index assignment not supported: string"},
+		{`yield 1;`, "1:1: yield outside of a generator"},
+		{`fn gen() -> int { yield 1; yield "two"; }`, "1:34: gen: yielded value must be int, got string"},
+		{`fn gen() -> Foo { yield 1; }`, "1:13: unknown type Foo"},
+		{`fn gen() -> int { yield 1; } let n: int = gen();`, ""},
+		{`take(count_from(), "3")`, "1:20: take: argument `n` must be int, got string"},
+		{`let xs: [int] = collect([1]); let s: string = is_done(1);`, "1:54: s must be string, got bool"},
+		{`let s = "a"; let n: int = s[0];`, "1:28: n must be int, got string"},
+		{`let s = "a"; s["b"]`, "1:16: string index must be int, got string"},
+		{`let xs = [1]; let s: string = xs[1:];`, "1:33: s must be string, got [int]"},
+		{`let xs = [1]; xs["a":]`, "1:18: slice bound must be int, got string"},
+		{`let xs = [1]; xs[::true]`, "1:20: slice bound must be int, got bool"},
+		{`let h = {"a": 1}; h[0:1]`, "1:20: slice operator not supported: {string: int}"},
+		{`let xs: [int]? = if (true) { [1] }; xs[1:]`, "1:39: slice operator not supported: [int]?"},
+	}
+
+	for _, tt := range tests {
+		errs := check(t, tt.input)
+		if tt.expected == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: expected no errors, got %v", tt.input, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 {
+			t.Errorf("%s: expected error %q, got none", tt.input, tt.expected)
+			continue
+		}
+		if got := errs[0].Error(); got != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestQuotedCodeIsNotChecked(t *testing.T) {
+	if errs := check(t, `quote(1 + "a"); quote(undefined)`); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestErrorsInSourceOrder(t *testing.T) {
+	errs := check(t, `let a: int = "a";
+let b: string = 1;
+c`)
+	expected := []string{
+		"1:14: a must be int, got string",
+		"2:17: b must be string, got int",
+		"3:1: identifier not found: c",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, want := range expected {
+		if got := errs[i].Error(); got != want {
+			t.Errorf("error %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package checker
+
+import (
+	"strings"
+)
+
+// Type is the static type of an expression.
+type Type interface {
+	String() string
+}
+
+// Basic is one of the builtin scalar types, or any.
+type Basic string
+
+const (
+	Int    Basic = "int"
+	Float  Basic = "float"
+	String Basic = "string"
+	Bool   Basic = "bool"
+	Null   Basic = "null"
+	// Any is the type of values the checker knows nothing about. It is
+	// assignable to and from every other type.
+	Any Basic = "any"
+	// never is the type of statements that do not complete normally, such
+	// as return statements. It is assignable to every type.
+	never Basic = "never"
+)
+
+func (b Basic) String() string { return string(b) }
+
+// Array is the type of arrays whose elements have type Element.
+type Array struct {
+	Element Type
+}
+
+func (a *Array) String() string { return "[" + a.Element.String() + "]" }
+
+// Hash is the type of hashes with keys of type Key and values of type Value.
+type Hash struct {
+	Key   Type
+	Value Type
+}
+
+func (h *Hash) String() string { return "{" + h.Key.String() + ": " + h.Value.String() + "}" }
+
+// Param is a parameter of a function type. Name is empty for parameters of
+// function types written as annotations.
+type Param struct {
+	Name string
+	Type Type
+}
+
+// Func is the type of functions. The first Required of Params must be
+// passed; the rest have defaults. Rest is the type of each extra argument
+// if the function has a rest parameter, and nil otherwise.
+type Func struct {
+	Params   []Param
+	Required int
+	Rest     Type
+	Return   Type
+}
+
+func (f *Func) String() string {
+	params := make([]string, 0, len(f.Params)+1)
+	for _, param := range f.Params {
+		params = append(params, param.Type.String())
+	}
+	if f.Rest != nil {
+		params = append(params, "...["+f.Rest.String()+"]")
+	}
+	return "fn(" + strings.Join(params, ", ") + ") -> " + f.Return.String()
+}
+
+// accepts reports whether f can be called with n positional arguments.
+func (f *Func) accepts(n int) bool {
+	return n >= f.Required && (f.Rest != nil || n <= len(f.Params))
+}
+
+// Union is the type of values that have one of Types, which are never
+// unions themselves. Build unions with union rather than directly.
+type Union struct {
+	Types []Type
+}
+
+// String writes a union with null as T? where it can.
+func (u *Union) String() string {
+	types := make([]string, 0, len(u.Types))
+	nullable := false
+	for _, t := range u.Types {
+		if t == Null {
+			nullable = true
+			continue
+		}
+		if _, ok := t.(*Func); ok {
+			types = append(types, "("+t.String()+")")
+			continue
+		}
+		types = append(types, t.String())
+	}
+	if !nullable {
+		return strings.Join(types, " | ")
+	}
+	if len(types) == 1 {
+		return types[0] + "?"
+	}
+	return "(" + strings.Join(types, " | ") + ")?"
+}
+
+// Named is a struct or class type. Fields lists the fields of a struct;
+// classes have Methods instead, and the fields their methods assign are not
+// tracked.
+type Named struct {
+	Name    string
+	Class   bool
+	Super   *Named
+	Fields  []string
+	Methods map[string]*Func
+}
+
+func (n *Named) String() string { return n.Name }
+
+// extends reports whether n is other or, for classes, inherits from it.
+func (n *Named) extends(other *Named) bool {
+	for c := n; c != nil; c = c.Super {
+		if c == other {
+			return true
+		}
+	}
+	return false
+}
+
+// method looks name up in n and its superclasses.
+func (n *Named) method(name string) (*Func, bool) {
+	for c := n; c != nil; c = c.Super {
+		if m, ok := c.Methods[name]; ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// union returns the type of values having any of types. It flattens nested
+// unions, drops duplicates and never, and collapses to any if any of types
+// is any.
+func union(types ...Type) Type {
+	var flat []Type
+	seen := map[string]bool{}
+	var add func(t Type) bool
+	add = func(t Type) bool {
+		switch t := t.(type) {
+		case *Union:
+			for _, member := range t.Types {
+				if !add(member) {
+					return false
+				}
+			}
+			return true
+		case Basic:
+			if t == Any {
+				return false
+			}
+			if t == never {
+				return true
+			}
+		}
+		if key := t.String(); !seen[key] {
+			seen[key] = true
+			flat = append(flat, t)
+		}
+		return true
+	}
+	for _, t := range types {
+		if !add(t) {
+			return Any
+		}
+	}
+
+	switch len(flat) {
+	case 0:
+		return never
+	case 1:
+		return flat[0]
+	default:
+		return &Union{Types: flat}
+	}
+}
+
+// element returns the element type of an array or hash whose elements have
+// the given types. Collections that mix types, such as ["Alice", 30], are
+// usually records read by position or key, where each use expects the type
+// of one particular element; a union element type would reject all of
+// those uses, so the elements have type any instead. Annotations are still
+// checked against the union; see checker.annotated.
+func element(types ...Type) Type {
+	t := union(types...)
+	if _, ok := t.(*Union); ok {
+		return Any
+	}
+	return t
+}
+
+// members returns the types a value of type t may have.
+func members(t Type) []Type {
+	if u, ok := t.(*Union); ok {
+		return u.Types
+	}
+	return []Type{t}
+}
+
+// assignable reports whether a value of type from may be used where a value
+// of type to is expected. Any is assignable both ways, so unannotated code
+// is never rejected for lack of information.
+func assignable(from, to Type) bool {
+	if from == never || from == Any || to == Any {
+		return true
+	}
+	if u, ok := from.(*Union); ok {
+		for _, member := range u.Types {
+			if !assignable(member, to) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch to := to.(type) {
+	case *Union:
+		for _, member := range to.Types {
+			if assignable(from, member) {
+				return true
+			}
+		}
+		return false
+
+	case *Array:
+		from, ok := from.(*Array)
+		return ok && assignable(from.Element, to.Element)
+
+	case *Hash:
+		from, ok := from.(*Hash)
+		return ok && assignable(from.Key, to.Key) && assignable(from.Value, to.Value)
+
+	case *Func:
+		from, ok := from.(*Func)
+		if !ok || !from.accepts(len(to.Params)) {
+			return false
+		}
+		for i, param := range to.Params {
+			if !assignable(param.Type, from.paramType(i)) {
+				return false
+			}
+		}
+		if to.Rest != nil && (from.Rest == nil || !assignable(to.Rest, from.Rest)) {
+			return false
+		}
+		return assignable(from.Return, to.Return)
+
+	case *Named:
+		from, ok := from.(*Named)
+		return ok && from.extends(to)
+
+	default:
+		return from == to
+	}
+}
+
+// paramType returns the type of the i-th positional argument of f.
+func (f *Func) paramType(i int) Type {
+	if i < len(f.Params) {
+		return f.Params[i].Type
+	}
+	if f.Rest != nil {
+		return f.Rest
+	}
+	return Any
+}
+
+// withoutNull returns the type of the values of type t that are not null.
+func withoutNull(t Type) Type {
+	var types []Type
+	for _, member := range members(t) {
+		if member != Null {
+			types = append(types, member)
+		}
+	}
+	return union(types...)
+}
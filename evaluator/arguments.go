@@ -0,0 +1,125 @@
+package evaluator
+
+import (
+	"slices"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// evalCallArguments evaluates the arguments of a call from left to right.
+// Spread arguments are expanded into the positional arguments, and keyword
+// arguments are returned separately in the order they were written.
+func evalCallArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, []object.Keyword, object.Object) {
+	var args []object.Object
+	var keywords []object.Keyword
+
+	for _, exp := range exps {
+		switch exp := exp.(type) {
+		case *ast.KeywordArgument:
+			val := Eval(exp.Value, env)
+			if isError(val) {
+				return nil, nil, val
+			}
+			keywords = append(keywords, object.Keyword{Name: exp.Name.Value, Value: val})
+
+		case *ast.SpreadExpression:
+			val := Eval(exp.Value, env)
+			if isError(val) {
+				return nil, nil, val
+			}
+			arr, ok := val.(*object.Array)
+			if !ok {
+				return nil, nil, object.NewError("spread argument must be ARRAY, got %s", val.Type())
+			}
+			args = append(args, arr.Elements...)
+
+		default:
+			val := Eval(exp, env)
+			if isError(val) {
+				return nil, nil, val
+			}
+			args = append(args, val)
+		}
+	}
+
+	return args, keywords, nil
+}
+
+// extendFunctionEnv binds the arguments of a call to fn's parameters in a
+// new environment enclosed by fn's. Parameters left without a value take
+// their default, evaluated in the new environment so that it can refer to
+// the parameters before it, and the rest parameter collects any extra
+// positional arguments. A parameter with a pattern binds the names in the
+// pattern instead of its own. In strict mode, arguments are checked against
+// the parameters' types.
+func extendFunctionEnv(fn *object.Function, args []object.Object, keywords []object.Keyword) (*object.Environment, object.Object) {
+	min, max := fn.Arity()
+	if got := len(args); (max >= 0 && got > max) || (got < min && len(keywords) == 0) {
+		return nil, argumentError(fn, "invalid argument length; expected %s arguments, got %d", object.DescribeArity(min, max), got)
+	}
+
+	slots := make([]object.Object, len(fn.Parameters))
+	n := copy(slots, args)
+	for _, kw := range keywords {
+		i := slices.IndexFunc(fn.Parameters, func(param *ast.Identifier) bool {
+			return param.Value == kw.Name
+		})
+		if i < 0 {
+			return nil, argumentError(fn, "unexpected keyword argument `%s`", kw.Name)
+		}
+		if slots[i] != nil {
+			return nil, argumentError(fn, "got multiple values for argument `%s`", kw.Name)
+		}
+		slots[i] = kw.Value
+	}
+
+	env := object.NewEnclosedEnvironment(fn.Env)
+	for i, param := range fn.Parameters {
+		val := slots[i]
+		if val == nil {
+			if i >= len(fn.Defaults) || fn.Defaults[i] == nil {
+				return nil, argumentError(fn, "missing argument `%s`", param.Value)
+			}
+			val = Eval(fn.Defaults[i], env)
+			if isError(val) {
+				return nil, val
+			}
+		}
+		if i < len(fn.ParamTypes) {
+			if errObj := checkType("argument `"+param.Value+"`", val, fn.ParamTypes[i], env); errObj != nil {
+				return nil, argumentError(fn, "%s", errObj.Message)
+			}
+		}
+		if i < len(fn.Patterns) && fn.Patterns[i] != nil {
+			m, errObj := bindPattern(fn.Patterns[i], val, env, false)
+			if errObj != nil {
+				return nil, errObj
+			}
+			if m != nil {
+				return nil, argumentError(fn, "cannot destructure argument %s: %s", param.Value, m)
+			}
+			continue
+		}
+		env.Set(param.Value, val)
+	}
+	if fn.Rest != nil {
+		rest := &object.Array{Elements: slices.Clone(args[n:])}
+		if errObj := checkType("argument `"+fn.Rest.Value+"`", rest, fn.RestType, env); errObj != nil {
+			return nil, argumentError(fn, "%s", errObj.Message)
+		}
+		env.Set(fn.Rest.Value, rest)
+	}
+
+	return env, nil
+}
+
+// argumentError reports a call that does not fit fn's parameters, naming fn
+// if it has a name.
+func argumentError(fn *object.Function, format string, args ...interface{}) *object.Error {
+	errObj := object.NewError(format, args...)
+	if fn.Name != "" {
+		errObj.Message = fn.Name + ": " + errObj.Message
+	}
+	return errObj
+}
@@ -0,0 +1,73 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestCallArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(a, b = 10) { a + b }; f(1)", "11"},
+		{"let f = fn(a, b = 10) { a + b }; f(1, 2)", "3"},
+		{"let f = fn(a, b = a * 2) { b }; f(4)", "8"},
+		{"let f = fn(first, ...others) { others }; f(1, 2, 3)", "[2, 3]"},
+		{"let f = fn(first, ...others) { others }; f(1)", "[]"},
+		{"let f = fn(a, b, c) { [a, b, c] }; f(...[1, 2, 3])", "[1, 2, 3]"},
+		{"let f = fn(a, b, c) { [a, b, c] }; f(1, ...[2], ...[3])", "[1, 2, 3]"},
+		{"let f = fn(a, b) { a - b }; f(b: 2, a: 1)", "-1"},
+		{"let f = fn(a, b = 2, c = 3) { [a, b, c] }; f(1, c: 30)", "[1, 2, 30]"},
+		{"let f = fn(a, ...rest) { [a, rest] }; f(...[1, 2], 3)", "[1, [2, 3]]"},
+		{"let f = fn(a, b = 1) { if (a == 0) { b } else { f(a - 1, b: b * 2) } }; f(3)", "8"},
+		{"json_stringify([1], indent: 2)", "[\n  1\n]"},
+		{"assert_eq(want: 1, got: 1)", "null"},
+		{"len(...[[1, 2]])", "2"},
+		{"fn(a = 1, ...r) { 0 }", "<fn/1+>"},
+		{"json_stringify", "builtin function(value, indent?)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestCallArgumentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b) { a }(1)", "invalid argument length; expected 2 arguments, got 1"},
+		{"fn(a, b = 1) { a }(1, 2, 3)", "invalid argument length; expected 1 or 2 arguments, got 3"},
+		{"fn(a, b = 1, c = 2) { a }()", "invalid argument length; expected 1 to 3 arguments, got 0"},
+		{"fn(a, ...b) { a }()", "invalid argument length; expected at least 1 arguments, got 0"},
+		{"fn(a, b) { a }(1, c: 2)", "unexpected keyword argument `c`"},
+		{"fn(a, ...b) { a }(1, b: 2)", "unexpected keyword argument `b`"},
+		{"fn(a, b) { a }(1, a: 2)", "got multiple values for argument `a`"},
+		{"fn(a, b) { a }(b: 2)", "missing argument `a`"},
+		{"fn(a, b = c) { a }(1)", "identifier not found: c"},
+		{"fn(a) { a }(...1)", "spread argument must be ARRAY, got INTEGER"},
+		{"len(1, 2)", "wrong number of arguments. got=2, want=1"},
+		{"spawn()", "wrong number of arguments. got=0, want at least 1"},
+		{"json_stringify(1, depth: 2)", "unexpected keyword argument `depth`"},
+		{"json_stringify(1, value: 2)", "got multiple values for argument `value`"},
+		{"assert_eq(1, message: 2)", "missing argument `want`"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ekediala/jian/object"
+)
+
+func assert(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 && got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=1 or 2", got)
+	}
+
+	if isTruthy(args[0]) {
+		return NULL
+	}
+
+	if len(args) == 2 {
+		return object.NewError("assertion failed: %s", args[1].Inspect())
+	}
+	return object.NewError("assertion failed")
+}
+
+func assertEq(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 && got != 3 {
+		return object.NewError("wrong number of arguments. got=%d, want=2 or 3", got)
+	}
+
+	got, want := args[0], args[1]
+	if object.Equal(got, want) {
+		return NULL
+	}
+
+	var out strings.Builder
+	out.WriteString("assert_eq failed")
+	if len(args) == 3 {
+		out.WriteString(": ")
+		out.WriteString(args[2].Inspect())
+	}
+	fmt.Fprintf(&out, "\n    got:  %s\n    want: %s", repr(got), repr(want))
+	for _, line := range diffValues("", got, want, nil) {
+		out.WriteString("\n    ")
+		out.WriteString(line)
+	}
+
+	return object.NewError("%s", out.String())
+}
+
+// assertError calls a function that takes no arguments and passes if the
+// call fails. The function is checked first, so that a value that cannot
+// be called that way is not mistaken for a call that failed.
+func assertError(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 && got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=1 or 2", got)
+	}
+
+	required := 0
+	switch fn := args[0].(type) {
+	case *object.Function:
+		required, _ = fn.Arity()
+	case *object.Builtin:
+		if fn.Signature != nil {
+			required = fn.Signature.Required
+		}
+	default:
+		return object.NewError("first argument to `assert_error` must be FUNCTION, got %s", args[0].Type())
+	}
+	if required > 0 {
+		return object.NewError("first argument to `assert_error` must take no arguments, got %s", args[0].Inspect())
+	}
+
+	result := applyFunction(args[0], []object.Object{}, env)
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		return object.NewError("assert_error failed: expected an error, got %s", repr(result))
+	}
+
+	if len(args) == 2 {
+		want, ok := args[1].(*object.String)
+		if !ok {
+			return object.NewError("second argument to `assert_error` must be STRING, got %s", args[1].Type())
+		}
+		if !strings.Contains(errObj.Message, want.Value) {
+			return object.NewError("assert_error failed: expected error containing %q, got %q",
+				want.Value, errObj.Message)
+		}
+	}
+
+	return NULL
+}
+
+// diffValues describes where got and want diverge, one line per difference.
+// comparing holds the pairs of containers already being walked further up
+// the path; meeting one again means both sides loop back the same way, so
+// there is nothing new to report below it.
+func diffValues(path string, got, want object.Object, comparing map[[2]object.Object]bool) []string {
+	if got.Type() != want.Type() {
+		return []string{fmt.Sprintf("%stype mismatch: got %s, want %s", at(path), got.Type(), want.Type())}
+	}
+
+	switch got.(type) {
+	case *object.Array, *object.Hash:
+		pair := [2]object.Object{got, want}
+		if comparing[pair] {
+			return nil
+		}
+		if comparing == nil {
+			comparing = map[[2]object.Object]bool{}
+		}
+		comparing[pair] = true
+		defer delete(comparing, pair)
+	}
+
+	switch got := got.(type) {
+	case *object.Array:
+		want := want.(*object.Array)
+		var lines []string
+		for i := 0; i < len(got.Elements) && i < len(want.Elements); i++ {
+			lines = append(lines, diffValues(fmt.Sprintf("%s[%d]", path, i), got.Elements[i], want.Elements[i], comparing)...)
+		}
+		if len(got.Elements) != len(want.Elements) {
+			lines = append(lines, fmt.Sprintf("%slength mismatch: got %d, want %d", at(path), len(got.Elements), len(want.Elements)))
+		}
+		return lines
+	case *object.Hash:
+		want := want.(*object.Hash)
+		var lines []string
+		for _, pair := range got.OrderedPairs() {
+			keyPath := fmt.Sprintf("%s[%s]", path, repr(pair.Key))
+			if other, ok := want.Get(pair.Key.(object.Hashable)); ok {
+				lines = append(lines, diffValues(keyPath, pair.Value, other, comparing)...)
+			} else {
+				lines = append(lines, fmt.Sprintf("%s: unexpected key", keyPath))
+			}
+		}
+		for _, pair := range want.OrderedPairs() {
+			if _, ok := got.Get(pair.Key.(object.Hashable)); !ok {
+				lines = append(lines, fmt.Sprintf("%s[%s]: missing key", path, repr(pair.Key)))
+			}
+		}
+		return lines
+	default:
+		if object.Equal(got, want) {
+			return nil
+		}
+		if path == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: got %s, want %s", path, repr(got), repr(want))}
+	}
+}
+
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
+
+// repr renders a value the way it would be written in source, so that the
+// string "1" and the integer 1 are distinguishable in failure messages.
+// Like Inspect, it shows a value met again inside itself as [...] or {...}.
+func repr(obj object.Object) string {
+	return reprValue(obj, map[object.Object]bool{})
+}
+
+// reprValue is repr for a value inside the containers in visiting.
+func reprValue(obj object.Object, visiting map[object.Object]bool) string {
+	switch obj := obj.(type) {
+	case nil:
+		return "nothing"
+	case *object.String:
+		return strconv.Quote(obj.Value)
+	case *object.Array:
+		if visiting[obj] {
+			return "[...]"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := make([]string, 0, len(obj.Elements))
+		for _, el := range obj.Elements {
+			elements = append(elements, reprValue(el, visiting))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *object.Hash:
+		if visiting[obj] {
+			return "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := make([]string, 0, obj.Len())
+		for _, pair := range obj.OrderedPairs() {
+			pairs = append(pairs, reprValue(pair.Key, visiting)+": "+reprValue(pair.Value, visiting))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case *object.Struct:
+		return reprStruct(obj, visiting)
+	case *object.Instance:
+		return reprInstance(obj, visiting)
+	default:
+		return obj.Inspect()
+	}
+}
@@ -1,28 +1,197 @@
 package evaluator
 
 import (
-	"fmt"
+	"io"
+	"slices"
+	"strings"
 
 	"github.com/ekediala/jian/object"
 )
 
 var builtins = map[string]*object.Builtin{
-	"len":   {Fn: length},
-	"first": {Fn: first},
-	"last":  {Fn: last},
-	"rest":  {Fn: rest},
-	"push":  {Fn: push},
-	"puts":  {Fn: puts},
+	"len":   {Fn: length, Signature: object.NewSignature("arg")},
+	"first": {Fn: first, Signature: object.NewSignature("array")},
+	"last":  {Fn: last, Signature: object.NewSignature("array")},
+	"rest":  {Fn: rest, Signature: object.NewSignature("array")},
+	"push":  {Fn: push, Signature: object.NewSignature("array", "value")},
+
+	"freeze": {Fn: freeze, Signature: object.NewSignature("value")},
+
+	"type_of":    {Fn: typeOf, Signature: object.NewSignature("value")},
+	"instanceof": {Fn: instanceOf, Signature: object.NewSignature("value", "type")},
+
+	"contains": {Fn: contains, Signature: object.NewSignature("collection", "value")},
+	"index_of": {Fn: indexOf, Signature: object.NewSignature("collection", "value")},
+	"puts":     {Fn: puts, Signature: object.NewSignature("...values")},
+
+	"print":     {Fn: printValues, Signature: object.NewSignature("...values")},
+	"eprint":    {Fn: eprintValues, Signature: object.NewSignature("...values")},
+	"input":     {Fn: input, Signature: object.NewSignature("prompt?")},
+	"read_line": {Fn: readLine, Signature: object.NewSignature()},
+
+	"read_file":   {Fn: readFile, Signature: object.NewSignature("path")},
+	"write_file":  {Fn: writeFile, Signature: object.NewSignature("path", "content")},
+	"append_file": {Fn: appendFile, Signature: object.NewSignature("path", "content")},
+	"list_dir":    {Fn: listDir, Signature: object.NewSignature("path?")},
+	"exists":      {Fn: exists, Signature: object.NewSignature("path")},
+	"remove":      {Fn: remove, Signature: object.NewSignature("path")},
+	"path_join":   {Fn: pathJoin, Signature: object.NewSignature("...parts")},
+	"path_base":   {Fn: pathBase, Signature: object.NewSignature("path")},
+	"path_dir":    {Fn: pathDir, Signature: object.NewSignature("path")},
+	"path_ext":    {Fn: pathExt, Signature: object.NewSignature("path")},
+
+	"json_parse":     {Fn: jsonParse, Signature: object.NewSignature("string")},
+	"json_stringify": {Fn: jsonStringify, Signature: object.NewSignature("value", "indent?")},
+
+	"assert":    {Fn: assert, Signature: object.NewSignature("condition", "message?")},
+	"assert_eq": {Fn: assertEq, Signature: object.NewSignature("got", "want", "message?")},
+
+	"join":    {Fn: join, Signature: object.NewSignature("task")},
+	"channel": {Fn: channel, Signature: object.NewSignature("capacity?")},
+	"send":    {Fn: send, Signature: object.NewSignature("channel", "value")},
+	"recv":    {Fn: recv, Signature: object.NewSignature("channel")},
+	"close":   {Fn: closeChannel, Signature: object.NewSignature("channel")},
+
+	"next":       {Fn: next, Signature: object.NewSignature("generator")},
+	"is_done":    {Fn: isDone, Signature: object.NewSignature("value")},
+	"collect":    {Fn: collect, Signature: object.NewSignature("generator")},
+	"take":       {Fn: take, Signature: object.NewSignature("generator", "n")},
+	"count_from": {Fn: countFrom, Signature: object.NewSignature("start?", "step?")},
 }
 
-func puts(args ...object.Object) object.Object {
+func init() {
+	// These builtins call back into the evaluator, which itself looks up
+	// builtins, so they have to be registered after package initialisation.
+	builtins["assert_error"] = &object.Builtin{Fn: assertError, Signature: object.NewSignature("fn", "substring?")}
+	builtins["spawn"] = &object.Builtin{Fn: spawn, Signature: object.NewSignature("fn", "...args")}
+	builtins["map_iter"] = &object.Builtin{Fn: mapIter, Signature: object.NewSignature("generator", "fn")}
+	builtins["filter_iter"] = &object.Builtin{Fn: filterIter, Signature: object.NewSignature("generator", "fn")}
+}
+
+// LookupBuiltin returns the builtin function called name, if there is one.
+func LookupBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+func puts(env *object.Environment, args ...object.Object) object.Object {
+	streams := env.IO()
 	for _, arg := range args {
-		fmt.Println(arg.Inspect())
+		if err := streams.Print(arg.Inspect() + "\n"); err != nil {
+			return object.NewError("puts: %s", err)
+		}
+	}
+	return NULL
+}
+
+func printValues(env *object.Environment, args ...object.Object) object.Object {
+	if err := env.IO().Print(joinInspected(args)); err != nil {
+		return object.NewError("print: %s", err)
 	}
 	return NULL
 }
 
-func push(args ...object.Object) object.Object {
+func eprintValues(env *object.Environment, args ...object.Object) object.Object {
+	if err := env.IO().Eprint(joinInspected(args)); err != nil {
+		return object.NewError("eprint: %s", err)
+	}
+	return NULL
+}
+
+func joinInspected(args []object.Object) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, arg.Inspect())
+	}
+	return strings.Join(parts, " ")
+}
+
+func input(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got > 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=0 or 1", got)
+	}
+
+	if len(args) == 1 {
+		if err := env.IO().Print(args[0].Inspect()); err != nil {
+			return object.NewError("input: %s", err)
+		}
+	}
+
+	return readLine(env)
+}
+
+func readLine(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 0 {
+		return object.NewError("wrong number of arguments. got=%d, want=0", got)
+	}
+
+	line, err := env.IO().ReadLine()
+	if err == io.EOF {
+		return NULL
+	}
+	if err != nil {
+		return object.NewError("read_line: %s", err)
+	}
+
+	return &object.String{Value: line}
+}
+
+func contains(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	switch collection := args[0].(type) {
+	case *object.Array:
+		for _, el := range collection.Elements {
+			if object.Equal(el, args[1]) {
+				return TRUE
+			}
+		}
+		return FALSE
+	case *object.Hash:
+		key, ok := asHashKey(args[1])
+		if !ok {
+			return FALSE
+		}
+		_, found := collection.Get(key)
+		return nativeBoolToBooleanObject(found)
+	case *object.String:
+		sub, ok := args[1].(*object.String)
+		if !ok {
+			return object.NewError("cannot search STRING for %s", args[1].Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(collection.Value, sub.Value))
+	default:
+		return object.NewError("argument to `contains` not supported, got %s", args[0].Type())
+	}
+}
+
+func indexOf(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	switch collection := args[0].(type) {
+	case *object.Array:
+		for i, el := range collection.Elements {
+			if object.Equal(el, args[1]) {
+				return &object.Integer{Value: int64(i)}
+			}
+		}
+		return &object.Integer{Value: -1}
+	case *object.String:
+		sub, ok := args[1].(*object.String)
+		if !ok {
+			return object.NewError("cannot search STRING for %s", args[1].Type())
+		}
+		return &object.Integer{Value: int64(strings.Index(collection.Value, sub.Value))}
+	default:
+		return object.NewError("argument to `index_of` not supported, got %s", args[0].Type())
+	}
+}
+
+func push(env *object.Environment, args ...object.Object) object.Object {
 	if got := len(args); got != 2 {
 		return object.NewError("wrong number of arguments. got=%d, want=2", got)
 	}
@@ -37,23 +206,26 @@ func push(args ...object.Object) object.Object {
 	return object.NewError("argument to `push` must be ARRAY, got %s", args[0].Type())
 }
 
-func rest(args ...object.Object) object.Object {
+func rest(env *object.Environment, args ...object.Object) object.Object {
 	if got := len(args); got != 1 {
 		return object.NewError("wrong number of arguments. got=%d, want=1", got)
 	}
 
-	if arg, ok := args[0].(*object.Array); ok {
+	switch arg := args[0].(type) {
+	case *object.Array:
 		if len(arg.Elements) > 0 {
-			return &object.Array{Elements: arg.Elements[1:]}
+			return &object.Array{Elements: slices.Clone(arg.Elements[1:])}
 		}
 
 		return NULL
+	case *object.Generator:
+		return restOfGenerator(arg)
 	}
 
-	return object.NewError("argument to `rest` must be ARRAY, got %s", args[0].Type())
+	return object.NewError("argument to `rest` must be ARRAY or GENERATOR, got %s", args[0].Type())
 }
 
-func last(args ...object.Object) object.Object {
+func last(env *object.Environment, args ...object.Object) object.Object {
 	if got := len(args); got != 1 {
 		return object.NewError("wrong number of arguments. got=%d, want=1", got)
 	}
@@ -69,23 +241,60 @@ func last(args ...object.Object) object.Object {
 	return object.NewError("argument to `last` must be ARRAY, got %s", args[0].Type())
 }
 
-func first(args ...object.Object) object.Object {
+func first(env *object.Environment, args ...object.Object) object.Object {
 	if got := len(args); got != 1 {
 		return object.NewError("wrong number of arguments. got=%d, want=1", got)
 	}
 
-	if arg, ok := args[0].(*object.Array); ok {
+	switch arg := args[0].(type) {
+	case *object.Array:
 		if len(arg.Elements) > 0 {
 			return arg.Elements[0]
 		}
 
 		return NULL
+	case *object.Generator:
+		if value, ok := arg.Next(); ok {
+			return value
+		}
+		return NULL
+	}
+
+	return object.NewError("argument to `first` must be ARRAY or GENERATOR, got %s", args[0].Type())
+}
+
+// typeOf returns the name of the type of a struct or the class of an
+// instance, or the type of any other value, such as "INTEGER".
+func typeOf(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Struct:
+		return &object.String{Value: arg.Def.Name}
+	case *object.Instance:
+		return &object.String{Value: arg.Class.Name}
+	default:
+		return &object.String{Value: string(arg.Type())}
 	}
+}
 
-	return object.NewError("argument to `first` must be ARRAY, got %s", args[0].Type())
+// instanceOf reports whether a value is an instance of a class, including
+// the classes it extends, or of a struct type.
+func instanceOf(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	is, valid := isInstance(args[0], args[1])
+	if !valid {
+		return object.NewError("argument `type` to `instanceof` must be a class or struct type, got %s", args[1].Type())
+	}
+	return nativeBoolToBooleanObject(is)
 }
 
-func length(args ...object.Object) object.Object {
+func length(env *object.Environment, args ...object.Object) object.Object {
 	if got := len(args); got != 1 {
 		return object.NewError("wrong number of arguments. got=%d, want=1", got)
 	}
@@ -95,6 +304,8 @@ func length(args ...object.Object) object.Object {
 		return &object.Integer{Value: int64(len(arg.Value))}
 	case *object.Array:
 		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Generator:
+		return lengthOfGenerator(arg)
 	default:
 		return object.NewError("argument to `len` not supported, got %v", arg.Type())
 	}
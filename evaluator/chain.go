@@ -0,0 +1,64 @@
+package evaluator
+
+import (
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// evalChain evaluates node, which may be a link in a chain of field
+// accesses, indexes and calls such as a?.b[0].c(). It also reports whether
+// an optional link (?. or ?[) in the chain was applied to null, in which
+// case the links after it are skipped and the whole chain is null.
+func evalChain(node ast.Node, env *object.Environment) (object.Object, bool) {
+	switch node := node.(type) {
+	case *ast.FieldExpression:
+		left, skipped := evalLinkOperand(node.Left, node.Optional, env)
+		if skipped || isError(left) {
+			return left, skipped
+		}
+		return evalFieldAccess(left, node.Field.Value), false
+
+	case *ast.IndexExpression:
+		left, skipped := evalLinkOperand(node.Left, node.Optional, env)
+		if skipped || isError(left) {
+			return left, skipped
+		}
+		if node.Slice {
+			return evalSlice(left, node, env), false
+		}
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index, false
+		}
+		return evalIndexOperation(left, index), false
+
+	case *ast.CallExpression:
+		if ident, ok := node.Function.(*ast.Identifier); ok && ident.Value == "quote" {
+			return quote(node.Arguments, env), false
+		}
+		fn, skipped := evalLinkOperand(node.Function, false, env)
+		if skipped || isError(fn) {
+			return fn, skipped
+		}
+		args, keywords, errObj := evalCallArguments(node.Arguments, env)
+		if errObj != nil {
+			return errObj, false
+		}
+		return withFrame(callFunction(fn, args, keywords, env), fn, node), false
+
+	default:
+		return Eval(node, env), false
+	}
+}
+
+// evalLinkOperand evaluates the expression a link of a chain applies to. It
+// reports whether the rest of the chain should be skipped, because an
+// earlier optional link was skipped or because the link is optional and the
+// operand is null.
+func evalLinkOperand(exp ast.Expression, optional bool, env *object.Environment) (object.Object, bool) {
+	operand, skipped := evalChain(exp, env)
+	if skipped || (optional && operand == NULL) {
+		return NULL, true
+	}
+	return operand, false
+}
@@ -0,0 +1,69 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestOptionalChainingAndCoalescing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let config = {"db": {"host": "localhost"}}; config["db"]?["host"]`, "localhost"},
+		{`let config = {"db": {"host": "localhost"}}; config["cache"]?["host"]`, "null"},
+		{`let config = {}; config["a"]?["b"]?["c"]`, "null"},
+		{`let config = {}; config["a"]?["b"]["c"][0]`, "null"},
+		{`let xs = [[1, 2]]; xs[5]?[0]`, "null"},
+		{`let xs = [[1, 2]]; xs[0]?[1]`, "2"},
+		{"struct P { next } let p = P(P(1)); p?.next?.next", "1"},
+		{"struct P { next } let p = P(if (false) { 1 }); p.next?.next.next", "null"},
+		{`let h = {}; h["f"]?.g(1 / 0)`, "null"},
+		{"class C { fn get() { 7 } } let c = C(); c?.get()", "7"},
+		{"let f = fn(h) { h?.get() }; f(if (false) { 1 })", "null"},
+		{`let h = {"a": 1}; h["b"] ?? 2`, "2"},
+		{`let h = {"a": 1}; h["a"] ?? 2`, "1"},
+		{`false ?? true`, "false"},
+		{`0 ?? 1`, "0"},
+		{`let h = {}; h["a"] ?? h["b"] ?? "default"`, "default"},
+		{`1 ?? undefined_name`, "1"},
+		{`let h = {}; h["port"] ?? 80 + 1`, "81"},
+		{`let config = {"db": {}}; config["db"]?["port"] ?? 5432`, "5432"},
+		{`fn port(c) { c?["db"]?["port"] ?? 5432 } port(if (false) { 1 })`, "5432"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestOptionalChainingErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let h = {}; h["a"]["b"]`, "index operator not supported: NULL"},
+		{`let h = {"a": {}}; h?["a"]["b"]["c"]`, "index operator not supported: NULL"},
+		{`let h = {}; h["a"].b`, "field access not supported: NULL"},
+		{"1?.x", "field access not supported: INTEGER"},
+		{`1?[0]`, "index operator not supported: INTEGER"},
+		{`let h = {}; h?[fn() {}]`, "unusable as hash key: FUNCTION"},
+		{`let h = {}; h["a"] ?? missing`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
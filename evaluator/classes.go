@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// evalClassStatement creates a class from its declaration. The methods
+// close over env, and are named after the class, as in Dog.speak.
+func evalClassStatement(stmt *ast.ClassStatement, env *object.Environment) object.Object {
+	if errObj := checkRedeclaration(stmt.Name.Value, env); errObj != nil {
+		return errObj
+	}
+	class := &object.Class{Name: stmt.Name.Value, Methods: map[string]*object.Function{}}
+
+	if stmt.Super != nil {
+		super := Eval(stmt.Super, env)
+		if isError(super) {
+			return super
+		}
+		parent, ok := super.(*object.Class)
+		if !ok {
+			return object.NewError("class %s cannot extend %s, which is not a class", class.Name, super.Type())
+		}
+		class.Super = parent
+	}
+
+	for _, decl := range stmt.Methods {
+		method := Eval(decl.Function, env).(*object.Function)
+		method.Name = class.Name + "." + decl.Name.Value
+		class.Methods[decl.Name.Value] = method
+	}
+
+	env.Set(class.Name, class)
+	return nil
+}
+
+// bindMethod returns method with self bound to the instance and, if the
+// class defining the method has a parent, super bound to the parent's
+// methods.
+func bindMethod(method *object.Function, defining *object.Class, self *object.Instance) *object.Function {
+	env := object.NewEnclosedEnvironment(method.Env)
+	env.Set("self", self)
+	if defining.Super != nil {
+		env.Set("super", &object.Super{Self: self, Class: defining.Super})
+	}
+
+	bound := *method
+	bound.Env = env
+	return &bound
+}
+
+// instantiate creates an instance of class and runs its init method, if any,
+// with the arguments of the call.
+func instantiate(class *object.Class, args []object.Object, keywords []object.Keyword, env *object.Environment) object.Object {
+	instance := object.NewInstance(class)
+
+	init, defining := class.Method("init")
+	if init == nil {
+		if got := len(args) + len(keywords); got > 0 {
+			return object.NewError("%s: invalid argument length; expected 0 arguments, got %d", class.Name, got)
+		}
+		return instance
+	}
+
+	if result := callFunction(bindMethod(init, defining, instance), args, keywords, env); isError(result) {
+		return result
+	}
+	return instance
+}
+
+// getInstanceField returns a field of instance or, failing that, one of its
+// methods bound to it.
+func getInstanceField(instance *object.Instance, name string) object.Object {
+	if value, ok := instance.Get(name); ok {
+		return value
+	}
+	if method, defining := instance.Class.Method(name); method != nil {
+		return bindMethod(method, defining, instance)
+	}
+	return object.NewError("%s has no field or method %s", instance.Class.Name, name)
+}
+
+// getSuperMethod returns the method of the parent class that super refers
+// to, bound to the same instance.
+func getSuperMethod(super *object.Super, name string) object.Object {
+	if method, defining := super.Class.Method(name); method != nil {
+		return bindMethod(method, defining, super.Self)
+	}
+	return object.NewError("%s has no method %s", super.Class.Name, name)
+}
+
+// isInstance reports whether value is an instance of typ. Instances of a
+// class are also instances of the classes it extends. valid is false if typ
+// is neither a class nor a struct type.
+func isInstance(value, typ object.Object) (is, valid bool) {
+	switch typ := typ.(type) {
+	case *object.Class:
+		instance, ok := value.(*object.Instance)
+		return ok && instance.Class.Extends(typ), true
+	case *object.StructType:
+		s, ok := value.(*object.Struct)
+		return ok && s.Def == typ, true
+	default:
+		return false, false
+	}
+}
+
+func evalIsExpression(left, right object.Object) object.Object {
+	is, valid := isInstance(left, right)
+	if !valid {
+		return object.NewError("right side of `is` must be a class or struct type, got %s", right.Type())
+	}
+	return nativeBoolToBooleanObject(is)
+}
+
+// reprInstance formats instance like its Inspect, but with its field values
+// shown by repr.
+func reprInstance(instance *object.Instance, visiting map[object.Object]bool) string {
+	if visiting[instance] {
+		return instance.Class.Name + "{...}"
+	}
+	visiting[instance] = true
+	defer delete(visiting, instance)
+
+	fields := instance.Fields()
+	for i, field := range fields {
+		value, _ := instance.Get(field)
+		fields[i] = field + ": " + reprValue(value, visiting)
+	}
+	return instance.Class.Name + "{" + strings.Join(fields, ", ") + "}"
+}
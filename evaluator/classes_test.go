@@ -0,0 +1,132 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+const animals = `
+class Animal {
+  fn init(name) { self.name = name; }
+  fn speak() { self.name + " makes a sound" }
+  fn describe() { "I am " + self.name + ". " + self.speak() }
+}
+class Dog extends Animal {
+  fn init(name, breed) { super.init(name); self.breed = breed; }
+  fn speak() { super.speak() + " (woof)" }
+}
+class Puppy extends Dog {}
+`
+
+func TestClasses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Animal("cat")`, "Animal{name: cat}"},
+		{`Animal("cat").name`, "cat"},
+		{`Animal("cat").speak()`, "cat makes a sound"},
+		{`Dog("rex", "lab")`, "Dog{name: rex, breed: lab}"},
+		{`Dog("rex", "lab").speak()`, "rex makes a sound (woof)"},
+		{`Dog(breed: "lab", name: "rex").breed`, "lab"},
+		{`Dog("rex", "lab").describe()`, "I am rex. rex makes a sound (woof)"},
+		{`Puppy("bit", "pug").describe()`, "I am bit. bit makes a sound (woof)"},
+		{`let speak = Dog("rex", "lab").speak; speak()`, "rex makes a sound (woof)"},
+		{`Dog("rex", "lab").speak`, "<fn Dog.speak/0>"},
+		{"Dog", "class Dog extends Animal"},
+		{"Animal", "class Animal"},
+		{`let d = Dog("rex", "lab"); d.name = "max"; d.speak()`, "max makes a sound (woof)"},
+		{`let d = Dog("rex", "lab"); d.speak = fn() { "shadowed" }; d.speak()`, "shadowed"},
+		{`let d = Dog("rex", "lab"); [d is Dog, d is Animal, d is Puppy]`, "[true, true, false]"},
+		{`[Animal("cat") is Dog, 1 is Animal]`, "[false, false]"},
+		{`instanceof(Puppy("bit", "pug"), Animal)`, "true"},
+		{`instanceof(value: 1, type: Dog)`, "false"},
+		{`type_of(Puppy("bit", "pug"))`, "Puppy"},
+		{"type_of(Dog)", "CLASS"},
+		{`let d = Dog("rex", "lab"); d == d`, "true"},
+		{`Dog("rex", "lab") == Dog("rex", "lab")`, "false"},
+	}
+
+	for _, tt := range tests {
+		input := animals + tt.input
+		evaluated := testEval(input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestClassFeatures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class Empty {} Empty()", "Empty{}"},
+		{"class Counter { fn init() { self.n = 0 } fn inc() { self.n = self.n + 1; self } } Counter().inc().inc().n", "2"},
+		{"class Counter { fn init(start = 10) { self.n = start } } Counter().n", "10"},
+		{"class Box { fn init(v) { self.v = v } fn get() { self.v } } let make = fn(v) { Box(v) }; make(3).get()", "3"},
+		{"let k = 2; class Scaler { fn scale(n) { n * k } } Scaler().scale(4)", "8"},
+		{"class Loop { fn count(n, acc) { if (n == 0) { acc } else { self.count(n - 1, acc + 1) } } } Loop().count(10000, 0)", "10000"},
+		{"class A { fn who() { \"A\" } fn hello() { \"hello from \" + self.who() } } class B extends A { fn who() { \"B\" } } B().hello()", "hello from B"},
+		{"class A { fn f() { \"A\" } } class B extends A { fn f() { \"B>\" + super.f() } } class C extends B { fn f() { \"C>\" + super.f() } } C().f()", "C>B>A"},
+		{"struct P { x } [P(1) is P, instanceof(1, P)]", "[true, false]"},
+		{"class A {} let f = fn() { class A { fn x() { 1 } } A }; [f()().x(), A]", "[1, class A]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestClassErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class A {} A(1)", "A: invalid argument length; expected 0 arguments, got 1"},
+		{"class A { fn init(x) {} } A()", "A.init: invalid argument length; expected 1 arguments, got 0"},
+		{"class A {} A().x", "A has no field or method x"},
+		{"class A { fn f() { super.f() } } A().f()", "identifier not found: super"},
+		{"class A {} class B extends A { fn f() { super.g() } } B().f()", "A has no method g"},
+		{"let x = 1; class B extends x {}", "class B cannot extend INTEGER, which is not a class"},
+		{"class B extends Missing {}", "identifier not found: Missing"},
+		{"class A {} 1 is 2", "right side of `is` must be a class or struct type, got INTEGER"},
+		{"instanceof(1, 2)", "argument `type` to `instanceof` must be a class or struct type, got INTEGER"},
+		{"class A { fn init() { self.x = 1 + true } } A()", "type mismatch: INTEGER + BOOLEAN"},
+		{"class A {} class B extends A { fn f() { super.x = 1; } } B().f()", "field assignment not supported: SUPER"},
+		{"class A {} A.x", "field access not supported: CLASS"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestClassTraceback(t *testing.T) {
+	input := `class A {
+  fn init() { self.check(); }
+  fn check() { 1 + true }
+}
+A();`
+
+	errObj, ok := testEval(input).(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error")
+	}
+	expected := "type mismatch: INTEGER + BOOLEAN\n    at A.check (2:25)\n    at A.init (5:2)"
+	if got := errObj.Traceback(); got != expected {
+		t.Errorf("expected traceback %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,167 @@
+package evaluator
+
+import (
+	"reflect"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+func spawn(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return object.NewError("wrong number of arguments. got=%d, want at least 1", len(args))
+	}
+
+	switch fn := args[0].(type) {
+	case *object.Function, *object.Builtin:
+		fnArgs := args[1:]
+		return object.NewTask(func() object.Object {
+			return applyFunction(fn, fnArgs, env)
+		})
+	default:
+		return object.NewError("argument to `spawn` must be FUNCTION, got %s", args[0].Type())
+	}
+}
+
+func join(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	task, ok := args[0].(*object.Task)
+	if !ok {
+		return object.NewError("argument to `join` must be TASK, got %s", args[0].Type())
+	}
+
+	if result := task.Wait(); result != nil {
+		return result
+	}
+	return NULL
+}
+
+func channel(env *object.Environment, args ...object.Object) object.Object {
+	switch len(args) {
+	case 0:
+		return object.NewChannel(0)
+	case 1:
+		size, ok := args[0].(*object.Integer)
+		if !ok {
+			return object.NewError("argument to `channel` must be INTEGER, got %s", args[0].Type())
+		}
+		if size.Value < 0 {
+			return object.NewError("channel capacity must not be negative, got %d", size.Value)
+		}
+		return object.NewChannel(int(size.Value))
+	default:
+		return object.NewError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+}
+
+func send(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	ch, ok := args[0].(*object.Channel)
+	if !ok {
+		return object.NewError("first argument to `send` must be CHANNEL, got %s", args[0].Type())
+	}
+
+	if err := ch.Send(args[1]); err != nil {
+		return object.NewError("send: %s", err)
+	}
+	return NULL
+}
+
+func recv(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	ch, ok := args[0].(*object.Channel)
+	if !ok {
+		return object.NewError("argument to `recv` must be CHANNEL, got %s", args[0].Type())
+	}
+
+	if value, ok := ch.Recv(); ok {
+		return value
+	}
+	return NULL
+}
+
+func closeChannel(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	ch, ok := args[0].(*object.Channel)
+	if !ok {
+		return object.NewError("argument to `close` must be CHANNEL, got %s", args[0].Type())
+	}
+
+	if err := ch.Close(); err != nil {
+		return object.NewError("close: %s", err)
+	}
+	return NULL
+}
+
+func evalSelectExpression(se *ast.SelectExpression, env *object.Environment) object.Object {
+	cases := make([]reflect.SelectCase, 0, len(se.Cases)+1)
+
+	for _, c := range se.Cases {
+		args := evalExpressions(c.Operation.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+
+		ch, ok := args[0].(*object.Channel)
+		if !ok {
+			return object.NewError("select case on %s, expected CHANNEL", args[0].Type())
+		}
+
+		sc := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch.Chan())}
+		if c.IsSend() {
+			sc.Dir = reflect.SelectSend
+			sc.Send = reflect.ValueOf(args[1])
+		}
+		cases = append(cases, sc)
+	}
+
+	if se.Default != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+
+	chosen, received, ok, err := selectChannels(cases)
+	if err != nil {
+		return object.NewError("select: %s", err)
+	}
+
+	if chosen == len(se.Cases) {
+		return Eval(se.Default, env)
+	}
+
+	c := se.Cases[chosen]
+	caseEnv := object.NewEnclosedEnvironment(env)
+	if c.Binding != nil {
+		var value object.Object = NULL
+		if ok {
+			value = received.Interface().(object.Object)
+		}
+		caseEnv.Set(c.Binding.Value, value)
+	}
+
+	return Eval(c.Body, caseEnv)
+}
+
+// selectChannels wraps reflect.Select, turning the panic caused by sending on
+// a closed channel into an error.
+func selectChannels(cases []reflect.SelectCase) (chosen int, received reflect.Value, ok bool, err error) {
+	defer func() {
+		if recover() != nil {
+			err = object.ErrClosedChannel
+		}
+	}()
+
+	chosen, received, ok = reflect.Select(cases)
+	return chosen, received, ok, nil
+}
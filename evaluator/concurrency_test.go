@@ -0,0 +1,127 @@
+package evaluator_test
+
+import (
+	"testing"
+)
+
+func TestSpawnAndJoin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"join(spawn(fn() { 42 }))", 42},
+		{"join(spawn(fn(a, b) { a + b }, 1, 2))", 3},
+		{"let t = spawn(fn() { 1 }); join(t); join(t) + 1", 2},
+		{"join(spawn(len, [1, 2, 3]))", 3},
+		{"join(spawn(fn() { 1 + true }))", "type mismatch: INTEGER + BOOLEAN"},
+		{"spawn(1)", "argument to `spawn` must be FUNCTION, got INTEGER"},
+		{"join(1)", "argument to `join` must be TASK, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestChannels(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let c = channel(1); send(c, 5); recv(c)", 5},
+		{"let c = channel(); spawn(send, c, 7); recv(c)", 7},
+		{"let c = channel(2); send(c, 1); send(c, 2); recv(c) * 10 + recv(c)", 12},
+		{"let c = channel(1); send(c, 1); close(c); recv(c)", 1},
+		{"let c = channel(1); close(c); recv(c)", nil},
+		{"let c = channel(1); close(c); send(c, 1)", "send: channel is closed"},
+		{"let c = channel(1); close(c); close(c)", "close: channel is closed"},
+		{"channel(-1)", "channel capacity must not be negative, got -1"},
+		{`channel("1")`, "argument to `channel` must be INTEGER, got STRING"},
+		{"recv(1)", "argument to `recv` must be CHANNEL, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSelectExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let c = channel(); select { case recv(c) { 1 } default { 2 } }", 2},
+		{"let c = channel(1); send(c, 5); select { case let v = recv(c) { v * 2 } }", 10},
+		{"let c = channel(1); select { case send(c, 7) { recv(c) } }", 7},
+		{"let c = channel(1); close(c); select { case let v = recv(c) { v } }", nil},
+		{"let c = channel(1); close(c); select { case send(c, 1) { 1 } }", "select: channel is closed"},
+		{
+			`let a = channel(); let b = channel(1);
+			send(b, "b");
+			select { case let v = recv(a) { v } case let v = recv(b) { v + "!" } }`,
+			"b!",
+		},
+		{
+			`let c = channel();
+			spawn(fn() { send(c, 3) });
+			select { case let v = recv(c) { v } }`,
+			3,
+		},
+		{"select { case recv(1) { 1 } }", "select case on INTEGER, expected CHANNEL"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+// TestConcurrentEvaluation is meant to be run with -race: many tasks read
+// the shared global environment while the main program keeps binding names
+// in it.
+func TestConcurrentEvaluation(t *testing.T) {
+	input := `
+	let results = channel(50);
+	let square = fn(n) { let sq = n * n; send(results, sq); sq };
+	let start = fn(n) {
+		if (n == 0) { [] } else { push(start(n - 1), spawn(square, n)) }
+	};
+	let tasks = start(50);
+	let a = 1; let b = 2; let c = a + b;
+	let joinAll = fn(ts) {
+		if (len(ts) == 0) { 0 } else { join(first(ts)) + joinAll(rest(ts)) }
+	};
+	let total = joinAll(tasks);
+	let drain = fn(n) { if (n == 0) { 0 } else { recv(results) + drain(n - 1) } };
+	total == drain(50)
+	`
+	testBooleanObject(t, testEval(input), true)
+}
+
+// TestConcurrentAssignment is meant to be run with -race: many tasks
+// assign to and read from the same hash and the same instance at once.
+func TestConcurrentAssignment(t *testing.T) {
+	input := `
+	class Box {}
+	let box = Box();
+	let shared = {};
+	let worker = fn(n) {
+		shared[n] = n * n;
+		box.last = n;
+		box.last;
+		contains(shared, 1);
+		shared[n]
+	};
+	let start = fn(n) {
+		if (n == 0) { [] } else { push(start(n - 1), spawn(worker, n)) }
+	};
+	let joinAll = fn(ts) {
+		if (len(ts) == 0) { 0 } else { join(first(ts)); joinAll(rest(ts)) }
+	};
+	joinAll(start(50));
+	[shared[1], shared[7], shared[50], type_of(box.last)]
+	`
+	evaluated := testEval(input)
+	if evaluated == nil || evaluated.Inspect() != "[1, 49, 2500, INTEGER]" {
+		t.Errorf("expected [1, 49, 2500, INTEGER], got %T (%+v)", evaluated, evaluated)
+	}
+}
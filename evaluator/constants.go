@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// evalLetStatement binds the value of a let or const statement in env. A
+// name bound as a constant in env cannot be bound again.
+func evalLetStatement(ls *ast.LetStatement, env *object.Environment) object.Object {
+	for _, name := range letNames(ls) {
+		if errObj := checkRedeclaration(name.Value, env); errObj != nil {
+			return errObj
+		}
+	}
+
+	v := Eval(ls.Value, env)
+	if isError(v) {
+		return v
+	}
+	if ls.Pattern != nil {
+		if errObj := checkType(ls.Pattern.String(), v, ls.Type, env); errObj != nil {
+			return errObj
+		}
+		return destructure(ls.Pattern, v, env, ls.Const)
+	}
+	if errObj := checkType(ls.Name.Value, v, ls.Type, env); errObj != nil {
+		return errObj
+	}
+	if fn, ok := v.(*object.Function); ok && fn.Name == "" {
+		if _, literal := ls.Value.(*ast.FunctionLiteral); literal {
+			fn.Name = ls.Name.Value
+		}
+	}
+	bind(env, ls.Name.Value, v, ls.Const)
+	return nil
+}
+
+// letNames returns the names a let or const statement binds.
+func letNames(ls *ast.LetStatement) []*ast.Identifier {
+	if ls.Pattern != nil {
+		return ast.PatternBindings(ls.Pattern)
+	}
+	return []*ast.Identifier{ls.Name}
+}
+
+// checkRedeclaration returns an error if name is bound as a constant in env,
+// where declaring it again would replace the constant.
+func checkRedeclaration(name string, env *object.Environment) *object.Error {
+	if env.IsConst(name) {
+		return object.NewError("cannot redeclare constant %s", name)
+	}
+	return nil
+}
+
+// bind binds name to value in env, as a constant if constant is set.
+func bind(env *object.Environment, name string, value object.Object, constant bool) {
+	if constant {
+		env.SetConst(name, value)
+	} else {
+		env.Set(name, value)
+	}
+}
+
+// freeze makes its argument and every array, hash, struct and instance in
+// it immutable, and returns it.
+func freeze(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+	return object.Freeze(args[0])
+}
+
+// checkMutable returns an error if value has been frozen.
+func checkMutable(value object.Object) *object.Error {
+	if object.IsFrozen(value) {
+		return object.NewError("cannot modify frozen %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/parser"
+)
+
+func TestConstantsAndIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 5; x * 2", "10"},
+		{"const [a, b] = [1, 2]; a + b", "3"},
+		{"const limit: int = 3; limit", "3"},
+		{"const x = 1; let f = fn() { let x = 2; x }; f() + x", "3"},
+		{"const x = 1; fn f(x) { x } f(5)", "5"},
+		{"let x = 1; const x = 2; x", "2"},
+		{"let xs = [1, 2, 3]; xs[1] = 5; xs", "[1, 5, 3]"},
+		{"let xs = [1, 2, 3]; xs[-1] = 5; xs", "[1, 2, 5]"},
+		{`let h = {"a": 1}; h["b"] = 2; h["a"] = 3; h`, "{a: 3,b: 2}"},
+		{`let h = {"a": [1]}; h["a"][0] = 2; h`, "{a: [2]}"},
+		{"let xs = [1, 2]; let ys = xs; ys[0] = 3; xs", "[3, 2]"},
+		{"let xs = [1, 2, 3]; let ys = rest(xs); ys[0] = 9; xs", "[1, 2, 3]"},
+		{"const xs = [1]; xs[0] = 2; xs", "[2]"},
+		{"let k = [1]; let h = {k: true}; k[0] = 2; h[[1]]", "true"},
+		{"struct P { xs } let p = P([1]); p.xs[0] = 2; p", "P{xs: [2]}"},
+		{"let xs = freeze([1, [2]]); xs[0] + xs[1][0]", "3"},
+		{"let xs = [1]; let ys = push(freeze(xs), 2); ys[0] = 3; ys", "[3, 2]"},
+		{"let xs = freeze([1]); let ys = xs; ys == [1]", "true"},
+		{"freeze(1)", "1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestConstantAndFreezeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 1; let x = 2;", "cannot redeclare constant x"},
+		{"const x = 1; const x = 2;", "cannot redeclare constant x"},
+		{"const x = 1; let [a, x] = [1, 2];", "cannot redeclare constant x"},
+		{"const [a, b] = [1, 2]; let b = 3;", "cannot redeclare constant b"},
+		{"const f = 1; fn f() {}", "cannot redeclare constant f"},
+		{"const P = 1; struct P { x }", "cannot redeclare constant P"},
+		{"const A = 1; class A {}", "cannot redeclare constant A"},
+		{"const x = 1; let x = y;", "cannot redeclare constant x"},
+		{"let xs = freeze([1, 2]); xs[0] = 3;", "cannot modify frozen ARRAY"},
+		{`let h = freeze({"a": 1}); h["b"] = 2;`, "cannot modify frozen HASH"},
+		{`let h = freeze({"a": [1]}); h["a"][0] = 2;`, "cannot modify frozen ARRAY"},
+		{"struct P { x } let p = freeze(P(1)); p.x = 2;", "cannot modify frozen STRUCT"},
+		{"struct P { xs } let p = freeze(P([1])); p.xs[0] = 2;", "cannot modify frozen ARRAY"},
+		{"class C { fn init() { self.n = 1 } fn bump() { self.n = self.n + 1 } } freeze(C()).bump()", "cannot modify frozen INSTANCE"},
+		{"let xs = [1]; let h = freeze({1: xs}); xs[0] = 2;", "cannot modify frozen ARRAY"},
+		{"let xs = [1]; xs[1] = 2;", "array index out of range: 1"},
+		{"let xs = [1]; xs[-2] = 2;", "array index out of range: -2"},
+		{`let xs = [1]; xs["a"] = 2;`, "array index must be INTEGER, got STRING"},
+		{"let h = {}; h[fn() {}] = 1;", "unusable as hash key: FUNCTION"},
+		{`let s = "abc"; s[0] = "x";`, "index assignment not supported: STRING"},
+		{"let xs = [1]; xs[0] = y;", "identifier not found: y"},
+		{"freeze()", "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestReadOnlyGlobals(t *testing.T) {
+	config := object.NewHash(1)
+	config.Set(&object.String{Value: "ports"}, &object.Array{Elements: []object.Object{&object.Integer{Value: 80}}})
+
+	env := object.NewEnvironment()
+	env.SetConst("config", object.Freeze(config))
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`config["ports"][0]`, "80"},
+		{`let config = {};`, "cannot redeclare constant config"},
+		{`config["ports"] = [];`, "cannot modify frozen HASH"},
+		{`config["ports"][0] = 8080;`, "cannot modify frozen ARRAY"},
+		{`let f = fn() { let config = 1; config }; f()`, "1"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := evaluator.Eval(program, env)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestDestructuringLet(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2]; a + b", "3"},
+		{"let [a, b, ...rest] = [1, 2, 3, 4]; rest", "[3, 4]"},
+		{"let [a, ...rest] = [1]; rest", "[]"},
+		{"let [_, b] = [1, 2]; b", "2"},
+		{`let {"name": n, "age": a} = {"name": "ada", "age": 36}; [n, a]`, `[ada, 36]`},
+		{`let {"name": n} = {"name": "ada", "age": 36}; n`, "ada"},
+		{`let [[a, b], {"c": [c]}] = [[1, 2], {"c": [3]}]; a + b + c`, "6"},
+		{"let [a, b = 2] = [1]; [a, b]", "[1, 2]"},
+		{"let [a, b = 2] = [1, 5]; b", "5"},
+		{"let [a, b = a * 10] = [1]; b", "10"},
+		{"let [a = 1, ...rest] = []; [a, rest]", "[1, []]"},
+		{`let {"port": port = 80} = {}; port`, "80"},
+		{`let {"db": {"host": host = "localhost"} = {}} = {}; host`, "localhost"},
+		{"let [1, a] = [1, 2]; a", "2"},
+		{"let [a, 1 | 2] = [3, 2]; a", "3"},
+		{"let a = 1; let f = fn() { let [a] = [2]; a }; [f(), a]", "[2, 1]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestDestructuringParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn([a, b]) { a + b }; f([1, 2])", "3"},
+		{`let f = fn({"x": x, "y": y}, scale) { [x * scale, y * scale] }; f({"x": 1, "y": 2}, 3)`, "[3, 6]"},
+		{`let f = fn({"x": x} = {"x": 7}) { x }; f()`, "7"},
+		{"let f = fn([a, ...rest], ...more) { [a, rest, more] }; f([1, 2], 3)", "[1, [2], [3]]"},
+		{"let f = fn(n, [a = n]) { a }; f(4, [])", "4"},
+		{"let f = fn([a, b]) { a + b }; f(...[[1, 2]])", "3"},
+		{"fn first([x, ..._]) { x } first([9, 8])", "9"},
+		{"let f = fn([a, b]) { a }; f", "<fn f/1>"},
+		{"let f = fn(n, [a, b]) { if (n == 0) { a + b } else { f(n - 1, [b, a]) } }; f(10000, [1, 2])", "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1];", "cannot destructure [a, b]: expected an array of 2 elements, got [1]"},
+		{"let [a] = [1, 2];", "cannot destructure [a]: expected an array of 1 element, got [1, 2]"},
+		{"let [a, b = 1] = [];", "cannot destructure [a, b = 1]: expected an array of 1 or 2 elements, got []"},
+		{"let [a, b, ...c] = [1];", "cannot destructure [a, b, ...c]: expected an array of at least 2 elements, got [1]"},
+		{"let [a] = 1;", "cannot destructure [a]: expected an array, got 1"},
+		{`let {"a": a} = [];`, `cannot destructure {a: a}: expected a hash, got []`},
+		{`let {"a": a} = {"b": 1};`, `cannot destructure {a: a}: expected a hash with key "a", got {"b": 1}`},
+		{`let [{"a": [b]}] = [{"a": 1}];`, `cannot destructure [{a: [b]}]: expected an array at [0]["a"], got 1`},
+		{"let [1, a] = [2, 3];", "cannot destructure [1, a]: expected 1 at [0], got 2"},
+		{"let [a = b] = [];", "identifier not found: b"},
+		{"let f = fn([a, b]) { a }; f([1])", "f: cannot destructure argument [a, b]: expected an array of 2 elements, got [1]"},
+		{"let f = fn([a, b]) { a }; f()", "f: invalid argument length; expected 1 arguments, got 0"},
+		{"let f = fn({1: a}) { a }; f(1)", "f: cannot destructure argument {1: a}: expected a hash, got 1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
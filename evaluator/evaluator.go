@@ -1,6 +1,9 @@
 package evaluator
 
 import (
+	"math"
+	"math/big"
+
 	"github.com/ekediala/jian/ast"
 	"github.com/ekediala/jian/object"
 	"github.com/ekediala/jian/token"
@@ -24,6 +27,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalBlockStatement(val, env)
 
 	case *ast.IntegerLiteral:
+		if val.Big != nil {
+			return object.NewBigInteger(new(big.Int).Set(val.Big))
+		}
 		return &object.Integer{Value: val.Value}
 
 	case *ast.StringLiteral:
@@ -44,17 +50,32 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		if val.Operator == token.COALESCE {
+			if left != NULL {
+				return left
+			}
+			return Eval(val.Right, env)
+		}
 
 		right := Eval(val.Right, env)
 		if isError(right) {
 			return right
 		}
 
+		if val.Token.Type == token.IS {
+			return evalIsExpression(left, right)
+		}
 		return evalInfixExpression(left, val.Operator, right)
 
 	case *ast.IfExpression:
 		return evalIfExpression(val, env)
 
+	case *ast.SelectExpression:
+		return evalSelectExpression(val, env)
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(val, env, Eval)
+
 	case *ast.ReturnStatement:
 		v := Eval(val.ReturnValue, env)
 		if isError(v) {
@@ -62,38 +83,46 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.ReturnValue{Value: v}
 
+	case *ast.YieldStatement:
+		return evalYieldStatement(val, env)
+
 	case *ast.LetStatement:
-		v := Eval(val.Value, env)
-		if isError(v) {
-			return v
-		}
-		env.Set(val.Name.Value, v)
+		return evalLetStatement(val, env)
+
+	case *ast.FunctionStatement:
+		// bound by hoistFunctions when the enclosing block started
+
+	case *ast.StructStatement:
+		return evalStructStatement(val, env)
+
+	case *ast.ClassStatement:
+		return evalClassStatement(val, env)
+
+	case *ast.AssignStatement:
+		return evalAssignStatement(val, env)
+
+	case *ast.FieldExpression, *ast.IndexExpression, *ast.CallExpression:
+		result, _ := evalChain(val, env)
+		return result
 
 	case *ast.Identifier:
 		return evalIdentifier(val, env)
 
 	case *ast.FunctionLiteral:
-		return object.NewFunction(val.Parameters, val.Body, env)
+		fn := object.NewFunction(val.Parameters, val.Body, env)
+		fn.Defaults, fn.Patterns, fn.Rest = val.Defaults, val.Patterns, val.Rest
+		fn.ParamTypes, fn.RestType, fn.ReturnType = val.ParamTypes, val.RestType, val.ReturnType
+		fn.Generator = val.Generator
+		return fn
 
-	case *ast.CallExpression:
-		{
-			fn := Eval(val.Function, env)
-			if isError(fn) {
-				return fn
-			}
+	case *ast.KeywordArgument:
+		return object.NewError("keyword argument `%s` outside of a call", val.Name.Value)
 
-			if function, ok := fn.(*object.Function); ok {
-				if exp, got := len(function.Parameters), len(val.Arguments); exp != got {
-					return object.NewError("invalid argument length; expected %d arguments, got %d", exp, got)
-				}
-			}
+	case *ast.SpreadExpression:
+		return object.NewError("spread outside of a call")
 
-			args := evalExpressions(val.Arguments, env)
-			if len(args) == 1 && isError(args[0]) {
-				return args[0]
-			}
-			return applyFunction(fn, args)
-		}
+	case *ast.MacroLiteral:
+		return object.NewError("macros must be defined with a top-level let statement")
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(val.Elements, env)
@@ -102,52 +131,39 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.Array{Elements: elements}
 
-	case *ast.IndexExpression:
-		{
-			left := Eval(val.Left, env)
-			if isError(left) {
-				return left
-			}
-
-			index := Eval(val.Index, env)
-			if isError(index) {
-				return index
-			}
-
-			return evalIndexOperation(left, index)
-		}
-
 	case *ast.HashLiteral:
 		{
-			h := object.Hash{
-				Pairs: map[object.HashKey]object.HashPair{},
-			}
+			h := object.NewHash(len(val.Keys))
 
-			for k, v := range val.Pairs {
+			for _, k := range val.Keys {
 				key := Eval(k, env)
 				if isError(key) {
 					return key
 				}
 
-				hashable, ok := key.(object.Hashable)
+				hashable, ok := asHashKey(key)
 				if !ok {
 					return object.NewError("unusable as hash key: %s", key.Type())
 				}
 
-				value := Eval(v, env)
+				value := Eval(val.Pairs[k], env)
 				if isError(value) {
 					return value
 				}
-				h.Pairs[hashable.HashKey()] = object.HashPair{Key: key, Value: value}
+				h.Set(hashable, value)
 			}
 
-			return &h
+			return h
 		}
 	}
 
 	return nil
 }
 
+// evalIndexOperation reads index from the array, string or hash left.
+// Negative indexes count back from the end of arrays and strings, and
+// indexes out of range give null. Strings are indexed by byte, as len
+// counts them, and give one-character strings.
 func evalIndexOperation(left object.Object, index object.Object) object.Object {
 	switch obj := left.(type) {
 	case *object.Array:
@@ -155,14 +171,30 @@ func evalIndexOperation(left object.Object, index object.Object) object.Object {
 			if i, ok := index.(*object.Integer); ok {
 				return evalArrayIndexOperation(obj, i)
 			}
+			if _, ok := index.(*object.BigInteger); ok {
+				return NULL
+			}
+			return object.NewError("expected index to be *object.Integer, got %T", index)
+		}
+
+	case *object.String:
+		switch index := index.(type) {
+		case *object.Integer:
+			if i, ok := sequenceIndex(index.Value, len(obj.Value)); ok {
+				return &object.String{Value: obj.Value[i : i+1]}
+			}
+			return NULL
+		case *object.BigInteger:
+			return NULL
+		default:
 			return object.NewError("expected index to be *object.Integer, got %T", index)
 		}
 
 	case *object.Hash:
 		{
-			if key, ok := index.(object.Hashable); ok {
-				if val, ok := obj.Pairs[key.HashKey()]; ok {
-					return val.Value
+			if key, ok := asHashKey(index); ok {
+				if val, ok := obj.Get(key); ok {
+					return val
 				}
 				return NULL
 			}
@@ -175,15 +207,79 @@ func evalIndexOperation(left object.Object, index object.Object) object.Object {
 	}
 }
 
+// evalIndexAssignment stores value at index in the array or hash left.
+// Arrays only have their existing elements replaced, counting back from the
+// end for negative indexes; hashes gain a new entry if they do not have the
+// key.
+func evalIndexAssignment(left, index, value object.Object) object.Object {
+	if errObj := checkMutable(left); errObj != nil {
+		return errObj
+	}
+
+	switch obj := left.(type) {
+	case *object.Array:
+		switch i := index.(type) {
+		case *object.Integer:
+			n, ok := sequenceIndex(i.Value, len(obj.Elements))
+			if !ok {
+				return object.NewError("array index out of range: %d", i.Value)
+			}
+			obj.Elements[n] = value
+			return nil
+		case *object.BigInteger:
+			return object.NewError("array index out of range: %s", i.Inspect())
+		default:
+			return object.NewError("array index must be INTEGER, got %s", index.Type())
+		}
+
+	case *object.Hash:
+		key, ok := asHashKey(index)
+		if !ok {
+			return object.NewError("unusable as hash key: %s", index.Type())
+		}
+		obj.Set(key, value)
+		return nil
+
+	default:
+		return object.NewError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// asHashKey returns obj as a hash key if it can be used as one.
+func asHashKey(obj object.Object) (object.Hashable, bool) {
+	if !object.IsHashable(obj) {
+		return nil, false
+	}
+	return obj.(object.Hashable), true
+}
+
 func evalArrayIndexOperation(arr *object.Array, index *object.Integer) object.Object {
-	if index.Value < 0 || index.Value >= int64(len(arr.Elements)) {
+	i, ok := sequenceIndex(index.Value, len(arr.Elements))
+	if !ok {
 		return NULL
 	}
 
-	return arr.Elements[index.Value]
+	return arr.Elements[i]
+}
+
+// sequenceIndex returns the position index refers to in an array or string
+// of the given length, counting back from the end if index is negative, and
+// reports whether it is in range.
+func sequenceIndex(index int64, length int) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
 }
 
 func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
+	if errObj := hoistFunctions(stmts, env); errObj != nil {
+		return errObj
+	}
+
 	var r object.Object
 	for _, stmt := range stmts {
 		r = Eval(stmt, env)
@@ -198,6 +294,10 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 }
 
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	if errObj := hoistFunctions(block.Statements, env); errObj != nil {
+		return errObj
+	}
+
 	var r object.Object
 
 	for _, stmt := range block.Statements {
@@ -244,25 +344,38 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusOperatorExpression(right object.Object) object.Object {
-	if obj, ok := right.(*object.Integer); ok {
+	switch obj := right.(type) {
+	case *object.Integer:
+		if obj.Value == math.MinInt64 {
+			return object.NewBigInteger(new(big.Int).Neg(big.NewInt(obj.Value)))
+		}
 		return &object.Integer{Value: -obj.Value}
+	case *object.BigInteger:
+		return object.NewBigInteger(new(big.Int).Neg(obj.Value))
+	case *object.Float:
+		return &object.Float{Value: -obj.Value}
+	default:
+		return object.NewError("unknown operator: -%s", right.Type())
 	}
-	return object.NewError("unknown operator: -%s", right.Type())
 }
 
 func evalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
 	switch {
+	case operator == token.EQ:
+		return nativeBoolToBooleanObject(object.Equal(left, right))
+	case operator == token.NOT_EQ:
+		return nativeBoolToBooleanObject(!object.Equal(left, right))
 	case left.Type() != right.Type():
 		return object.NewError("type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
 	case left.Type() == object.INTEGER:
-		return evalIntegerInfixOperation(left.(*object.Integer), operator, right.(*object.Integer))
+		return evalIntegerInfixOperation(left, operator, right)
+	case left.Type() == object.FLOAT:
+		return evalFloatInfixOperation(left.(*object.Float), operator, right.(*object.Float))
 	case left.Type() == object.STRING:
 		return evalStringInfixOperation(left.(*object.String), operator, right.(*object.String))
-	case operator == token.EQ:
-		return nativeBoolToBooleanObject(left == right)
-	case operator == token.NOT_EQ:
-		return nativeBoolToBooleanObject(left != right)
+	case left.Type() == object.ARRAY && (operator == token.LT || operator == token.GT):
+		return evalOrdering(left, operator, right)
 	default:
 		return object.NewError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
@@ -273,34 +386,117 @@ func evalStringInfixOperation(left *object.String, operator string, right *objec
 	switch operator {
 	case token.PLUS:
 		return &object.String{Value: left.Value + right.Value}
-	case token.EQ:
-		return nativeBoolToBooleanObject(left.Value == right.Value)
-	case token.NOT_EQ:
-		return nativeBoolToBooleanObject(left.Value != right.Value)
+	case token.LT, token.GT:
+		return evalOrdering(left, operator, right)
+	default:
+		return object.NewError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+func evalOrdering(left object.Object, operator string, right object.Object) object.Object {
+	c, err := object.Compare(left, right)
+	if err != nil {
+		return object.NewError("%s", err)
+	}
+
+	if operator == token.LT {
+		return nativeBoolToBooleanObject(c < 0)
+	}
+	return nativeBoolToBooleanObject(c > 0)
+}
+
+// evalIntegerInfixOperation does arithmetic on int64 values while the
+// result fits and switches to math/big when it would overflow.
+func evalIntegerInfixOperation(left object.Object, operator string, right object.Object) object.Object {
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if !lok || !rok {
+		return evalBigIntegerInfixOperation(left, operator, right)
+	}
+
+	switch operator {
+	case token.MINUS:
+		if diff := l.Value - r.Value; (diff < l.Value) == (r.Value > 0) {
+			return &object.Integer{Value: diff}
+		}
+	case token.PLUS:
+		if sum := l.Value + r.Value; (sum > l.Value) == (r.Value > 0) {
+			return &object.Integer{Value: sum}
+		}
+	case token.SLASH:
+		if r.Value == 0 {
+			return object.NewError("division by zero")
+		}
+		if l.Value != math.MinInt64 || r.Value != -1 {
+			return &object.Integer{Value: l.Value / r.Value}
+		}
+	case token.ASTERISK:
+		if l.Value == 0 || r.Value == 0 {
+			return &object.Integer{Value: 0}
+		}
+		product := l.Value * r.Value
+		overflows := product/r.Value != l.Value ||
+			(l.Value == -1 && r.Value == math.MinInt64) ||
+			(r.Value == -1 && l.Value == math.MinInt64)
+		if !overflows {
+			return &object.Integer{Value: product}
+		}
+	case token.LT:
+		return nativeBoolToBooleanObject(l.Value < r.Value)
+	case token.GT:
+		return nativeBoolToBooleanObject(l.Value > r.Value)
+	default:
+		return object.NewError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+
+	return evalBigIntegerInfixOperation(left, operator, right)
+}
+
+func evalBigIntegerInfixOperation(left object.Object, operator string, right object.Object) object.Object {
+	l, _ := object.BigValue(left)
+	r, _ := object.BigValue(right)
+
+	switch operator {
+	case token.MINUS:
+		return object.NewBigInteger(l.Sub(l, r))
+	case token.PLUS:
+		return object.NewBigInteger(l.Add(l, r))
+	case token.SLASH:
+		if r.Sign() == 0 {
+			return object.NewError("division by zero")
+		}
+		return object.NewBigInteger(l.Quo(l, r))
+	case token.ASTERISK:
+		return object.NewBigInteger(l.Mul(l, r))
+	case token.LT:
+		return nativeBoolToBooleanObject(l.Cmp(r) < 0)
+	case token.GT:
+		return nativeBoolToBooleanObject(l.Cmp(r) > 0)
 	default:
 		return object.NewError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
-func evalIntegerInfixOperation(left *object.Integer, operator string, right *object.Integer) object.Object {
+func evalFloatInfixOperation(left *object.Float, operator string, right *object.Float) object.Object {
 	switch operator {
 	case token.MINUS:
-		return &object.Integer{Value: left.Value - right.Value}
+		return &object.Float{Value: left.Value - right.Value}
 	case token.PLUS:
-		return &object.Integer{Value: left.Value + right.Value}
+		return &object.Float{Value: left.Value + right.Value}
 	case token.SLASH:
-		return &object.Integer{Value: left.Value / right.Value}
+		if right.Value == 0 {
+			return object.NewError("division by zero")
+		}
+		return &object.Float{Value: left.Value / right.Value}
 	case token.ASTERISK:
-		return &object.Integer{Value: left.Value * right.Value}
+		return &object.Float{Value: left.Value * right.Value}
 	case token.LT:
 		return nativeBoolToBooleanObject(left.Value < right.Value)
 	case token.GT:
 		return nativeBoolToBooleanObject(left.Value > right.Value)
-	case token.EQ:
-		return nativeBoolToBooleanObject(left.Value == right.Value)
-	case token.NOT_EQ:
-		return nativeBoolToBooleanObject(left.Value != right.Value)
 	default:
 		return object.NewError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
@@ -368,30 +564,103 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return r
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
-	switch obj := fn.(type) {
-	case *object.Function:
-		{
-			env := extendFunctionEnv(obj, args)
-			val := Eval(obj.Body, env)
-			return unwrapReturnValue(val)
-		}
-	case *object.Builtin:
-		{
-			return obj.Fn(args...)
+// Apply calls a function or builtin value with already evaluated arguments.
+// env is the environment of the call site; builtins use it to reach the
+// host's I/O streams.
+func Apply(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	return applyFunction(fn, args, env)
+}
+
+// applyFunction calls fn with positional arguments only.
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	return callFunction(fn, args, nil, env)
+}
+
+// callFunction calls fn with args and keywords. Calls that a function body
+// makes in tail position come back as a *tailCall and are made by the loop
+// here rather than by recursing, so they do not grow the Go stack.
+func callFunction(fn object.Object, args []object.Object, keywords []object.Keyword, env *object.Environment) object.Object {
+	// tail is the call that entered fn when it was made in tail position.
+	// The caller's own frame is gone by then, so an error raised in fn
+	// gets its frame here instead.
+	var tail *ast.CallExpression
+	// returning holds the functions with a declared return type that
+	// passed their result on by a tail call, so that the result can be
+	// checked against their types too once it is known.
+	var returning []*object.Function
+
+	for {
+		switch obj := fn.(type) {
+		case *object.Function:
+			{
+				var val object.Object
+				if fnEnv, errObj := extendFunctionEnv(obj, args, keywords); errObj != nil {
+					val = errObj
+				} else if obj.Generator {
+					val = newGenerator(obj, fnEnv)
+				} else {
+					val = unwrapReturnValue(evalTail(obj.Body, fnEnv))
+				}
+				if tc, ok := val.(*tailCall); ok {
+					if obj.ReturnType != nil && obj.Env.Strict() &&
+						(len(returning) == 0 || returning[len(returning)-1] != obj) {
+						returning = append(returning, obj)
+					}
+					fn, args, keywords, tail = tc.fn, tc.args, tc.keywords, tc.call
+					continue
+				}
+				if !isError(val) {
+					// The return type of a generator function is the type
+					// of the values it yields, which are checked as they
+					// are yielded.
+					if !obj.Generator {
+						returning = append(returning, obj)
+					}
+					val = checkReturnValue(returning, val)
+				}
+				if tail != nil {
+					return withFrame(val, obj, tail)
+				}
+				return val
+			}
+		case *object.Builtin:
+			{
+				if obj.Signature != nil {
+					bound, errObj := obj.Signature.Bind(args, keywords)
+					if errObj != nil {
+						return errObj
+					}
+					args = bound
+				} else if len(keywords) > 0 {
+					return object.NewError("builtin function does not accept keyword arguments")
+				}
+				return obj.Fn(env, args...)
+			}
+		case *object.Class:
+			return instantiate(obj, args, keywords, env)
+		case *object.StructType:
+			{
+				s, errObj := obj.New(args, keywords)
+				if errObj != nil {
+					return errObj
+				}
+				return s
+			}
+		default:
+			return object.NewError("not a function: %s", fn.Type())
 		}
-	default:
-		return object.NewError("not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
-	for i, param := range fn.Parameters {
-		env.Set(param.Value, args[i])
+// checkReturnValue checks val against the declared return type of each of
+// fns, innermost first, and returns it or the first type error.
+func checkReturnValue(fns []*object.Function, val object.Object) object.Object {
+	for i := len(fns) - 1; i >= 0; i-- {
+		if errObj := checkType("return value", val, fns[i].ReturnType, fns[i].Env); errObj != nil {
+			return argumentError(fns[i], "%s", errObj.Message)
+		}
 	}
-
-	return env
+	return val
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -1,6 +1,8 @@
 package evaluator_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/ekediala/jian/evaluator"
@@ -36,6 +38,64 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"-9223372036854775807 - 1", "-9223372036854775808"},
+		{"-9223372036854775807 - 1 - -1", "-9223372036854775807"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) * -1", "9223372036854775808"},
+		{"-1 * (-9223372036854775807 - 1)", "9223372036854775808"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-4294967296 * 4294967296", "-18446744073709551616"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 - 123456789012345678901234567890", "0"},
+		{"-123456789012345678901234567890 / 10", "-12345678901234567890123456789"},
+		{"-7 / 2", "-3"},
+		{"-70000000000000000000 / 20000000000000000000", "-3"},
+		{"9223372036854775808 - 1", "9223372036854775807"},
+		{"let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(25)", "15511210043330985984000000"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Type() != object.INTEGER || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %s (%T)", tt.input, tt.expected, evaluated.Inspect(), evaluated)
+		}
+	}
+
+	// Results that fit in an int64 are plain integers again.
+	testIntegerObject(t, testEval("9223372036854775808 - 1"), 9223372036854775807)
+	testIntegerObject(t, testEval("-(9223372036854775808)"), -9223372036854775808)
+}
+
+func TestBigIntegerComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"9223372036854775808 > 9223372036854775807", true},
+		{"9223372036854775807 < 9223372036854775808", true},
+		{"-9223372036854775809 < 0", true},
+		{"100000000000000000000 == 10000000000 * 10000000000", true},
+		{"100000000000000000000 != 100000000000000000001", true},
+		{"9223372036854775808 - 1 == 9223372036854775807", true},
+		{"[9223372036854775808] < [9223372036854775809]", true},
+		{"{100000000000000000000: 1}[10000000000 * 10000000000] == 1", true},
+		{"[1, 2][9223372036854775808] == first([])", true},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if !testBooleanObject(t, evaluated, tt.expected) {
+			t.Logf("input: %s", tt.input)
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -67,6 +127,82 @@ func TestEvalBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestStructuralEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{`[1, ["a", [true]]] == [1, ["a", [true]]]`, true},
+		{`[1, ["a", [true]]] == [1, ["a", [false]]]`, false},
+		{"[] == []", true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"a": 1, "b": 2}`, false},
+		{`{"a": {"b": [1]}} != {"a": {"b": [1]}}`, false},
+		{"{} == {}", true},
+		{`1 == "1"`, false},
+		{`1 != "1"`, true},
+		{"[1] == 1", false},
+		{"first([]) == first([])", true},
+		{"first([]) == 0", false},
+		{"first([]) != false", true},
+		{"[first([])] == [last([])]", true},
+		{"let f = fn(x) { x }; f == f", true},
+		{"let make = fn() { fn(x) { x } }; make() == make()", false},
+		{"fn(x) { x } == fn(x) { x }", false},
+		{"len == len", true},
+		{"len == first", false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if !testBooleanObject(t, evaluated, tt.expected) {
+			t.Logf("input: %s", tt.input)
+		}
+	}
+}
+
+func TestOrderingComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"abc" > "ab"`, true},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] > [1, 3]", false},
+		{"[1, 2] < [1, 2, 0]", true},
+		{"[2] > [1, 9, 9]", true},
+		{"[] < [1]", true},
+		{"[1, 2] < [1, 2]", false},
+		{`[["a", 2], 1] < [["a", 3], 0]`, true},
+		{`[1] < ["a"]`, "cannot order INTEGER and STRING"},
+		{"[true] < [false]", "cannot order BOOLEAN and BOOLEAN"},
+		{"true < false", "unknown operator: BOOLEAN < BOOLEAN"},
+		{`[1] < "a"`, "type mismatch: ARRAY < STRING"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("%s: expected error, got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("%s: expected %q, got %q", tt.input, expected, errObj.Message)
+			}
+		}
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -205,6 +341,14 @@ func TestErrorHandling(t *testing.T) {
 			`{"name": "Monkey"}[fn(x) { x }];`,
 			"unusable as hash key: FUNCTION",
 		},
+		{
+			"1 / 0",
+			"division by zero",
+		},
+		{
+			"100000000000000000000 / (5 - 5)",
+			"division by zero",
+		},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -327,7 +471,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`puts("hello", "world!")`, nil},
 		{`first([1, 2, 3])`, 1},
 		{`first([])`, nil},
-		{`first(1)`, "argument to `first` must be ARRAY, got INTEGER"},
+		{`first(1)`, "argument to `first` must be ARRAY or GENERATOR, got INTEGER"},
 		{`last([1, 2, 3])`, 3},
 		{`last([])`, nil},
 		{`last(1)`, "argument to `last` must be ARRAY, got INTEGER"},
@@ -335,6 +479,12 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`rest([])`, nil},
 		{`push([], 1)`, []int{1}},
 		{`push(1, 1)`, "argument to `push` must be ARRAY, got INTEGER"},
+		{`index_of([1, [2], "3"], [2])`, 1},
+		{`index_of([1, 2], "1")`, -1},
+		{`index_of("hello", "ll")`, 2},
+		{`index_of("hello", "x")`, -1},
+		{`index_of("hello", 1)`, "cannot search STRING for INTEGER"},
+		{`index_of(1, 1)`, "argument to `index_of` not supported, got INTEGER"},
 	}
 
 	for _, tt := range tests {
@@ -376,6 +526,44 @@ func TestBuiltinFunctions(t *testing.T) {
 	}
 }
 
+func TestContains(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`contains([1, 2, 3], 2)`, true},
+		{`contains([1, 2, 3], "2")`, false},
+		{`contains([[1, 2], {"a": 1}], {"a": 1})`, true},
+		{`contains([first([])], last([]))`, true},
+		{`contains({"a": 1, [1]: 2}, "a")`, true},
+		{`contains({"a": 1, [1]: 2}, [1])`, true},
+		{`contains({"a": 1}, "b")`, false},
+		{`contains({"a": 1}, fn() {})`, false},
+		{`contains("hello", "ell")`, true},
+		{`contains("hello", "")`, true},
+		{`contains("hello", "xyz")`, false},
+		{`contains("hello", 1)`, "cannot search STRING for INTEGER"},
+		{`contains(1, 1)`, "argument to `contains` not supported, got INTEGER"},
+		{`contains([1])`, "wrong number of arguments. got=1, want=2"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("%s: expected error, got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("%s: expected %q, got %q", tt.input, expected, errObj.Message)
+			}
+		}
+	}
+}
+
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 	evaluated := testEval(input)
@@ -435,6 +623,14 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
 	}
@@ -483,26 +679,77 @@ func TestHashLiterals(t *testing.T) {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
 	}
 
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "one"}).HashKey():   1,
-		(&object.String{Value: "two"}).HashKey():   2,
-		(&object.String{Value: "three"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():      4,
-		evaluator.TRUE.HashKey():                   5,
-		evaluator.FALSE.HashKey():                  6,
+	expected := []struct {
+		key   object.Hashable
+		value int64
+	}{
+		{&object.String{Value: "one"}, 1},
+		{&object.String{Value: "two"}, 2},
+		{&object.String{Value: "three"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{evaluator.TRUE, 5},
+		{evaluator.FALSE, 6},
 	}
 
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 	}
 
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+	for _, tt := range expected {
+		value, ok := result.Get(tt.key)
 		if !ok {
-			t.Errorf("no pair for given key in Pairs")
+			t.Errorf("no pair for key %s in hash", tt.key.Inspect())
+			continue
+		}
+
+		testIntegerObject(t, value, tt.value)
+	}
+}
+
+func TestCompositeHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{[1, 2]: 5}[[1, 2]]`, 5},
+		{`{[1, 2]: 5}[[2, 1]]`, nil},
+		{`{[1, [2, "x"]]: 5}[[1, [2, "x"]]]`, 5},
+		{`{[]: 5}[[]]`, 5},
+		{`{[1]: 5, 1: 6, "1": 7, [[1]]: 8}[1]`, 6},
+		{`{[1]: 5, 1: 6, "1": 7, [[1]]: 8}[[[1]]]`, 8},
+		{`let h = {[true]: 1, [true]: 2}; h[[true]]`, 2},
+		{`{[fn(x) { x }]: 1}`, "unusable as hash key: ARRAY"},
+		{`{"a": 1}[[1, {}]]`, "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("%s: expected error, got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("%s: expected %q, got %q", tt.input, expected, errObj.Message)
+			}
 		}
+	}
+}
+
+func TestHashLiteralOrder(t *testing.T) {
+	input := `let k = "key"; {"z": 1, 10: 2, true: 3, "a": 4, k: 5, "m": 6, "z": 7}`
+	expected := "{z: 7,10: 2,true: 3,a: 4,key: 5,m: 6}"
 
-		testIntegerObject(t, pair.Value, expectedValue)
+	for i := 0; i < 10; i++ {
+		if got := testEval(input).Inspect(); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
 	}
 }
 
@@ -552,6 +799,119 @@ func TestHashIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestAssertionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`assert(true)`, nil},
+		{`assert(1 < 2, "ordering")`, nil},
+		{`assert(false)`, "assertion failed"},
+		{`assert(1 > 2, "ordering")`, "assertion failed: ordering"},
+		{`assert()`, "wrong number of arguments. got=0, want=1 or 2"},
+		{`assert_eq(1 + 1, 2)`, nil},
+		{`assert_eq([1, "a"], [1, "a"])`, nil},
+		{`assert_eq({"a": [1]}, {"a": [1]})`, nil},
+		{`assert_eq(1, "1")`, "assert_eq failed\n    got:  1\n    want: \"1\"\n    type mismatch: got INTEGER, want STRING"},
+		{`assert_eq([1, 2, 3], [1, 5])`, "assert_eq failed\n    got:  [1, 2, 3]\n    want: [1, 5]\n    [1]: got 2, want 5\n    length mismatch: got 3, want 2"},
+		{`assert_eq({"a": 1, "b": 2}, {"a": 3, "c": 2})`, "assert_eq failed\n    got:  {\"a\": 1, \"b\": 2}\n    want: {\"a\": 3, \"c\": 2}\n    [\"a\"]: got 1, want 3\n    [\"b\"]: unexpected key\n    [\"c\"]: missing key"},
+		{`let a = {"n": 1}; a["self"] = a; let b = {"n": 1}; b["self"] = b; assert_eq(a, b)`, nil},
+		{`let a = {"n": 1}; a["self"] = a; let b = {"n": 2}; b["self"] = b; assert_eq(a, b)`, "assert_eq failed\n    got:  {\"n\": 1, \"self\": {...}}\n    want: {\"n\": 2, \"self\": {...}}\n    [\"n\"]: got 1, want 2"},
+		{`let xs = [1, 2]; xs[1] = xs; assert_eq(xs, [1])`, "assert_eq failed\n    got:  [1, [...]]\n    want: [1]\n    length mismatch: got 2, want 1"},
+		{`assert_error(fn() { 1 + true })`, nil},
+		{`assert_error(fn() { 1 + true }, "type mismatch")`, nil},
+		{`assert_error(fn() { 1 })`, "assert_error failed: expected an error, got 1"},
+		{`assert_error(fn() { -true }, "type mismatch")`, "assert_error failed: expected error containing \"type mismatch\", got \"unknown operator: -BOOLEAN\""},
+		{`assert_error(fn(x = 1) { x + true })`, nil},
+		{`assert_error(5)`, "first argument to `assert_error` must be FUNCTION, got INTEGER"},
+		{`assert_error(fn(x) { x })`, "first argument to `assert_error` must take no arguments, got <fn/1>"},
+		{`assert_error(len)`, "first argument to `assert_error` must take no arguments, got builtin function(arg)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestIOBuiltins(t *testing.T) {
+	tests := []struct {
+		input          string
+		stdin          string
+		expected       interface{}
+		expectedStdout string
+		expectedStderr string
+	}{
+		{`puts("hello", 1)`, "", nil, "hello\n1\n", ""},
+		{`print("a", 1); print("b")`, "", nil, "a 1b", ""},
+		{`eprint("oops")`, "", nil, "", "oops"},
+		{`read_line()`, "first\nsecond\n", "first", "", ""},
+		{`read_line(); read_line()`, "first\r\nsecond", "second", "", ""},
+		{`read_line()`, "", nil, "", ""},
+		{`input("name? ")`, "jian\n", "jian", "name? ", ""},
+		{`input() + input()`, "a\nb\n", "ab", "", ""},
+		{`read_line(1)`, "", "wrong number of arguments. got=1, want=0", "", ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		env := object.NewEnvironment()
+		env.SetIO(object.NewIO(strings.NewReader(tt.stdin), &stdout, &stderr))
+		evaluated := evaluator.Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+
+		switch expected := tt.expected.(type) {
+		case nil:
+			testNullObject(t, evaluated)
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("%s: expected %q, got %q", tt.input, expected, obj.Value)
+				}
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("%s: expected error %q, got %q", tt.input, expected, obj.Message)
+				}
+			default:
+				t.Errorf("%s: expected %q, got %T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		}
+
+		if got := stdout.String(); got != tt.expectedStdout {
+			t.Errorf("%s: expected stdout %q, got %q", tt.input, tt.expectedStdout, got)
+		}
+		if got := stderr.String(); got != tt.expectedStderr {
+			t.Errorf("%s: expected stderr %q, got %q", tt.input, tt.expectedStderr, got)
+		}
+	}
+}
+
+func TestIOIsInheritedByFunctions(t *testing.T) {
+	var stdout bytes.Buffer
+	env := object.NewEnvironment()
+	env.SetIO(object.NewIO(strings.NewReader(""), &stdout, &stdout))
+
+	input := `let greet = fn(name) { let say = fn() { puts("hi " + name) }; say() }; join(spawn(greet, "jian"))`
+	evaluator.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+
+	if got, expected := stdout.String(), "hi jian\n"; got != expected {
+		t.Errorf("expected stdout %q, got %q", expected, got)
+	}
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != evaluator.NULL {
 		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
@@ -595,3 +955,45 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	}
 	return true
 }
+
+// testObject checks evaluated against expected: an int, a bool, nil for
+// NULL, a []string for an array of strings, or a string, which matches
+// either an error with that message or a string with that value.
+func testObject(t *testing.T, input string, evaluated object.Object, expected interface{}) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		testIntegerObject(t, evaluated, int64(expected))
+	case bool:
+		testBooleanObject(t, evaluated, expected)
+	case nil:
+		testNullObject(t, evaluated)
+	case string:
+		switch obj := evaluated.(type) {
+		case *object.Error:
+			if obj.Message != expected {
+				t.Errorf("%s: wrong error message. expected=%q, got=%q", input, expected, obj.Message)
+			}
+		case *object.String:
+			if obj.Value != expected {
+				t.Errorf("%s: wrong string. expected=%q, got=%q", input, expected, obj.Value)
+			}
+		default:
+			t.Errorf("%s: expected %q, got %T (%+v)", input, expected, evaluated, evaluated)
+		}
+	case []string:
+		arr, ok := evaluated.(*object.Array)
+		if !ok || len(arr.Elements) != len(expected) {
+			t.Errorf("%s: expected %v, got %T (%+v)", input, expected, evaluated, evaluated)
+			return
+		}
+		for i, value := range expected {
+			if str, ok := arr.Elements[i].(*object.String); !ok || str.Value != value {
+				t.Errorf("%s: element %d: expected %q, got %+v", input, i, value, arr.Elements[i])
+			}
+		}
+	default:
+		t.Fatalf("%s: unsupported expected value %T", input, expected)
+	}
+}
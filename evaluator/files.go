@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+	"path"
+
+	"github.com/ekediala/jian/filesystem"
+	"github.com/ekediala/jian/object"
+)
+
+// fileSystem returns the host's filesystem, or an error object naming the
+// builtin when the host has not enabled file access.
+func fileSystem(env *object.Environment, builtin string) (filesystem.FS, *object.Error) {
+	fsys := env.FS()
+	if fsys == nil {
+		return nil, object.NewError("%s: %s", builtin, filesystem.ErrDisabled)
+	}
+	return fsys, nil
+}
+
+func stringArgs(builtin string, args []object.Object, want int) ([]string, *object.Error) {
+	if got := len(args); got != want {
+		return nil, object.NewError("wrong number of arguments. got=%d, want=%d", got, want)
+	}
+
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, object.NewError("arguments to `%s` must be STRING, got %s", builtin, arg.Type())
+		}
+		values = append(values, str.Value)
+	}
+	return values, nil
+}
+
+func readFile(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("read_file", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	fsys, errObj := fileSystem(env, "read_file")
+	if errObj != nil {
+		return errObj
+	}
+
+	data, err := fsys.ReadFile(values[0])
+	if err != nil {
+		return object.NewError("read_file: %s", err)
+	}
+	return &object.String{Value: string(data)}
+}
+
+func writeFile(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("write_file", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	fsys, errObj := fileSystem(env, "write_file")
+	if errObj != nil {
+		return errObj
+	}
+
+	if err := fsys.WriteFile(values[0], []byte(values[1])); err != nil {
+		return object.NewError("write_file: %s", err)
+	}
+	return NULL
+}
+
+func appendFile(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("append_file", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	fsys, errObj := fileSystem(env, "append_file")
+	if errObj != nil {
+		return errObj
+	}
+
+	if err := fsys.AppendFile(values[0], []byte(values[1])); err != nil {
+		return object.NewError("append_file: %s", err)
+	}
+	return NULL
+}
+
+func listDir(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) == 0 {
+		args = []object.Object{&object.String{Value: "."}}
+	}
+	values, errObj := stringArgs("list_dir", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	fsys, errObj := fileSystem(env, "list_dir")
+	if errObj != nil {
+		return errObj
+	}
+
+	names, err := fsys.ReadDir(values[0])
+	if err != nil {
+		return object.NewError("list_dir: %s", err)
+	}
+
+	elements := make([]object.Object, 0, len(names))
+	for _, name := range names {
+		elements = append(elements, &object.String{Value: name})
+	}
+	return &object.Array{Elements: elements}
+}
+
+func exists(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("exists", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	fsys, errObj := fileSystem(env, "exists")
+	if errObj != nil {
+		return errObj
+	}
+
+	ok, err := fsys.Exists(values[0])
+	if err != nil {
+		return object.NewError("exists: %s", err)
+	}
+	return nativeBoolToBooleanObject(ok)
+}
+
+func remove(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("remove", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	fsys, errObj := fileSystem(env, "remove")
+	if errObj != nil {
+		return errObj
+	}
+
+	if err := fsys.Remove(values[0]); err != nil {
+		return object.NewError("remove: %s", err)
+	}
+	return NULL
+}
+
+func pathJoin(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("path_join", args, len(args))
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: path.Join(values...)}
+}
+
+func pathBase(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("path_base", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: path.Base(values[0])}
+}
+
+func pathDir(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("path_dir", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: path.Dir(values[0])}
+}
+
+func pathExt(env *object.Environment, args ...object.Object) object.Object {
+	values, errObj := stringArgs("path_ext", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: path.Ext(values[0])}
+}
@@ -0,0 +1,55 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/filesystem"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/parser"
+)
+
+func testEvalWithFS(input string, fsys filesystem.FS) object.Object {
+	env := object.NewEnvironment()
+	env.SetFS(fsys)
+	return evaluator.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestFileBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`write_file("a.txt", "hello"); read_file("a.txt")`, "hello"},
+		{`write_file("a.txt", "a"); append_file("a.txt", "b"); read_file("a.txt")`, "ab"},
+		{`append_file("new.txt", "x"); read_file("new.txt")`, "x"},
+		{`write_file("dir/b.txt", ""); write_file("c.txt", ""); list_dir()`, []string{"c.txt", "dir"}},
+		{`write_file("dir/b.txt", ""); list_dir("dir")`, []string{"b.txt"}},
+		{`write_file("a.txt", ""); exists("a.txt")`, true},
+		{`exists("missing.txt")`, false},
+		{`write_file("a.txt", ""); remove("a.txt"); exists("a.txt")`, false},
+		{`read_file("missing.txt")`, "read_file: read missing.txt: file does not exist"},
+		{`read_file("../secret")`, "read_file: read ../secret: path is outside the filesystem root"},
+		{`write_file("/etc/passwd", "x")`, "write_file: write /etc/passwd: path is outside the filesystem root"},
+		{`read_file(1)`, "arguments to `read_file` must be STRING, got INTEGER"},
+		{`write_file("a.txt")`, "wrong number of arguments. got=1, want=2"},
+		{`path_join("a", "b/", "../c.txt")`, "a/c.txt"},
+		{`path_base("dir/file.tar.gz")`, "file.tar.gz"},
+		{`path_dir("dir/file.txt")`, "dir"},
+		{`path_ext("dir/file.tar.gz")`, ".gz"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEvalWithFS(tt.input, filesystem.NewMemory()), tt.expected)
+	}
+}
+
+func TestFileBuiltinsRequireFilesystem(t *testing.T) {
+	evaluated := testEval(`read_file("a.txt")`)
+	testObject(t, `read_file("a.txt")`, evaluated, "read_file: filesystem access is not enabled")
+
+	readOnly := filesystem.ReadOnly(filesystem.NewMemory())
+	evaluated = testEvalWithFS(`write_file("a.txt", "x")`, readOnly)
+	testObject(t, `write_file("a.txt", "x")`, evaluated, "write_file: write a.txt: filesystem is read-only")
+}
@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// hoistFunctions binds the functions declared by stmts in env before any of
+// the statements run, so that declarations in the same block can call each
+// other whatever their order. It returns an error if a declaration would
+// replace a constant, including one declared later in the same block.
+func hoistFunctions(stmts []ast.Statement, env *object.Environment) *object.Error {
+	consts := map[string]bool{}
+	for _, stmt := range stmts {
+		if let, ok := stmt.(*ast.LetStatement); ok && let.Const {
+			for _, name := range letNames(let) {
+				consts[name.Value] = true
+			}
+		}
+	}
+
+	for _, stmt := range stmts {
+		decl, ok := stmt.(*ast.FunctionStatement)
+		if !ok {
+			continue
+		}
+		if consts[decl.Name.Value] {
+			return object.NewError("cannot redeclare constant %s", decl.Name.Value)
+		}
+		if errObj := checkRedeclaration(decl.Name.Value, env); errObj != nil {
+			return errObj
+		}
+		fn := Eval(decl.Function, env).(*object.Function)
+		fn.Name = decl.Name.Value
+		env.Set(decl.Name.Value, fn)
+	}
+	return nil
+}
+
+// withFrame adds the call of fn at call to the trace of result if result is
+// an error raised inside a Jian function, or inside the init method of a
+// class being instantiated.
+func withFrame(result object.Object, fn object.Object, call *ast.CallExpression) object.Object {
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		return result
+	}
+	if class, ok := fn.(*object.Class); ok {
+		fn, _ = class.Method("init")
+	}
+	function, ok := fn.(*object.Function)
+	if !ok || function == nil {
+		return result
+	}
+	return errObj.WithFrame(object.Frame{
+		Function: function.Name,
+		Line:     call.Token.Line,
+		Column:   call.Token.Column,
+	})
+}
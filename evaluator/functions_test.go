@@ -0,0 +1,92 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestFunctionDeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(a, b) { a + b } add(1, 2)", "3"},
+		{"let r = add(1, 2); fn add(a, b) { a + b } r", "3"},
+		{"fn even(n) { if (n == 0) { true } else { odd(n - 1) } } fn odd(n) { if (n == 0) { false } else { even(n - 1) } } even(10)", "true"},
+		{"let f = fn() { let r = g() + 1; fn g() { 41 } r }; f()", "42"},
+		{"fn fact(n) { if (n < 2) { 1 } else { n * fact(n - 1) } } fact(5)", "120"},
+		{"fn add(a, b) { a + b }", "<nil>"},
+		{"fn add(a, b) { a + b } add", "<fn add/2>"},
+		{"fn log(level, ...parts) { parts } log", "<fn log/1+>"},
+		{"let square = fn(x) { x * x }; square", "<fn square/1>"},
+		{"let f = fn() { 1 }; let g = f; g", "<fn f/0>"},
+		{"fn() { 1 }", "<fn/0>"},
+		{"let fns = [fn(x) { x }]; fns[0]", "<fn/1>"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		got := "<nil>"
+		if evaluated != nil {
+			got = evaluated.Inspect()
+		}
+		if _, isErr := evaluated.(*object.Error); isErr || got != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%s)", tt.input, tt.expected, evaluated, got)
+		}
+	}
+}
+
+func TestFunctionErrorNames(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		traceback string
+	}{
+		{
+			"fn add(a, b) { a + b } add(1)",
+			"add: invalid argument length; expected 2 arguments, got 1",
+			"add: invalid argument length; expected 2 arguments, got 1\n    at add (1:27)",
+		},
+		{
+			"let f = fn(a) { a }; f(b: 1)",
+			"f: unexpected keyword argument `b`",
+			"f: unexpected keyword argument `b`\n    at f (1:23)",
+		},
+		{
+			"fn inner() { 1 + true }\nfn outer() {\n  inner() + 1\n}\nouter()",
+			"type mismatch: INTEGER + BOOLEAN",
+			"type mismatch: INTEGER + BOOLEAN\n    at inner (3:8)\n    at outer (5:6)",
+		},
+		{
+			"fn check(n) { assert(n > 1) }\nfn run() { check(1) }\nrun()",
+			"assertion failed",
+			"assertion failed\n    at check (2:17)\n    at run (3:4)",
+		},
+		{
+			"fn g() { 1 + true }\nfn f() {\n  g();\n  1\n}\nf()",
+			"type mismatch: INTEGER + BOOLEAN",
+			"type mismatch: INTEGER + BOOLEAN\n    at g (3:4)\n    at f (6:2)",
+		},
+		{
+			"fn(x) { len(x, x) }(1)",
+			"wrong number of arguments. got=2, want=1",
+			"wrong number of arguments. got=2, want=1\n    at <anonymous> (1:20)",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%q: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%q: expected message %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+		if got := errObj.Traceback(); got != tt.traceback {
+			t.Errorf("%q: expected traceback %q, got %q", tt.input, tt.traceback, got)
+		}
+	}
+}
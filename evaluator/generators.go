@@ -0,0 +1,297 @@
+package evaluator
+
+import (
+	"sync"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// DONE is what next returns once a generator has no values left.
+var DONE = &object.Done{}
+
+// generatorStopped is the panic that unwinds the goroutine of a generator
+// function stopped while it waits at a yield.
+type generatorStopped struct{}
+
+// newGenerator returns the generator made by calling fn, a generator
+// function, with its parameters bound in env. The body runs on its own
+// goroutine, started when the first value is asked for. The goroutine hands
+// each yielded value over and then waits until the next value is asked for,
+// so the body and its caller never run at the same time. Stopping the
+// generator while the body waits makes the yield unwind the goroutine.
+func newGenerator(fn *object.Function, env *object.Environment) *object.Generator {
+	values := make(chan object.Object)
+	resume := make(chan struct{})
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	started := false
+
+	env.SetYield(func(value object.Object) object.Object {
+		if errObj := checkType("yielded value", value, fn.ReturnType, fn.Env); errObj != nil {
+			return argumentError(fn, "%s", errObj.Message)
+		}
+		select {
+		case values <- value:
+		case <-stop:
+			panic(generatorStopped{})
+		}
+		select {
+		case <-resume:
+			return nil
+		case <-stop:
+			panic(generatorStopped{})
+		}
+	})
+
+	run := func() {
+		defer close(values)
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(generatorStopped); !ok {
+					panic(r)
+				}
+			}
+		}()
+
+		if result := unwrapReturnValue(Eval(fn.Body, env)); isError(result) {
+			select {
+			case values <- result:
+			case <-stop:
+			}
+		}
+	}
+
+	next := func() (object.Object, bool) {
+		if !started {
+			started = true
+			go run()
+		} else {
+			select {
+			case resume <- struct{}{}:
+			case <-stop:
+				return nil, false
+			}
+		}
+		value, ok := <-values
+		return value, ok
+	}
+
+	return object.NewGenerator(fn.Name, next, func() {
+		stopOnce.Do(func() { close(stop) })
+	})
+}
+
+// evalYieldStatement hands the value of ys to the caller of the generator
+// running env, and returns once the generator is asked for its next value.
+func evalYieldStatement(ys *ast.YieldStatement, env *object.Environment) object.Object {
+	yield, ok := env.Yield()
+	if !ok {
+		return object.NewError("yield outside of a generator")
+	}
+	value := Eval(ys.Value, env)
+	if isError(value) {
+		return value
+	}
+	return yield(value)
+}
+
+// iterate returns a generator over the values of arg, which must be a
+// generator or an array.
+func iterate(name string, arg object.Object) (*object.Generator, *object.Error) {
+	switch arg := arg.(type) {
+	case *object.Generator:
+		return arg, nil
+	case *object.Array:
+		i := 0
+		return object.NewGenerator("", func() (object.Object, bool) {
+			if i >= len(arg.Elements) {
+				return nil, false
+			}
+			i++
+			return arg.Elements[i-1], true
+		}, nil), nil
+	default:
+		return nil, object.NewError("argument to `%s` must be GENERATOR or ARRAY, got %s", name, arg.Type())
+	}
+}
+
+func next(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	gen, ok := args[0].(*object.Generator)
+	if !ok {
+		return object.NewError("argument to `next` must be GENERATOR, got %s", args[0].Type())
+	}
+
+	if value, ok := gen.Next(); ok {
+		return value
+	}
+	return DONE
+}
+
+func isDone(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+	return nativeBoolToBooleanObject(args[0] == DONE)
+}
+
+// collect returns the remaining values of a generator as an array.
+func collect(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	gen, errObj := iterate("collect", args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	elements := []object.Object{}
+	for {
+		value, ok := gen.Next()
+		if !ok {
+			return &object.Array{Elements: elements}
+		}
+		if isError(value) {
+			return value
+		}
+		elements = append(elements, value)
+	}
+}
+
+// restOfGenerator returns a generator over the values of gen after its
+// next one, which it skips when it is first asked for a value.
+func restOfGenerator(gen *object.Generator) *object.Generator {
+	skipped := false
+	return object.NewGenerator("", func() (object.Object, bool) {
+		if !skipped {
+			skipped = true
+			if value, ok := gen.Next(); !ok || isError(value) {
+				return value, ok
+			}
+		}
+		return gen.Next()
+	}, nil)
+}
+
+// lengthOfGenerator counts the remaining values of gen, using them up.
+func lengthOfGenerator(gen *object.Generator) object.Object {
+	var n int64
+	for {
+		value, ok := gen.Next()
+		if !ok {
+			return &object.Integer{Value: n}
+		}
+		if isError(value) {
+			return value
+		}
+		n++
+	}
+}
+
+// take returns a generator over at most the first n values of a generator
+// or array.
+func take(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	gen, errObj := iterate("take", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	n, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewError("second argument to `take` must be INTEGER, got %s", args[1].Type())
+	}
+
+	left := n.Value
+	return object.NewGenerator("", func() (object.Object, bool) {
+		if left <= 0 {
+			return nil, false
+		}
+		left--
+		return gen.Next()
+	}, nil)
+}
+
+// mapIter returns a generator over the results of calling fn on each value
+// of a generator or array, calling it only as the values are asked for.
+func mapIter(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	gen, errObj := iterate("map_iter", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	fn := args[1]
+
+	return object.NewGenerator("", func() (object.Object, bool) {
+		value, ok := gen.Next()
+		if !ok || isError(value) {
+			return value, ok
+		}
+		return applyFunction(fn, []object.Object{value}, env), true
+	}, nil)
+}
+
+// filterIter returns a generator over the values of a generator or array
+// for which fn returns a truthy value.
+func filterIter(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=2", got)
+	}
+
+	gen, errObj := iterate("filter_iter", args[0])
+	if errObj != nil {
+		return errObj
+	}
+	fn := args[1]
+
+	return object.NewGenerator("", func() (object.Object, bool) {
+		for {
+			value, ok := gen.Next()
+			if !ok || isError(value) {
+				return value, ok
+			}
+			keep := applyFunction(fn, []object.Object{value}, env)
+			if isError(keep) {
+				return keep, true
+			}
+			if isTruthy(keep) {
+				return value, true
+			}
+		}
+	}, nil)
+}
+
+// countFrom returns a generator that counts up from start, or from 0, in
+// steps of step, or of 1, without end.
+func countFrom(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got > 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=0 to 2", got)
+	}
+
+	bounds := []int64{0, 1}
+	for i, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return object.NewError("argument to `count_from` must be INTEGER, got %s", arg.Type())
+		}
+		bounds[i] = n.Value
+	}
+
+	var current object.Object = &object.Integer{Value: bounds[0]}
+	step := &object.Integer{Value: bounds[1]}
+	return object.NewGenerator("", func() (object.Object, bool) {
+		value := current
+		current = evalInfixExpression(current, "+", step)
+		return value, true
+	}, nil)
+}
@@ -0,0 +1,127 @@
+package evaluator_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn gen() { yield 1; yield 2; } let g = gen(); [next(g), next(g), next(g)]", "[1, 2, done]"},
+		{"fn gen() { yield 1; } let g = gen(); next(g); next(g); next(g)", "done"},
+		{"fn gen() { yield 1; } let g = gen(); next(g); is_done(next(g))", "true"},
+		{"is_done(1)", "false"},
+		{"fn gen() { yield 1; } gen()", "<generator gen>"},
+		{"fn() { yield 1; }()", "<generator>"},
+		{"fn gen() { yield 1; } type_of(gen())", "GENERATOR"},
+		{"fn gen(n) { if (n > 0) { yield n; } } collect(gen(0))", "[]"},
+		{"fn gen(a, b = 2) { yield a; yield b; } collect(gen(b: 3, a: 1))", "[1, 3]"},
+		{"fn gen() { yield 1; return 5; yield 2; } collect(gen())", "[1]"},
+		{"fn gen() { let x = 1; yield x; let x = x + 1; yield x; } collect(gen())", "[1, 2]"},
+		{"let xs = []; fn gen() { yield 1; yield 2; } let g = gen(); let a = next(g); [a, collect(g)]", "[1, [2]]"},
+		{"let log = []; fn gen() { log[0] = 1; yield 1; } let g = gen(); log", "[]"},
+		{"fn outer() { let inner = fn() { yield 1; }; inner } type_of(outer())", "FUNCTION"},
+		{"class Range { fn init(n) { self.n = n } fn values() { yield 0; yield self.n; } } collect(Range(3).values())", "[0, 3]"},
+		{"fn gen() { yield 1; yield 2; yield 3; } len(gen())", "3"},
+		{"fn gen() { yield 1; yield 2; } first(gen())", "1"},
+		{"fn gen() { yield 1; } let g = gen(); next(g); first(g)", "null"},
+		{"fn gen() { yield 1; yield 2; yield 3; } collect(rest(gen()))", "[2, 3]"},
+		{"fn gen() { yield 1; yield 2; } let g = gen(); let r = rest(g); next(g)", "1"},
+		{"collect(take(count_from(), 3))", "[0, 1, 2]"},
+		{"collect(take(count_from(5, -2), 4))", "[5, 3, 1, -1]"},
+		{"collect(take(count_from(9223372036854775806), 3))", "[9223372036854775806, 9223372036854775807, 9223372036854775808]"},
+		{"collect(take([1, 2, 3], 2))", "[1, 2]"},
+		{"collect(take([1, 2], 5))", "[1, 2]"},
+		{"collect(take(count_from(), 0))", "[]"},
+		{"collect(map_iter([1, 2, 3], fn(x) { x * 10 }))", "[10, 20, 30]"},
+		{"collect(filter_iter([1, 2, 3, 4], fn(x) { x > 2 }))", "[3, 4]"},
+		{"collect(take(filter_iter(map_iter(count_from(1), fn(x) { x * x }), fn(x) { x > 10 }), 2))", "[16, 25]"},
+		{"let calls = [0]; let m = map_iter(count_from(), fn(x) { calls[0] = calls[0] + 1; x }); next(m); next(m); calls[0]", "2"},
+		{"fn naturals() { let n = [0]; let bump = fn() { n[0] = n[0] + 1; n[0] }; yield bump(); yield bump(); } collect(naturals())", "[1, 2]"},
+		{"collect([1, 2])", "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"yield 1;", "yield outside of a generator"},
+		{"fn gen() { yield 1; 1 / 0; } let g = gen(); next(g); next(g)", "division by zero"},
+		{"fn gen() { yield 1 / 0; } collect(gen())", "division by zero"},
+		{"fn gen(a) { yield a; } gen()", "gen: invalid argument length; expected 1 arguments, got 0"},
+		{"let g = fn() { yield next(g); }(); next(g)", "generator is already running"},
+		{"fn gen() { yield 1; yield true + 1; } len(gen())", "type mismatch: BOOLEAN + INTEGER"},
+		{"next([1])", "argument to `next` must be GENERATOR, got ARRAY"},
+		{"collect(1)", "argument to `collect` must be GENERATOR or ARRAY, got INTEGER"},
+		{"take(count_from(), true)", "second argument to `take` must be INTEGER, got BOOLEAN"},
+		{"collect(map_iter([1], fn(x) { x + true }))", "type mismatch: INTEGER + BOOLEAN"},
+		{"collect(filter_iter([1], 5))", "not a function: INTEGER"},
+		{`count_from("a")`, "argument to `count_from` must be INTEGER, got STRING"},
+		{"first(1)", "argument to `first` must be ARRAY or GENERATOR, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestStrictGeneratorsCheckYieldedValues(t *testing.T) {
+	evaluated := testStrictEval(`fn gen() -> int { yield 1; yield "two"; } collect(gen())`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("expected Error, got %T (%+v)", evaluated, evaluated)
+	}
+	if want := "gen: type error: yielded value must be int, got string"; errObj.Message != want {
+		t.Errorf("expected %q, got %q", want, errObj.Message)
+	}
+
+	if got := testStrictEval(`fn gen() -> int { yield 1; } let n = gen(); collect(n)`).Inspect(); got != "[1]" {
+		t.Errorf("expected a generator function's call to pass its return type check, got %s", got)
+	}
+}
+
+func TestAbandonedGeneratorsDoNotLeakGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	testEval(`
+fn numbers() { yield 1; yield 2; yield 3; }
+let start = fn() { let g = numbers(); next(g) };
+let loop = fn(i) { if (i > 0) { start(); loop(i - 1) } };
+loop(100);
+let pipeline = fn() { next(map_iter(numbers(), fn(x) { x * 2 })) };
+let again = fn(i) { if (i > 0) { pipeline(); again(i - 1) } };
+again(100);
+`)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected abandoned generators to be stopped, %d goroutines left over", after-before)
+	}
+}
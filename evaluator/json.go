@@ -0,0 +1,207 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ekediala/jian/object"
+)
+
+func jsonParse(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 {
+		return object.NewError("wrong number of arguments. got=%d, want=1", got)
+	}
+
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewError("argument to `json_parse` must be STRING, got %s", args[0].Type())
+	}
+
+	dec := json.NewDecoder(strings.NewReader(str.Value))
+	dec.UseNumber()
+
+	value, err := decodeJSON(dec)
+	if err == nil {
+		if _, trailing := dec.Token(); trailing != io.EOF {
+			err = errors.New("unexpected data after top-level value")
+		}
+	}
+	if err != nil {
+		return object.NewError("json_parse: %s", err)
+	}
+
+	return value
+}
+
+// decodeJSON reads one value from the token stream. Objects are read key by
+// key rather than through a Go map so that their order is preserved.
+func decodeJSON(dec *json.Decoder) (object.Object, error) {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			elements := []object.Object{}
+			for dec.More() {
+				el, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, el)
+			}
+			_, err := dec.Token() // consume ]
+			return &object.Array{Elements: elements}, err
+		}
+
+		hash := object.NewHash(0)
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := &object.String{Value: keyTok.(string)}
+
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(key, value)
+		}
+		_, err := dec.Token() // consume }
+		return hash, err
+
+	case json.Number:
+		if n, err := strconv.ParseInt(tok.String(), 10, 64); err == nil {
+			return &object.Integer{Value: n}, nil
+		}
+		if n, ok := new(big.Int).SetString(tok.String(), 10); ok {
+			return object.NewBigInteger(n), nil
+		}
+		// Numbers with a fraction or an exponent become floats.
+		f, err := strconv.ParseFloat(tok.String(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("number %s is out of range", tok)
+		}
+		return &object.Float{Value: f}, nil
+
+	case string:
+		return &object.String{Value: tok}, nil
+
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+
+	default:
+		return NULL, nil
+	}
+}
+
+func jsonStringify(env *object.Environment, args ...object.Object) object.Object {
+	if got := len(args); got != 1 && got != 2 {
+		return object.NewError("wrong number of arguments. got=%d, want=1 or 2", got)
+	}
+
+	indent := ""
+	if len(args) == 2 {
+		switch arg := args[1].(type) {
+		case *object.Integer:
+			if arg.Value < 0 {
+				return object.NewError("json_stringify: indent must not be negative, got %d", arg.Value)
+			}
+			indent = strings.Repeat(" ", int(arg.Value))
+		case *object.String:
+			indent = arg.Value
+		default:
+			return object.NewError("second argument to `json_stringify` must be INTEGER or STRING, got %s", arg.Type())
+		}
+	}
+
+	value, err := toJSONValue(args[0], "$", map[object.Object]bool{})
+	if err != nil {
+		return object.NewError("json_stringify: %s", err)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(value); err != nil {
+		return object.NewError("json_stringify: %s", err)
+	}
+
+	return &object.String{Value: strings.TrimSuffix(buf.String(), "\n")}
+}
+
+// toJSONValue converts obj into a value encoding/json understands. Hashes
+// become Go maps, which the encoder writes with their keys sorted. path
+// locates obj inside the top-level value for error messages, and visiting
+// holds the arrays and hashes along it, which JSON cannot nest inside
+// themselves.
+func toJSONValue(obj object.Object, path string, visiting map[object.Object]bool) (interface{}, error) {
+	switch obj.(type) {
+	case *object.Array, *object.Hash:
+		if visiting[obj] {
+			return nil, fmt.Errorf("%s at %s contains itself", obj.Type(), path)
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+	}
+
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.BigInteger:
+		return json.Number(obj.Value.String()), nil
+	case *object.Float:
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return nil, fmt.Errorf("cannot encode %s at %s", obj.Inspect(), path)
+		}
+		// Inspect keeps the decimal point, so that the float decodes as a
+		// float again.
+		return json.Number(obj.Inspect()), nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		elements := make([]interface{}, 0, len(obj.Elements))
+		for i, el := range obj.Elements {
+			value, err := toJSONValue(el, fmt.Sprintf("%s[%d]", path, i), visiting)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, value)
+		}
+		return elements, nil
+	case *object.Hash:
+		pairs := make(map[string]interface{}, obj.Len())
+		for _, pair := range obj.OrderedPairs() {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, fmt.Errorf("hash key %s at %s must be STRING, got %s", pair.Key.Inspect(), path, pair.Key.Type())
+			}
+			value, err := toJSONValue(pair.Value, path+"["+strconv.Quote(key.Value)+"]", visiting)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key.Value] = value
+		}
+		return pairs, nil
+	default:
+		return nil, fmt.Errorf("cannot encode %s at %s", obj.Type(), path)
+	}
+}
@@ -0,0 +1,133 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/parser"
+)
+
+// testEvalJSON evaluates input with the string doc bound to `doc`, since Jian
+// string literals cannot contain double quotes.
+func testEvalJSON(input, doc string) object.Object {
+	env := object.NewEnvironment()
+	env.Set("doc", &object.String{Value: doc})
+	return evaluator.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestJSONParse(t *testing.T) {
+	tests := []struct {
+		doc      string
+		input    string
+		expected string
+	}{
+		{`42`, `json_parse(doc)`, "42"},
+		{`-7`, `json_parse(doc)`, "-7"},
+		{`"hi"`, `json_parse(doc)`, "hi"},
+		{`"café"`, `json_parse(doc)`, "café"},
+		{`true`, `json_parse(doc)`, "true"},
+		{`null`, `json_parse(doc)`, "null"},
+		{`[1, [2, []], "x"]`, `json_parse(doc)`, "[1, [2, []], x]"},
+		{`{"a": {"b": [true, null]}}`, `json_parse(doc)["a"]["b"]`, "[true, null]"},
+		{` [ 1 , 2 ] `, `len(json_parse(doc))`, "2"},
+		{`1.5`, `json_parse(doc)`, "1.5"},
+		{`-2.0`, `json_parse(doc)`, "-2.0"},
+		{`1e3`, `json_parse(doc)`, "1000.0"},
+		{`2.5e-3`, `json_parse(doc)`, "0.0025"},
+		{`{"price": 1.25, "count": 2.0, "tax": 0.5}`, `let p = json_parse(doc); p["price"] * p["count"] + p["tax"]`, "3.0"},
+		{`[1.5, 3.0]`, `let p = json_parse(doc); [p[0] < p[1], -p[0], p[1] / p[0] - p[0]]`, "[true, -1.5, 0.5]"},
+		{`123456789012345678901234567890`, `json_parse(doc) / 10`, "12345678901234567890123456789"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalJSON(tt.input, tt.doc)
+		if _, ok := evaluated.(*object.Error); ok || evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %T (%+v)", tt.doc, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_stringify(1)`, `1`},
+		{`json_stringify([9223372036854775807 * 10])`, `[92233720368547758070]`},
+		{`json_stringify(doc)`, `"say \"hi\" <b>"`},
+		{`json_stringify([1, true, first([]), "x"])`, `[1,true,null,"x"]`},
+		{`json_stringify({"b": 1, "a": [2], "c": {"z": 1, "y": 2}})`, `{"a":[2],"b":1,"c":{"y":2,"z":1}}`},
+		{`json_stringify({"a": [1, 2]}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{`json_stringify([1], "    ")`, "[\n    1\n]"},
+		{`json_stringify({})`, `{}`},
+		{`json_stringify([])`, `[]`},
+		{`let xs = [1]; json_stringify({"a": xs, "b": [xs, xs]})`, `{"a":[1],"b":[[1],[1]]}`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalJSON(tt.input, `say "hi" <b>`)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("%s: expected String, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, str.Value)
+		}
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		doc      string
+		input    string
+		expected string
+	}{
+		{`[1,`, `json_parse(doc)`, "json_parse: unexpected end of JSON input"},
+		{``, `json_parse(doc)`, "json_parse: unexpected EOF"},
+		{`{"a" 1}`, `json_parse(doc)`, "json_parse: invalid character '1' after object key"},
+		{`1 2`, `json_parse(doc)`, "json_parse: unexpected data after top-level value"},
+		{`1e400`, `json_parse(doc)`, "json_parse: number 1e400 is out of range"},
+		{`[1.5, 0.0]`, `let p = json_parse(doc); p[0] / p[1]`, "division by zero"},
+		{`1.5`, `json_parse(doc) + 1`, "type mismatch: FLOAT + INTEGER"},
+		{``, `json_parse(1)`, "argument to `json_parse` must be STRING, got INTEGER"},
+		{``, `json_stringify(fn(x) { x })`, "json_stringify: cannot encode FUNCTION at $"},
+		{``, `json_stringify({"handlers": [1, len]})`, `json_stringify: cannot encode BUILTIN at $["handlers"][1]`},
+		{``, `json_stringify({1: "one"})`, "json_stringify: hash key 1 at $ must be STRING, got INTEGER"},
+		{``, `let h = {}; h["self"] = h; json_stringify(h)`, `json_stringify: HASH at $["self"] contains itself`},
+		{``, `let xs = [1, 2]; xs[1] = [xs]; json_stringify(xs)`, "json_stringify: ARRAY at $[1][0] contains itself"},
+		{``, `json_stringify(1, -1)`, "json_stringify: indent must not be negative, got -1"},
+		{``, `json_stringify(1, true)`, "second argument to `json_stringify` must be INTEGER or STRING, got BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalJSON(tt.input, tt.doc)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	inputs := []string{
+		`let v = {"name": "jian", "tags": ["a", "b"], "meta": {"version": 2, "stable": false, "parent": first([])}};
+		assert_eq(json_parse(json_stringify(v)), v)`,
+		`let v = [[[]], [{}], [{"deep": [1, [2, [3]]]}]];
+		assert_eq(json_parse(json_stringify(v, 2)), v)`,
+		`assert_eq(json_stringify(json_parse(doc)), doc)`,
+		`let v = json_parse("[1.5, 2.0, 1e+21, 3]");
+		assert_eq(json_stringify(v), "[1.5,2.0,1e+21,3]");
+		assert_eq(json_parse(json_stringify(v)), v)`,
+	}
+
+	for _, input := range inputs {
+		testNullObject(t, testEvalJSON(input, `{"a":[1,{"b":null}],"c":"é","d":{"e":[[],{}]}}`))
+	}
+}
@@ -0,0 +1,125 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// DefineMacros removes the top-level `let name = macro(...) { ... }`
+// statements from program and binds the macros they define in env.
+func DefineMacros(program *ast.Program, env *object.Environment) {
+	statements := program.Statements[:0]
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			statements = append(statements, stmt)
+			continue
+		}
+		literal, ok := let.Value.(*ast.MacroLiteral)
+		if !ok || let.Name == nil {
+			statements = append(statements, stmt)
+			continue
+		}
+
+		env.Set(let.Name.Value, &object.Macro{
+			Parameters: literal.Parameters,
+			Body:       literal.Body,
+			Env:        env,
+		})
+	}
+	program.Statements = statements
+}
+
+// maxMacroDepth bounds how many times the code produced by a macro may be
+// expanded again, so a macro that expands to a call to itself fails instead
+// of recursing forever.
+const maxMacroDepth = 100
+
+// ExpandMacros replaces every call to a macro bound in env with the code the
+// macro returns for it. The arguments of a call are passed to the macro as
+// quoted, unevaluated code, and macro calls in the returned code are expanded
+// in turn. program itself is not modified.
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, error) {
+	return expandMacros(program, env, 0)
+}
+
+func expandMacros(program ast.Node, env *object.Environment, depth int) (ast.Node, error) {
+	if depth > maxMacroDepth {
+		return nil, fmt.Errorf("macro expansion exceeded %d levels", maxMacroDepth)
+	}
+
+	var err error
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || err != nil {
+			return node
+		}
+		macro, ok := macroFor(call, env)
+		if !ok {
+			return node
+		}
+
+		var replacement ast.Node
+		replacement, err = expandMacroCall(call, macro)
+		if err == nil {
+			replacement, err = expandMacros(replacement, env, depth+1)
+		}
+		if err != nil {
+			return node
+		}
+		return replacement
+	})
+
+	return expanded, err
+}
+
+func macroFor(call *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+
+	obj, ok := env.Get(ident.Value)
+	if !ok {
+		return nil, false
+	}
+
+	macro, ok := obj.(*object.Macro)
+	return macro, ok
+}
+
+func expandMacroCall(call *ast.CallExpression, macro *object.Macro) (ast.Node, error) {
+	name := call.Function.String()
+	for _, arg := range call.Arguments {
+		switch arg.(type) {
+		case *ast.KeywordArgument, *ast.SpreadExpression:
+			return nil, fmt.Errorf("macro `%s` does not accept keyword or spread arguments", name)
+		}
+	}
+	if got, want := len(call.Arguments), len(macro.Parameters); got != want {
+		return nil, fmt.Errorf("wrong number of arguments to macro `%s`. got=%d, want=%d", name, got, want)
+	}
+
+	env := object.NewEnclosedEnvironment(macro.Env)
+	for i, param := range macro.Parameters {
+		env.Set(param.Value, &object.Quote{Node: call.Arguments[i]})
+	}
+
+	evaluated := unwrapReturnValue(Eval(macro.Body, env))
+	if errObj, ok := evaluated.(*object.Error); ok {
+		return nil, errors.New(errObj.Message)
+	}
+
+	quote, ok := evaluated.(*object.Quote)
+	if !ok {
+		got := "nothing"
+		if evaluated != nil {
+			got = string(evaluated.Type())
+		}
+		return nil, fmt.Errorf("macro `%s` must return QUOTE, got %s", name, got)
+	}
+	return quote.Node, nil
+}
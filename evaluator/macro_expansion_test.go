@@ -0,0 +1,262 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/parser"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(5)`, `5`},
+		{`quote(5 + 8)`, `(5 + 8)`},
+		{`quote(foobar)`, `foobar`},
+		{`quote(foobar + barfoo)`, `(foobar + barfoo)`},
+		{`quote(unquote(4))`, `4`},
+		{`quote(unquote(4 + 4))`, `8`},
+		{`quote(8 + unquote(4 + 4))`, `(8 + 8)`},
+		{`quote(unquote(4 + 4) + 8)`, `(8 + 8)`},
+		{`let foobar = 8; quote(foobar)`, `foobar`},
+		{`let foobar = 8; quote(unquote(foobar))`, `8`},
+		{`quote(unquote(true))`, `true`},
+		{`quote(unquote(true == false))`, `false`},
+		{`quote(unquote("hi"))`, `hi`},
+		{`quote(unquote([1, [true]]))`, `[1, [true]]`},
+		{`quote(unquote(9223372036854775807 + 1))`, `9223372036854775808`},
+		{`quote(unquote(quote(4 + 4)))`, `(4 + 4)`},
+		{`let quotedInfix = quote(4 + 4); quote(unquote(4 + 4) + unquote(quotedInfix))`, `(8 + (4 + 4))`},
+		{`let f = fn(x) { quote(unquote(x) + 1) }; f(1); f(2)`, `(2 + 1)`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			t.Fatalf("%s: expected *object.Quote. got=%T (%+v)", tt.input, evaluated, evaluated)
+		}
+		if quote.Node == nil {
+			t.Fatalf("%s: quote.Node is nil", tt.input)
+		}
+		if quote.Node.String() != tt.expected {
+			t.Errorf("%s: not equal. got=%q, want=%q", tt.input, quote.Node.String(), tt.expected)
+		}
+	}
+}
+
+func TestQuoteErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(1, 2)`, "wrong number of arguments to `quote`. got=2, want=1"},
+		{`quote(unquote())`, "wrong number of arguments to `unquote`. got=0, want=1"},
+		{`quote(unquote(1 + true))`, "type mismatch: INTEGER + BOOLEAN"},
+		{`quote(unquote(fn(x) { x }))`, "cannot unquote FUNCTION"},
+		{`quote(unquote([len]))`, "cannot unquote ARRAY"},
+		{`let a = [1]; a[0] = a; quote(unquote(a))`, "cannot unquote ARRAY that contains itself"},
+		{`let a = [1, 2]; a[1] = [a]; quote(unquote(a))`, "cannot unquote ARRAY that contains itself"},
+		{`let m = macro(x) { x }; m`, "macros must be defined with a top-level let statement"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestDefineMacros(t *testing.T) {
+	input := `
+	let number = 1;
+	let function = fn(x, y) { x + y };
+	let mymacro = macro(x, y) { x + y; };
+	`
+
+	env := object.NewEnvironment()
+	program := testParseProgram(input)
+
+	evaluator.DefineMacros(program, env)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("Wrong number of statements. got=%d", len(program.Statements))
+	}
+	if _, ok := env.Get("number"); ok {
+		t.Fatalf("number should not be defined")
+	}
+	if _, ok := env.Get("function"); ok {
+		t.Fatalf("function should not be defined")
+	}
+
+	obj, ok := env.Get("mymacro")
+	if !ok {
+		t.Fatalf("macro not in environment.")
+	}
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		t.Fatalf("object is not Macro. got=%T (%+v)", obj, obj)
+	}
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("Wrong number of macro parameters. got=%d", len(macro.Parameters))
+	}
+	if macro.Parameters[0].String() != "x" || macro.Parameters[1].String() != "y" {
+		t.Fatalf("parameters wrong. got=%v", macro.Parameters)
+	}
+	if expected := "(x + y)"; macro.Body.String() != expected {
+		t.Fatalf("body is not %q. got=%q", expected, macro.Body.String())
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`
+			let infixExpression = macro() { quote(1 + 2); };
+			infixExpression();
+			`,
+			`(1 + 2)`,
+		},
+		{
+			`
+			let reverse = macro(a, b) { quote(unquote(b) - unquote(a)); };
+			reverse(2 + 2, 10 - 5);
+			`,
+			`(10 - 5) - (2 + 2)`,
+		},
+		{
+			`
+			let unless = macro(condition, consequence, alternative) {
+				quote(if (!(unquote(condition))) {
+					unquote(consequence);
+				} else {
+					unquote(alternative);
+				});
+			};
+			unless(10 > 5, puts("not greater"), puts("greater"));
+			`,
+			`if (!(10 > 5)) { puts("not greater") } else { puts("greater") }`,
+		},
+		{
+			`
+			let double = macro(x) { quote(unquote(x) * 2); };
+			let quadruple = macro(x) { quote(double(double(unquote(x)))); };
+			quadruple(a);
+			`,
+			`((a * 2) * 2)`,
+		},
+		{
+			`
+			let twice = macro(x) { quote([unquote(x), unquote(x)]); };
+			let f = fn() { [twice(1), twice(2)] };
+			`,
+			`let f = fn() { [[1, 1], [2, 2]] };`,
+		},
+	}
+
+	for _, tt := range tests {
+		expected := testParseProgram(tt.expected)
+		program := testParseProgram(tt.input)
+
+		env := object.NewEnvironment()
+		evaluator.DefineMacros(program, env)
+		expanded, err := evaluator.ExpandMacros(program, env)
+		if err != nil {
+			t.Fatalf("ExpandMacros returned error: %s", err)
+		}
+
+		if expanded.String() != expected.String() {
+			t.Errorf("not equal. want=%q, got=%q", expected.String(), expanded.String())
+		}
+	}
+}
+
+func TestExpandMacrosLeavesProgramUnchanged(t *testing.T) {
+	program := testParseProgram(`
+	let id = macro(x) { quote(unquote(x)); };
+	[id(1), id(2)];
+	`)
+
+	env := object.NewEnvironment()
+	evaluator.DefineMacros(program, env)
+	before := program.String()
+
+	if _, err := evaluator.ExpandMacros(program, env); err != nil {
+		t.Fatalf("ExpandMacros returned error: %s", err)
+	}
+	if program.String() != before {
+		t.Errorf("program was modified. want=%q, got=%q", before, program.String())
+	}
+}
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let m = macro(x) { quote(x) }; m()`, "wrong number of arguments to macro `m`. got=0, want=1"},
+		{`let m = macro() { 1 }; m()`, "macro `m` must return QUOTE, got INTEGER"},
+		{`let m = macro() { }; m()`, "macro `m` must return QUOTE, got nothing"},
+		{`let m = macro() { 1 + true }; m()`, "type mismatch: INTEGER + BOOLEAN"},
+		{`let m = macro() { quote(m()) }; m()`, "macro expansion exceeded 100 levels"},
+		{`let m = macro(x) { x }; m(x: 1)`, "macro `m` does not accept keyword or spread arguments"},
+	}
+
+	for _, tt := range tests {
+		program := testParseProgram(tt.input)
+		env := object.NewEnvironment()
+		evaluator.DefineMacros(program, env)
+
+		_, err := evaluator.ExpandMacros(program, env)
+		if err == nil {
+			t.Errorf("%s: expected error %q, got none", tt.input, tt.expected)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, err.Error())
+		}
+	}
+}
+
+func TestMacrosAtRuntime(t *testing.T) {
+	input := `
+	let unless = macro(condition, consequence, alternative) {
+		quote(if (!(unquote(condition))) { unquote(consequence) } else { unquote(alternative) });
+	};
+	let check = fn(n) { unless(n > 5, "small", "big") };
+	[check(1), check(10)]
+	`
+
+	program := testParseProgram(input)
+	env := object.NewEnvironment()
+	evaluator.DefineMacros(program, env)
+	expanded, err := evaluator.ExpandMacros(program, env)
+	if err != nil {
+		t.Fatalf("ExpandMacros returned error: %s", err)
+	}
+
+	evaluated := evaluator.Eval(expanded, env)
+	if got, want := evaluated.Inspect(), "[small, big]"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func testParseProgram(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
@@ -0,0 +1,230 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// evalMatchExpression evaluates the body of the first arm whose pattern
+// matches the subject and whose guard, if any, holds. The names the pattern
+// binds are visible to the guard and the body only. The body is evaluated
+// with eval, so that in tail position it can be evaluated with evalTail.
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment, eval func(ast.Node, *object.Environment) object.Object) object.Object {
+	subject := Eval(me.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		bindings := map[string]object.Object{}
+		m, errObj := matchPattern(arm.Pattern, subject, env, bindings, "")
+		if errObj != nil {
+			return errObj
+		}
+		if m != nil {
+			continue
+		}
+
+		armEnv := object.NewEnclosedEnvironment(env)
+		for name, value := range bindings {
+			armEnv.Set(name, value)
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		return eval(arm.Body, armEnv)
+	}
+
+	return object.NewError("no match arm matches %s", repr(subject))
+}
+
+// A mismatch describes why a value does not match a pattern.
+type mismatch struct {
+	path string        // where the value sits in the matched one, such as [0]["name"]
+	want string        // what the pattern expected there
+	got  object.Object // the value found there
+}
+
+func (m *mismatch) String() string {
+	if m.path == "" {
+		return fmt.Sprintf("expected %s, got %s", m.want, repr(m.got))
+	}
+	return fmt.Sprintf("expected %s at %s, got %s", m.want, m.path, repr(m.got))
+}
+
+// destructure binds the names pattern contains in env, as constants if
+// constant is set, or returns an error if value does not have the pattern's
+// shape.
+func destructure(pattern ast.Pattern, value object.Object, env *object.Environment, constant bool) object.Object {
+	m, errObj := bindPattern(pattern, value, env, constant)
+	if errObj != nil {
+		return errObj
+	}
+	if m != nil {
+		return object.NewError("cannot destructure %s: %s", pattern, m)
+	}
+	return nil
+}
+
+// bindPattern matches value against pattern and, if it matches, binds the
+// names the pattern contains in env, as constants if constant is set. It
+// returns why the value does not match, or an error raised while evaluating
+// a default value.
+func bindPattern(pattern ast.Pattern, value object.Object, env *object.Environment, constant bool) (*mismatch, object.Object) {
+	bindings := map[string]object.Object{}
+	m, errObj := matchPattern(pattern, value, env, bindings, "")
+	if m != nil || errObj != nil {
+		return m, errObj
+	}
+	for name, v := range bindings {
+		bind(env, name, v, constant)
+	}
+	return nil, nil
+}
+
+// matchPattern matches value, found at path within the value being matched,
+// against pattern and adds the names the pattern binds to bindings. It
+// returns why the value does not match, or an error raised while evaluating
+// a default value; bindings may hold partial results when either is set.
+func matchPattern(pattern ast.Pattern, value object.Object, env *object.Environment, bindings map[string]object.Object, path string) (*mismatch, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return nil, nil
+
+	case *ast.BindingPattern:
+		bindings[pattern.Name.Value] = value
+		return nil, nil
+
+	case *ast.LiteralPattern:
+		literal := Eval(pattern.Value, env)
+		if isError(literal) {
+			return nil, literal
+		}
+		if !object.Equal(literal, value) {
+			return &mismatch{path: path, want: repr(literal), got: value}, nil
+		}
+		return nil, nil
+
+	case *ast.DefaultPattern:
+		return matchPattern(pattern.Pattern, value, env, bindings, path)
+
+	case *ast.ArrayPattern:
+		arr, ok := value.(*object.Array)
+		if !ok {
+			return &mismatch{path: path, want: "an array", got: value}, nil
+		}
+		least, most := arrayPatternArity(pattern)
+		if n := len(arr.Elements); n < least || (most >= 0 && n > most) {
+			want := fmt.Sprintf("an array of %s elements", object.DescribeArity(least, most))
+			if least == 1 && most == 1 {
+				want = "an array of 1 element"
+			}
+			return &mismatch{path: path, want: want, got: value}, nil
+		}
+		for i, el := range pattern.Elements {
+			elPath := fmt.Sprintf("%s[%d]", path, i)
+			var m *mismatch
+			var errObj object.Object
+			if i < len(arr.Elements) {
+				m, errObj = matchPattern(el, arr.Elements[i], env, bindings, elPath)
+			} else {
+				m, errObj = matchDefault(el.(*ast.DefaultPattern), env, bindings, elPath)
+			}
+			if m != nil || errObj != nil {
+				return m, errObj
+			}
+		}
+		if pattern.Rest != nil && pattern.Rest.Value != "_" {
+			n := min(len(pattern.Elements), len(arr.Elements))
+			rest := make([]object.Object, len(arr.Elements)-n)
+			copy(rest, arr.Elements[n:])
+			bindings[pattern.Rest.Value] = &object.Array{Elements: rest}
+		}
+		return nil, nil
+
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return &mismatch{path: path, want: "a hash", got: value}, nil
+		}
+		for i, k := range pattern.Keys {
+			keyObj := Eval(k, env)
+			key, ok := asHashKey(keyObj)
+			if !ok {
+				return &mismatch{path: path, want: "a hash", got: value}, nil
+			}
+			elPath := fmt.Sprintf("%s[%s]", path, repr(keyObj))
+			var m *mismatch
+			var errObj object.Object
+			if v, ok := hash.Get(key); ok {
+				m, errObj = matchPattern(pattern.Values[i], v, env, bindings, elPath)
+			} else if def, ok := pattern.Values[i].(*ast.DefaultPattern); ok {
+				m, errObj = matchDefault(def, env, bindings, elPath)
+			} else {
+				m = &mismatch{path: path, want: "a hash with key " + repr(keyObj), got: value}
+			}
+			if m != nil || errObj != nil {
+				return m, errObj
+			}
+		}
+		return nil, nil
+
+	case *ast.AlternativePattern:
+		for _, alt := range pattern.Alternatives {
+			altBindings := map[string]object.Object{}
+			m, errObj := matchPattern(alt, value, env, altBindings, path)
+			if errObj != nil {
+				return nil, errObj
+			}
+			if m == nil {
+				for name, v := range altBindings {
+					bindings[name] = v
+				}
+				return nil, nil
+			}
+		}
+		return &mismatch{path: path, want: "a value matching " + pattern.String(), got: value}, nil
+
+	default:
+		return &mismatch{path: path, want: "a value matching " + pattern.String(), got: value}, nil
+	}
+}
+
+// matchDefault matches the default value of a missing element against the
+// element's pattern. The default can refer to the names bound before it.
+func matchDefault(el *ast.DefaultPattern, env *object.Environment, bindings map[string]object.Object, path string) (*mismatch, object.Object) {
+	scope := object.NewEnclosedEnvironment(env)
+	for name, v := range bindings {
+		scope.Set(name, v)
+	}
+	value := Eval(el.Default, scope)
+	if isError(value) {
+		return nil, value
+	}
+	return matchPattern(el.Pattern, value, env, bindings, path)
+}
+
+// arrayPatternArity returns the fewest and the most elements an array
+// matching pattern may have; max is -1 when the pattern has a rest element.
+func arrayPatternArity(pattern *ast.ArrayPattern) (min, max int) {
+	for _, el := range pattern.Elements {
+		if _, ok := el.(*ast.DefaultPattern); ok {
+			break
+		}
+		min++
+	}
+	if pattern.Rest != nil {
+		return min, -1
+	}
+	return min, len(pattern.Elements)
+}
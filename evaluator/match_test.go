@@ -0,0 +1,70 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (1) { 1 => 10, _ => 20 }", "10"},
+		{"match (3) { 1 => 10, _ => 20 }", "20"},
+		{`match ("b") { "a" => 1, "b" => 2 }`, "2"},
+		{"match (false) { true => 1, false => 2 }", "2"},
+		{"match (-1) { -1 => \"minus one\" }", "minus one"},
+		{"match (2) { 1 | 2 | 3 => \"small\", _ => \"big\" }", "small"},
+		{"match (5) { n => n * 2 }", "10"},
+		{"match (5) { n if n > 10 => 1, n if n > 1 => 2, _ => 3 }", "2"},
+		{"match ([]) { [] => \"empty\", _ => \"other\" }", "empty"},
+		{"match ([1, 2, 3]) { [head, ...tail] => [head, tail] }", "[1, [2, 3]]"},
+		{"match ([1]) { [head, ...tail] => tail }", "[]"},
+		{"match ([1, 2]) { [a] => 1, [a, b, c] => 3, [a, b] => a + b }", "3"},
+		{"match ([1, 2, 3]) { [1, ..._] => \"starts with one\" }", "starts with one"},
+		{"match ([[1, 2], 3]) { [[a, b], c] => a + b + c }", "6"},
+		{`match ({"type": "user", "name": "ada", "age": 36}) { {"type": "admin"} => 0, {"type": "user", "name": n} => n }`, "ada"},
+		{`match ({1: [true, 2]}) { {1: [true, n]} => n }`, "2"},
+		{`match ({"a": 1}) { {"b": _} => 1, {} => 2 }`, "2"},
+		{"match ([2, 1]) { [a, 1] | [1, a] => a }", "2"},
+		{"match (1) { [a] => a, {\"a\": a} => a, _ => \"neither\" }", "neither"},
+		{"let n = 1; match (2) { n => n }; n", "1"},
+		{"let f = fn(n, acc) { match (n) { 0 => acc, _ => f(n - 1, acc + n) } }; f(10000, 0)", "50005000"},
+		{"fn size(xs) { match (xs) { [] => 0, [_, ...rest] => 1 + size(rest) } } size([1, 2, 3])", "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (3) { 1 => 1, 2 => 2 }", "no match arm matches 3"},
+		{`match ("x") { "y" => 1 }`, `no match arm matches "x"`},
+		{"match (1) { n if n > 1 => 1 }", "no match arm matches 1"},
+		{"match (x) { _ => 1 }", "identifier not found: x"},
+		{"match (1) { n if n + true => 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{"match (1) { 1 => 1 + true }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/token"
+)
+
+// quote returns its argument unevaluated, after replacing every
+// unquote(expr) call inside it with the code for the value of expr.
+func quote(args []ast.Expression, env *object.Environment) object.Object {
+	if len(args) != 1 {
+		return object.NewError("wrong number of arguments to `quote`. got=%d, want=1", len(args))
+	}
+
+	var failed object.Object
+	node := ast.Modify(args[0], func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || failed != nil || !isUnquoteCall(call) {
+			return node
+		}
+		if len(call.Arguments) != 1 {
+			failed = object.NewError("wrong number of arguments to `unquote`. got=%d, want=1", len(call.Arguments))
+			return node
+		}
+
+		unquoted := Eval(call.Arguments[0], env)
+		if isError(unquoted) {
+			failed = unquoted
+			return node
+		}
+
+		converted, err := objectToASTNode(unquoted, map[object.Object]bool{})
+		if err != nil {
+			failed = object.NewError("%s", err)
+			return node
+		}
+		if converted == nil {
+			failed = object.NewError("cannot unquote %s", unquoted.Type())
+			return node
+		}
+		return converted
+	})
+	if failed != nil {
+		return failed
+	}
+
+	return &object.Quote{Node: node}
+}
+
+func isUnquoteCall(call *ast.CallExpression) bool {
+	ident, ok := call.Function.(*ast.Identifier)
+	return ok && ident.Value == "unquote"
+}
+
+// objectToASTNode returns code that evaluates to obj, or nil if obj has no
+// literal form. visiting holds the arrays being converted further up, since
+// an array that contains itself would need infinite code.
+func objectToASTNode(obj object.Object, visiting map[object.Object]bool) (ast.Node, error) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		t := token.Token{Type: token.INT, Literal: obj.Inspect()}
+		return &ast.IntegerLiteral{Token: t, Value: obj.Value}, nil
+
+	case *object.BigInteger:
+		t := token.Token{Type: token.INT, Literal: obj.Inspect()}
+		return &ast.IntegerLiteral{Token: t, Big: new(big.Int).Set(obj.Value)}, nil
+
+	case *object.Boolean:
+		t := token.Token{Type: token.FALSE, Literal: "false"}
+		if obj.Value {
+			t = token.Token{Type: token.TRUE, Literal: "true"}
+		}
+		return &ast.Boolean{Token: t, Value: obj.Value}, nil
+
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}, nil
+
+	case *object.Array:
+		if visiting[obj] {
+			return nil, fmt.Errorf("cannot unquote %s that contains itself", obj.Type())
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := make([]ast.Expression, 0, len(obj.Elements))
+		for _, el := range obj.Elements {
+			node, err := objectToASTNode(el, visiting)
+			if err != nil {
+				return nil, err
+			}
+			exp, ok := node.(ast.Expression)
+			if !ok {
+				return nil, nil
+			}
+			elements = append(elements, exp)
+		}
+		return &ast.ArrayLiteral{Token: token.Token{Type: token.LBRACKET, Literal: "["}, Elements: elements}, nil
+
+	case *object.Quote:
+		return obj.Node, nil
+
+	default:
+		return nil, nil
+	}
+}
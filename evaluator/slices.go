@@ -0,0 +1,137 @@
+package evaluator
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// evalSlice evaluates a slice of left such as xs[1:-1:2]. Bounds work as
+// they do in Python: omitted or null bounds default to the whole of left in
+// the direction of the step, negative bounds count from the end, and bounds
+// past either end are clamped to it. The result is a new array or string.
+func evalSlice(left object.Object, node *ast.IndexExpression, env *object.Environment) object.Object {
+	var bounds [3]object.Object
+	for i, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			continue
+		}
+		bound := Eval(exp, env)
+		if isError(bound) {
+			return bound
+		}
+		bounds[i] = bound
+	}
+
+	var length int
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.String:
+		length = len(left.Value)
+	default:
+		return object.NewError("slice operator not supported: %s", left.Type())
+	}
+
+	start, step, count, errObj := sliceIndices(bounds, length)
+	if errObj != nil {
+		return errObj
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		elements := make([]object.Object, 0, count)
+		for i := 0; i < count; i++ {
+			elements = append(elements, left.Elements[start+i*step])
+		}
+		return &object.Array{Elements: elements}
+	default:
+		s := left.(*object.String).Value
+		if step == 1 {
+			return &object.String{Value: s[start : start+count]}
+		}
+		var out strings.Builder
+		out.Grow(count)
+		for i := 0; i < count; i++ {
+			out.WriteByte(s[start+i*step])
+		}
+		return &object.String{Value: out.String()}
+	}
+}
+
+// sliceIndices works out which elements of a sequence of the given length a
+// slice with the given start, end and step selects: count elements, the
+// first at start and each step after the one before.
+func sliceIndices(bounds [3]object.Object, length int) (start, step, count int, errObj *object.Error) {
+	n := int64(length)
+	stepValue, omitted, errObj := sliceBound("step", bounds[2])
+	if errObj != nil {
+		return 0, 0, 0, errObj
+	}
+	if omitted {
+		stepValue = 1
+	}
+	if stepValue == 0 {
+		return 0, 0, 0, object.NewError("slice step cannot be zero")
+	}
+	// A step longer than the sequence selects at most one element, so
+	// capping it changes nothing and keeps the arithmetic below in range.
+	stepValue = max(min(stepValue, n+1), -(n + 1))
+
+	lower, upper := int64(0), n
+	if stepValue < 0 {
+		lower, upper = -1, n-1
+	}
+	clamp := func(name string, bound object.Object, def int64) (int64, *object.Error) {
+		i, omitted, errObj := sliceBound(name, bound)
+		if errObj != nil || omitted {
+			return def, errObj
+		}
+		if i < 0 {
+			i += n
+		}
+		return max(min(i, upper), lower), nil
+	}
+
+	from, to := lower, upper
+	if stepValue < 0 {
+		from, to = upper, lower
+	}
+	if from, errObj = clamp("start", bounds[0], from); errObj != nil {
+		return 0, 0, 0, errObj
+	}
+	if to, errObj = clamp("end", bounds[1], to); errObj != nil {
+		return 0, 0, 0, errObj
+	}
+
+	switch {
+	case stepValue > 0 && from < to:
+		count = int((to-from-1)/stepValue + 1)
+	case stepValue < 0 && from > to:
+		count = int((from-to-1)/-stepValue + 1)
+	}
+	return int(from), int(stepValue), count, nil
+}
+
+// sliceBound returns the value of a slice bound, reporting whether it was
+// omitted. Integers too large for an int64 saturate, since they lie past
+// the end of any sequence either way.
+func sliceBound(name string, bound object.Object) (int64, bool, *object.Error) {
+	switch bound := bound.(type) {
+	case nil:
+		return 0, true, nil
+	case *object.Null:
+		return 0, true, nil
+	case *object.Integer:
+		return bound.Value, false, nil
+	case *object.BigInteger:
+		if bound.Value.Sign() < 0 {
+			return math.MinInt64, false, nil
+		}
+		return math.MaxInt64, false, nil
+	default:
+		return 0, false, object.NewError("slice %s must be INTEGER, got %s", name, bound.Type())
+	}
+}
@@ -0,0 +1,83 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestSlicingAndNegativeIndexing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let xs = [1, 2, 3, 4, 5]; xs[1:3]", "[2, 3]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[:2]", "[1, 2]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[3:]", "[4, 5]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[:]", "[1, 2, 3, 4, 5]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[-2:]", "[4, 5]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[:-1]", "[1, 2, 3, 4]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[::2]", "[1, 3, 5]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[1::2]", "[2, 4]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[::-1]", "[5, 4, 3, 2, 1]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[3:0:-1]", "[4, 3, 2]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[-1:-4:-2]", "[5, 3]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[3:1]", "[]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[-100:100]", "[1, 2, 3, 4, 5]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[100:]", "[]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[::100]", "[1]"},
+		{"let xs = [1, 2, 3, 4, 5]; xs[::-100]", "[5]"},
+		{"let xs = [1, 2, 3]; xs[0:99999999999999999999]", "[1, 2, 3]"},
+		{"let xs = [1, 2, 3]; xs[::-9223372036854775807 - 1]", "[3]"},
+		{"let xs = [1, 2, 3]; xs[if (false) { 1 }:2]", "[1, 2]"},
+		{"[][::-1]", "[]"},
+		{"let xs = [1, 2, 3]; let ys = xs[:]; ys[0] = 9; xs", "[1, 2, 3]"},
+		{"let xs = freeze([1, 2]); let ys = xs[:]; ys[0] = 9; ys", "[9, 2]"},
+		{`"hello"[1:4]`, "ell"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"hello"[-3:]`, "llo"},
+		{`"hello"[::2]`, "hlo"},
+		{`"hello"[0]`, "h"},
+		{`"hello"[-1]`, "o"},
+		{`"hello"[5]`, "null"},
+		{`"hello"[-6]`, "null"},
+		{`""[0]`, "null"},
+		{`let h = {"xs": [1, 2, 3]}; h["xs"][1:][0]`, "2"},
+		{`let h = {}; h["xs"]?[1:]`, "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestSlicingErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2][::0]", "slice step cannot be zero"},
+		{`[1, 2]["a":]`, "slice start must be INTEGER, got STRING"},
+		{"[1, 2][:true]", "slice end must be INTEGER, got BOOLEAN"},
+		{"[1, 2][::[1]]", "slice step must be INTEGER, got ARRAY"},
+		{`{"a": 1}[0:1]`, "slice operator not supported: HASH"},
+		{"1[:]", "slice operator not supported: INTEGER"},
+		{"[1, 2][:y]", "identifier not found: y"},
+		{`"abc"["a"]`, "expected index to be *object.Integer, got *object.String"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
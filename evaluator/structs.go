@@ -0,0 +1,111 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+func evalStructStatement(stmt *ast.StructStatement, env *object.Environment) object.Object {
+	if errObj := checkRedeclaration(stmt.Name.Value, env); errObj != nil {
+		return errObj
+	}
+	fields := make([]string, 0, len(stmt.Fields))
+	for _, field := range stmt.Fields {
+		fields = append(fields, field.Value)
+	}
+	env.Set(stmt.Name.Value, object.NewStructType(stmt.Name.Value, fields))
+	return nil
+}
+
+// evalFieldAccess reads the named field of a struct or instance, or looks
+// up a method through super.
+func evalFieldAccess(left object.Object, field string) object.Object {
+	switch left := left.(type) {
+	case *object.Struct:
+		value, ok := left.Get(field)
+		if !ok {
+			return object.NewError("%s has no field %s", left.Def.Name, field)
+		}
+		return value
+	case *object.Instance:
+		return getInstanceField(left, field)
+	case *object.Super:
+		return getSuperMethod(left, field)
+	default:
+		return object.NewError("field access not supported: %s", left.Type())
+	}
+}
+
+// evalAssignStatement stores a value in the struct or instance field, or
+// the array element or hash entry, its target names.
+func evalAssignStatement(as *ast.AssignStatement, env *object.Environment) object.Object {
+	switch target := as.Target.(type) {
+	case *ast.FieldExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		value := Eval(as.Value, env)
+		if isError(value) {
+			return value
+		}
+		return assignField(left, target.Field.Value, value)
+
+	case *ast.IndexExpression:
+		if target.Slice {
+			return object.NewError("cannot assign to %s", as.Target)
+		}
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		value := Eval(as.Value, env)
+		if isError(value) {
+			return value
+		}
+		return evalIndexAssignment(left, index, value)
+
+	default:
+		return object.NewError("cannot assign to %s", as.Target)
+	}
+}
+
+func assignField(left object.Object, field string, value object.Object) object.Object {
+	if errObj := checkMutable(left); errObj != nil {
+		return errObj
+	}
+
+	switch left := left.(type) {
+	case *object.Struct:
+		if !left.Set(field, value) {
+			return object.NewError("%s has no field %s", left.Def.Name, field)
+		}
+	case *object.Instance:
+		left.Set(field, value)
+	default:
+		return object.NewError("field assignment not supported: %s", left.Type())
+	}
+	return nil
+}
+
+// reprStruct formats s like its Inspect, but with its field values shown by
+// repr.
+func reprStruct(s *object.Struct, visiting map[object.Object]bool) string {
+	if visiting[s] {
+		return s.Def.Name + "{...}"
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	fields := make([]string, 0, len(s.Values))
+	for i, field := range s.Def.Fields() {
+		fields = append(fields, field+": "+reprValue(s.Values[i], visiting))
+	}
+	return s.Def.Name + "{" + strings.Join(fields, ", ") + "}"
+}
@@ -0,0 +1,75 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Point { x, y } Point(1, 2)", "Point{x: 1, y: 2}"},
+		{"struct Point { x, y } Point(y: 2, x: 1)", "Point{x: 1, y: 2}"},
+		{"struct Point { x, y } Point(1, y: 2).y", "2"},
+		{"struct Point { x, y } let p = Point(1, 2); p.x + p.y", "3"},
+		{"struct Point { x, y } let p = Point(1, 2); p.x = 10; p", "Point{x: 10, y: 2}"},
+		{"struct Point { x, y } let p = Point(1, 2); let q = p; q.x = 5; p.x", "5"},
+		{"struct Point { x, y } let p = Point(1, 2); p.x = p.x + 1; p.x = p.x + 1; p.x", "3"},
+		{"struct Box { value } struct Pair { left, right } let p = Pair(Box(1), Box(2)); p.right.value = 3; p", "Pair{left: Box{value: 1}, right: Box{value: 3}}"},
+		{"struct Empty {} Empty()", "Empty{}"},
+		{"struct Point { x, y } Point", "struct Point { x, y }"},
+		{"struct Point { x, y } Point(1, 2) == Point(1, 2)", "true"},
+		{"struct Point { x, y } struct Pair { x, y } Point(1, 2) == Pair(1, 2)", "false"},
+		{"struct Point { x, y } type_of(Point(1, 2))", "Point"},
+		{"type_of(1)", "INTEGER"},
+		{`type_of("a")`, "STRING"},
+		{"struct Point { x, y } type_of(Point)", "STRUCT_TYPE"},
+		{"struct Point { x, y } let f = fn(p) { p.x * 2 }; f(Point(3, 0))", "6"},
+		{"struct Node { value, next } let n = Node(1, Node(2, false)); n.next.value", "2"},
+		{"let f = fn() { struct Local { a } Local(1) }; f()", "Local{a: 1}"},
+		{"struct Point { x, y } let x = 5; Point(1, 2).x", "1"},
+		{"struct Point { x, y } let ps = [Point(1, 2)]; ps[0].y", "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Point { x, y } Point(1)", "Point: wrong number of arguments. got=1, want=2"},
+		{"struct Point { x, y } Point(1, 2, 3)", "Point: wrong number of arguments. got=3, want=2"},
+		{"struct Point { x, y } Point(x: 1)", "Point: missing argument `y`"},
+		{"struct Point { x, y } Point(1, z: 2)", "Point: unexpected keyword argument `z`"},
+		{"struct Point { x, y } Point(1, x: 2)", "Point: got multiple values for argument `x`"},
+		{"struct Point { x, y } Point(1, 2).z", "Point has no field z"},
+		{"struct Point { x, y } let p = Point(1, 2); p.z = 1;", "Point has no field z"},
+		{"1.x", "field access not supported: INTEGER"},
+		{`let h = {"x": 1}; h.x`, "field access not supported: HASH"},
+		{"let n = 1; n.x = 2;", "field assignment not supported: INTEGER"},
+		{"struct Point { x, y } let p = Point(1, 2); p.x = q;", "identifier not found: q"},
+		{"struct Point { x, y } Point(1, 2) + 1", "type mismatch: STRUCT + INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected Error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// tailCall is a call to a Jian function in tail position that has not been
+// made yet. The function bodies evaluated by applyFunction return it instead
+// of calling the function themselves, and applyFunction then makes the call
+// in its own loop, so tail-recursive functions run in constant Go stack
+// space. A tailCall never escapes applyFunction.
+type tailCall struct {
+	fn       *object.Function
+	args     []object.Object
+	keywords []object.Keyword
+	call     *ast.CallExpression
+}
+
+func (tc *tailCall) Type() object.ObjectType { return object.FUNCTION }
+func (tc *tailCall) Inspect() string         { return "tail call" }
+
+// evalTail evaluates a node in tail position of a function body: its value,
+// unless discarded, is the value of the function. Calls in tail position are
+// returned as *tailCall, possibly wrapped in a ReturnValue.
+func evalTail(node ast.Node, env *object.Environment) object.Object {
+	switch val := node.(type) {
+	case *ast.BlockStatement:
+		return evalTailBlockStatement(val, env)
+
+	case *ast.ExpressionStatement:
+		return evalTail(val.Expression, env)
+
+	case *ast.ReturnStatement:
+		v := evalTail(val.ReturnValue, env)
+		if isError(v) {
+			return v
+		}
+		return &object.ReturnValue{Value: v}
+
+	case *ast.IfExpression:
+		cond := Eval(val.Condition, env)
+		if isError(cond) {
+			return cond
+		}
+		if isTruthy(cond) {
+			return evalTail(val.Consequence, env)
+		}
+		if val.Alternative != nil {
+			return evalTail(val.Alternative, env)
+		}
+		return NULL
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(val, env, evalTail)
+
+	case *ast.CallExpression:
+		if ident, ok := val.Function.(*ast.Identifier); ok && ident.Value == "quote" {
+			return Eval(val, env)
+		}
+
+		fn, skipped := evalChain(val.Function, env)
+		if skipped {
+			return NULL
+		}
+		if isError(fn) {
+			return fn
+		}
+
+		args, keywords, errObj := evalCallArguments(val.Arguments, env)
+		if errObj != nil {
+			return errObj
+		}
+
+		if function, ok := fn.(*object.Function); ok {
+			return &tailCall{fn: function, args: args, keywords: keywords, call: val}
+		}
+		return withFrame(callFunction(fn, args, keywords, env), fn, val)
+
+	default:
+		return Eval(node, env)
+	}
+}
+
+// evalTailBlockStatement is evalBlockStatement for a block in tail position.
+// Only the last statement is in tail position, but a return statement
+// anywhere in the block ends the function, so its value is too.
+func evalTailBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	if errObj := hoistFunctions(block.Statements, env); errObj != nil {
+		return errObj
+	}
+
+	var r object.Object
+
+	for i, stmt := range block.Statements {
+		if i == len(block.Statements)-1 {
+			return evalTail(stmt, env)
+		}
+
+		r = evalTail(stmt, env)
+		if tc, ok := r.(*tailCall); ok {
+			// The value of this statement is discarded, so the call was not
+			// in tail position after all.
+			r = withFrame(callFunction(tc.fn, tc.args, tc.keywords, env), tc.fn, tc.call)
+		}
+		if r != nil {
+			if r.Type() == object.RETURN_VALUE || r.Type() == object.ERROR {
+				return r
+			}
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,100 @@
+package evaluator_test
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+// withSmallStack runs f with the Go stack limited to a few megabytes, far
+// less than the nested Jian calls below would need without tail calls.
+func withSmallStack(t *testing.T, f func()) {
+	t.Helper()
+	old := debug.SetMaxStack(16 << 20)
+	defer debug.SetMaxStack(old)
+	f()
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{
+			"let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } }; count(1000000)",
+			0,
+		},
+		{
+			"let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(2000000, 0)",
+			2000001000000,
+		},
+		{
+			`let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };
+			 let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } };
+			 even(200001)`,
+			false,
+		},
+		{
+			`let loop = fn(n) {
+				let next = n - 1;
+				if (next < 0) {
+					return "done";
+				} else {
+					if (next > 300000) { loop(next) } else { loop(next - 1) }
+				}
+			};
+			loop(600000)`,
+			"done",
+		},
+		{
+			"let id = fn(x) { x }; let spin = fn(n) { if (n == 0) { id(n) } else { spin(n - 1) } }; spin(100000)",
+			0,
+		},
+	}
+
+	withSmallStack(t, func() {
+		for _, tt := range tests {
+			evaluated := testEval(tt.input)
+			switch expected := tt.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			case string:
+				if evaluated == nil || evaluated.Inspect() != expected {
+					t.Errorf("expected %q, got %v", expected, evaluated)
+				}
+			}
+		}
+	})
+}
+
+func TestCallsOutsideTailPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(10)", 3628800},
+		{"let f = fn() { g(); 2 }; let g = fn() { 1 }; f()", 2},
+		{"let f = fn() { if (true) { g() } 2 }; let g = fn() { return 1; }; f()", 2},
+		{"let f = fn() { [g()] }; let g = fn() { 1 }; f()[0]", 1},
+		{"let f = fn() { let x = g(); x + 1 }; let g = fn() { 1 }; f()", 2},
+		{"let f = fn() { g(); }; let g = fn() { 1 + true }; f(); 3", "type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn() { h(1) }; let h = fn() { 1 }; f()", "h: invalid argument length; expected 0 arguments, got 1"},
+		{"let f = fn() { len([1, 2]) }; f()", 2},
+		{"let f = fn() { 5() }; f()", "not a function: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if evaluated == nil || evaluated.Type() != object.ERROR || evaluated.Inspect() != expected {
+				t.Errorf("%s: expected error %q, got %v", tt.input, expected, evaluated)
+			}
+		}
+	}
+}
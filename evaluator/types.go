@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/object"
+)
+
+// conforms reports whether value has type typ. Names other than those of
+// the builtin types are looked up in env and must name a class or struct
+// type. Arrays and hashes conform if all their elements do, and any value
+// that can be called conforms to a function type.
+func conforms(value object.Object, typ ast.TypeExpression, env *object.Environment) bool {
+	switch typ := typ.(type) {
+	case *ast.NamedType:
+		switch typ.Name {
+		case "any":
+			return true
+		case "int":
+			return value.Type() == object.INTEGER
+		case "float":
+			return value.Type() == object.FLOAT
+		case "string":
+			return value.Type() == object.STRING
+		case "bool":
+			return value.Type() == object.BOOLEAN
+		case "null":
+			return value == NULL
+		}
+		def, ok := env.Get(typ.Name)
+		if !ok {
+			return false
+		}
+		is, valid := isInstance(value, def)
+		return valid && is
+
+	case *ast.ArrayType:
+		arr, ok := value.(*object.Array)
+		if !ok {
+			return false
+		}
+		for _, el := range arr.Elements {
+			if !conforms(el, typ.Element, env) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.HashType:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false
+		}
+		for _, pair := range hash.OrderedPairs() {
+			if !conforms(pair.Key, typ.Key, env) || !conforms(pair.Value, typ.Value, env) {
+				return false
+			}
+		}
+		return true
+
+	case *ast.FunctionType:
+		switch value.(type) {
+		case *object.Function, *object.Builtin, *object.Class, *object.StructType:
+			return true
+		}
+		return false
+
+	case *ast.UnionType:
+		for _, alt := range typ.Types {
+			if conforms(value, alt, env) {
+				return true
+			}
+		}
+		return false
+
+	case *ast.NullableType:
+		return value == NULL || conforms(value, typ.Type, env)
+
+	default:
+		return true
+	}
+}
+
+// typeName names the type of value the way type annotations do, such as
+// int or bool, or by the name of its struct or class. Other values are
+// named after their object type, such as array or hash.
+func typeName(value object.Object) string {
+	switch value := value.(type) {
+	case *object.Boolean:
+		return "bool"
+	case *object.Function, *object.Builtin:
+		return "function"
+	case *object.Struct:
+		return value.Def.Name
+	case *object.Instance:
+		return value.Class.Name
+	}
+	if value.Type() == object.INTEGER {
+		return "int"
+	}
+	return strings.ToLower(string(value.Type()))
+}
+
+// checkType returns a type error describing what if strict mode is on and
+// value does not conform to typ, which may be nil.
+func checkType(what string, value object.Object, typ ast.TypeExpression, env *object.Environment) *object.Error {
+	if typ == nil || !env.Strict() || conforms(value, typ, env) {
+		return nil
+	}
+	return object.NewError("type error: %s must be %s, got %s", what, typ, typeName(value))
+}
@@ -0,0 +1,111 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/parser"
+)
+
+func testStrictEval(input string) object.Object {
+	program := parser.New(lexer.New(input)).ParseProgram()
+	env := object.NewEnvironment()
+	env.SetStrict(true)
+	return evaluator.Eval(program, env)
+}
+
+func TestAnnotationsIgnoredByDefault(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let x: int = "a"; x`, "a"},
+		{`let f = fn(a: int) -> int { a }; f("b")`, "b"},
+		{`fn f(...r: [string]) -> bool { len(r) } f(1, 2)`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 5; x", "5"},
+		{"let x: int = 123456789012345678901234567890; x", "123456789012345678901234567890"},
+		{`let x: int | string = "a"; x`, "a"},
+		{"let x: int? = if (false) { 1 }; x", "null"},
+		{`let xs: [int | string] = [1, "a"]; len(xs)`, "2"},
+		{`let h: {string: [int]} = {"a": [1], "b": []}; h["a"]`, "[1]"},
+		{"let xs: [int] = []; xs", "[]"},
+		{"let f: fn(int) -> int = fn(a) { a }; f(1)", "1"},
+		{"let f: fn() = len; 1", "1"},
+		{"let [a, b]: [int] = [1, 2]; a + b", "3"},
+		{"let x: any = fn() {}; 1", "1"},
+		{"fn add(a: int, b: int = 2) -> int { a + b } add(1)", "3"},
+		{"fn f(...r: [int]) -> [int] { r } f(1, 2)", "[1, 2]"},
+		{"fn f([a, b]: [int]) -> int { a * b } f([2, 3])", "6"},
+		{"struct P { x } fn f(p: P) -> int { p.x } f(P(4))", "4"},
+		{"class A {} class B extends A {} fn f(a: A) -> A { a } f(B())", "B{}"},
+		{"fn count(n: int, acc: int) -> int { if (n == 0) { return acc } count(n - 1, acc + 1) } count(10000, 0)", "10000"},
+		{"fn f(n) -> int { g(n) } fn g(n) { n } f(1)", "1"},
+		{`fn half(x: float) -> float { x / json_parse("2.0") } half(json_parse("3.0"))`, "1.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testStrictEval(tt.input)
+		if evaluated == nil || evaluated.Type() == object.ERROR || evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected %q, got %T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestStrictModeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let x: int = "a";`, "type error: x must be int, got string"},
+		{"let x: string? = 1;", "type error: x must be string?, got int"},
+		{"let x: bool = if (false) { 1 };", "type error: x must be bool, got null"},
+		{`let xs: [int] = [1, "a"];`, "type error: xs must be [int], got array"},
+		{`let h: {string: int} = {1: 1};`, "type error: h must be {string: int}, got hash"},
+		{"let f: fn() -> int = 1;", "type error: f must be fn() -> int, got int"},
+		{"let [a, b]: [string] = [1, 2];", "type error: [a, b] must be [string], got array"},
+		{"struct P { x } struct Q { x } let p: P = Q(1);", "type error: p must be P, got Q"},
+		{"class A {} class B {} let a: A = B();", "type error: a must be A, got B"},
+		{"let u: Unknown = 1;", "type error: u must be Unknown, got int"},
+		{`let f: float = 1;`, "type error: f must be float, got int"},
+		{`let n: int = json_parse("1.5");`, "type error: n must be int, got float"},
+		{`fn f(a: int) { a } f("s")`, "f: type error: argument `a` must be int, got string"},
+		{`let f = fn(a, b: bool) { a }; f(1, b: 2)`, "f: type error: argument `b` must be bool, got int"},
+		{`fn f(a: int = "x") { a } f()`, "f: type error: argument `a` must be int, got string"},
+		{`fn f(...r: [int]) { r } f(1, "a")`, "f: type error: argument `r` must be [int], got array"},
+		{`fn f() -> int { "s" } f()`, "f: type error: return value must be int, got string"},
+		{`fn f() -> int { return true; } f()`, "f: type error: return value must be int, got bool"},
+		{`fn f() -> int { g() } fn g() { "s" } f()`, "f: type error: return value must be int, got string"},
+		{`fn f() -> int { g() } fn g() -> string { 1 } f()`, "g: type error: return value must be string, got int"},
+		{`fn(a: int) { a }("s")`, "type error: argument `a` must be int, got string"},
+		{`class A { fn init(n: int) { self.n = n } } A("x")`, "A.init: type error: argument `n` must be int, got string"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testStrictEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected error, got %T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package filesystem provides the sandboxed file access used by the file
+// builtins. Hosts choose what a program may touch by attaching one of the
+// implementations below to the global environment.
+//
+// All paths are slash-separated and relative to the root of the filesystem.
+// Absolute paths and paths that climb out of the root with ".." are
+// rejected with ErrOutsideRoot.
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+var (
+	ErrOutsideRoot = errors.New("path is outside the filesystem root")
+	ErrReadOnly    = errors.New("filesystem is read-only")
+	ErrDisabled    = errors.New("filesystem access is not enabled")
+)
+
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	AppendFile(name string, data []byte) error
+	// ReadDir returns the names of the entries in a directory, sorted.
+	ReadDir(name string) ([]string, error)
+	Exists(name string) (bool, error)
+	Remove(name string) error
+}
+
+// Clean validates name and returns it in the canonical form used by fs.FS:
+// no leading slash, no "." or ".." elements, "." for the root itself.
+func Clean(op, name string) (string, error) {
+	if name == "" {
+		name = "."
+	}
+
+	if path.IsAbs(name) || strings.HasPrefix(name, `\`) {
+		return "", &fs.PathError{Op: op, Path: name, Err: ErrOutsideRoot}
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", &fs.PathError{Op: op, Path: name, Err: ErrOutsideRoot}
+	}
+
+	return cleaned, nil
+}
+
+type readOnly struct {
+	FS
+}
+
+// ReadOnly wraps fsys so that every write fails with ErrReadOnly.
+func ReadOnly(fsys FS) FS {
+	return readOnly{fsys}
+}
+
+func (r readOnly) WriteFile(name string, data []byte) error {
+	return &fs.PathError{Op: "write", Path: name, Err: ErrReadOnly}
+}
+
+func (r readOnly) AppendFile(name string, data []byte) error {
+	return &fs.PathError{Op: "append", Path: name, Err: ErrReadOnly}
+}
+
+func (r readOnly) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: ErrReadOnly}
+}
@@ -0,0 +1,227 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ekediala/jian/filesystem"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		escapes  bool
+	}{
+		{"", ".", false},
+		{".", ".", false},
+		{"a/b/../c.txt", "a/c.txt", false},
+		{"./a//b/", "a/b", false},
+		{"..", "", true},
+		{"a/../../b", "", true},
+		{"/etc/passwd", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := filesystem.Clean("open", tt.name)
+		if tt.escapes {
+			if !errors.Is(err, filesystem.ErrOutsideRoot) {
+				t.Errorf("Clean(%q): expected ErrOutsideRoot, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("Clean(%q): expected %q, got %q (%v)", tt.name, tt.expected, got, err)
+		}
+	}
+}
+
+// testReadWrite exercises the behaviour every writable implementation shares.
+func testReadWrite(t *testing.T, fsys filesystem.FS) {
+	t.Helper()
+
+	if err := fsys.WriteFile("notes.txt", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.AppendFile("notes.txt", []byte(" world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.AppendFile("log.txt", []byte("created")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fsys.ReadFile("notes.txt")
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("expected %q, got %q (%v)", "hello world", data, err)
+	}
+
+	names, err := fsys.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"log.txt", "notes.txt", "sub"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected entries %v, got %v", expected, names)
+	}
+
+	if ok, err := fsys.Exists("sub/inner.txt"); !ok || err != nil {
+		t.Errorf("expected sub/inner.txt to exist, got %t (%v)", ok, err)
+	}
+
+	if err := fsys.Remove("notes.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := fsys.Exists("notes.txt"); ok {
+		t.Errorf("expected notes.txt to be removed")
+	}
+
+	if _, err := fsys.ReadFile("missing.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+
+	for _, name := range []string{"../outside.txt", "/etc/passwd", "sub/../../x"} {
+		if _, err := fsys.ReadFile(name); !errors.Is(err, filesystem.ErrOutsideRoot) {
+			t.Errorf("ReadFile(%q): expected ErrOutsideRoot, got %v", name, err)
+		}
+		if err := fsys.WriteFile(name, nil); !errors.Is(err, filesystem.ErrOutsideRoot) {
+			t.Errorf("WriteFile(%q): expected ErrOutsideRoot, got %v", name, err)
+		}
+	}
+}
+
+func TestOS(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "inner.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := filesystem.OS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testReadWrite(t, fsys)
+
+	_, err = fsys.ReadFile("missing.txt")
+	if err == nil || strings.Contains(err.Error(), root) {
+		t.Errorf("expected error without the root path, got %v", err)
+	}
+}
+
+func TestOSRefusesSymlinksOutsideRoot(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	fsys, err := filesystem.OS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fsys.ReadFile("link/secret.txt"); !errors.Is(err, filesystem.ErrOutsideRoot) {
+		t.Errorf("expected ErrOutsideRoot reading through link, got %v", err)
+	}
+	if err := fsys.WriteFile("link/new.txt", []byte("x")); !errors.Is(err, filesystem.ErrOutsideRoot) {
+		t.Errorf("expected ErrOutsideRoot writing through link, got %v", err)
+	}
+}
+
+func TestOSRefusesDanglingSymlinksOutsideRoot(t *testing.T) {
+	outside := t.TempDir()
+	root := t.TempDir()
+	if err := os.Symlink(filepath.Join(outside, "pwned"), filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("inside.txt", filepath.Join(root, "local")); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := filesystem.OS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fsys.WriteFile("link", []byte("escaped")); !errors.Is(err, filesystem.ErrOutsideRoot) {
+		t.Errorf("expected ErrOutsideRoot writing through dangling link, got %v", err)
+	}
+	if err := fsys.AppendFile("link", []byte("escaped")); !errors.Is(err, filesystem.ErrOutsideRoot) {
+		t.Errorf("expected ErrOutsideRoot appending through dangling link, got %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "pwned")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected nothing written outside the root, got %v", err)
+	}
+
+	if err := fsys.WriteFile("local", []byte("x")); err != nil {
+		t.Fatalf("writing through dangling link inside the root: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "inside.txt")); err != nil || string(data) != "x" {
+		t.Errorf("expected inside.txt to hold %q, got %q (%v)", "x", data, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	fsys := filesystem.NewMemory()
+	if err := fsys.WriteFile("sub/inner.txt", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	testReadWrite(t, fsys)
+
+	if err := fsys.Remove("sub"); err == nil {
+		t.Errorf("expected error removing non-empty directory")
+	}
+	if err := fsys.WriteFile("sub/inner.txt/nested", nil); err == nil {
+		t.Errorf("expected error writing below a file")
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := filesystem.FromFS(fstest.MapFS{
+		"config/app.json": {Data: []byte(`{"debug": true}`)},
+	})
+
+	data, err := fsys.ReadFile("config/app.json")
+	if err != nil || string(data) != `{"debug": true}` {
+		t.Errorf("unexpected contents %q (%v)", data, err)
+	}
+
+	names, err := fsys.ReadDir("config")
+	if err != nil || !reflect.DeepEqual(names, []string{"app.json"}) {
+		t.Errorf("unexpected entries %v (%v)", names, err)
+	}
+
+	if err := fsys.WriteFile("config/app.json", nil); !errors.Is(err, filesystem.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if _, err := fsys.ReadFile("../x"); !errors.Is(err, filesystem.ErrOutsideRoot) {
+		t.Errorf("expected ErrOutsideRoot, got %v", err)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	memory := filesystem.NewMemory()
+	memory.WriteFile("a.txt", []byte("a"))
+	fsys := filesystem.ReadOnly(memory)
+
+	if data, err := fsys.ReadFile("a.txt"); err != nil || string(data) != "a" {
+		t.Errorf("unexpected contents %q (%v)", data, err)
+	}
+	if err := fsys.AppendFile("a.txt", []byte("b")); !errors.Is(err, filesystem.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if err := fsys.Remove("a.txt"); !errors.Is(err, filesystem.ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+)
+
+type ioFS struct {
+	fsys fs.FS
+}
+
+// FromFS exposes any fs.FS, such as an embed.FS or os.DirFS, as a read-only
+// filesystem.
+func FromFS(fsys fs.FS) FS {
+	return ReadOnly(ioFS{fsys})
+}
+
+func (f ioFS) ReadFile(name string) ([]byte, error) {
+	name, err := Clean("read", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(f.fsys, name)
+}
+
+func (f ioFS) ReadDir(name string) ([]string, error) {
+	name, err := Clean("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(f.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+func (f ioFS) Exists(name string) (bool, error) {
+	name, err := Clean("stat", name)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = fs.Stat(f.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (f ioFS) WriteFile(name string, data []byte) error  { return ErrReadOnly }
+func (f ioFS) AppendFile(name string, data []byte) error { return ErrReadOnly }
+func (f ioFS) Remove(name string) error                  { return ErrReadOnly }
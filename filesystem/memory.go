@@ -0,0 +1,158 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory is an in-memory filesystem. Directories exist implicitly for every
+// file below them, so writing "a/b.txt" makes "a" appear in listings.
+type Memory struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{files: map[string][]byte{}}
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	cleaned, err := Clean("read", name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.files[cleaned]
+	if !ok {
+		if m.isDir(cleaned) {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+func (m *Memory) WriteFile(name string, data []byte) error {
+	return m.write("write", name, data, false)
+}
+
+func (m *Memory) AppendFile(name string, data []byte) error {
+	return m.write("append", name, data, true)
+}
+
+func (m *Memory) write(op, name string, data []byte, appending bool) error {
+	cleaned, err := Clean(op, name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cleaned == "." || m.isDir(cleaned) {
+		return &fs.PathError{Op: op, Path: name, Err: errors.New("is a directory")}
+	}
+	for dir := path.Dir(cleaned); dir != "."; dir = path.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return &fs.PathError{Op: op, Path: name, Err: errors.New("not a directory")}
+		}
+	}
+
+	if appending {
+		data = append(append([]byte(nil), m.files[cleaned]...), data...)
+	} else {
+		data = append([]byte(nil), data...)
+	}
+	m.files[cleaned] = data
+	return nil
+}
+
+func (m *Memory) ReadDir(name string) ([]string, error) {
+	cleaned, err := Clean("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.isDir(cleaned) {
+		if _, ok := m.files[cleaned]; ok {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	seen := map[string]bool{}
+	for file := range m.files {
+		rel := file
+		if cleaned != "." {
+			rel = strings.TrimPrefix(file, cleaned+"/")
+			if rel == file {
+				continue
+			}
+		}
+		child, _, _ := strings.Cut(rel, "/")
+		seen[child] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for child := range seen {
+		names = append(names, child)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *Memory) Exists(name string) (bool, error) {
+	cleaned, err := Clean("stat", name)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.files[cleaned]
+	return ok || m.isDir(cleaned), nil
+}
+
+func (m *Memory) Remove(name string) error {
+	cleaned, err := Clean("remove", name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.files[cleaned]; ok {
+		delete(m.files, cleaned)
+		return nil
+	}
+	if m.isDir(cleaned) {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+}
+
+// isDir reports whether any file lives below dir. The root always exists.
+func (m *Memory) isDir(dir string) bool {
+	if dir == "." {
+		return true
+	}
+	for file := range m.files {
+		if strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
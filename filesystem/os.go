@@ -0,0 +1,184 @@
+package filesystem
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxLinkHops bounds how many dangling links resolve follows by hand.
+const maxLinkHops = 255
+
+type osFS struct {
+	root string
+}
+
+// OS gives read and write access to the directory tree below root on the
+// real filesystem. Symbolic links that point outside root are refused.
+func OS(root string) (FS, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(real)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: root, Err: errors.New("not a directory")}
+	}
+
+	return &osFS{root: real}, nil
+}
+
+// resolve maps name to a path on disk, checking that neither the path nor
+// any symbolic link along it leads outside the root.
+func (f *osFS) resolve(op, name string) (string, error) {
+	cleaned, err := Clean(op, name)
+	if err != nil {
+		return "", err
+	}
+
+	full := filepath.Join(f.root, filepath.FromSlash(cleaned))
+
+	// Resolve links on the longest prefix of the path that exists; the rest
+	// is about to be created. A dangling link along the way is followed by
+	// hand, since writing through it would create its target, which must be
+	// checked like any other path.
+	existing, rest := full, ""
+	for hops := 0; ; {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			if !f.contains(real) {
+				return "", &fs.PathError{Op: op, Path: name, Err: ErrOutsideRoot}
+			}
+			return filepath.Join(real, rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		if info, lerr := os.Lstat(existing); lerr == nil && info.Mode()&fs.ModeSymlink != 0 {
+			if hops++; hops > maxLinkHops {
+				return "", &fs.PathError{Op: op, Path: name, Err: errors.New("too many links")}
+			}
+			target, err := os.Readlink(existing)
+			if err != nil {
+				return "", relabel(err, name)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(existing), target)
+			}
+			existing = target
+			continue
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", err
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
+
+func (f *osFS) contains(real string) bool {
+	rel, err := filepath.Rel(f.root, real)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (f *osFS) ReadFile(name string) ([]byte, error) {
+	full, err := f.resolve("read", name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(full)
+	return data, relabel(err, name)
+}
+
+func (f *osFS) WriteFile(name string, data []byte) error {
+	full, err := f.resolve("write", name)
+	if err != nil {
+		return err
+	}
+	return relabel(os.WriteFile(full, data, 0o644), name)
+}
+
+func (f *osFS) AppendFile(name string, data []byte) error {
+	full, err := f.resolve("append", name)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(full, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return relabel(err, name)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return relabel(err, name)
+	}
+	return relabel(file.Close(), name)
+}
+
+func (f *osFS) ReadDir(name string) ([]string, error) {
+	full, err := f.resolve("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return nil, relabel(err, name)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *osFS) Exists(name string) (bool, error) {
+	full, err := f.resolve("stat", name)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, relabel(err, name)
+}
+
+func (f *osFS) Remove(name string) error {
+	full, err := f.resolve("remove", name)
+	if err != nil {
+		return err
+	}
+	if full == f.root {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("cannot remove the filesystem root")}
+	}
+	return relabel(os.Remove(full), name)
+}
+
+// relabel replaces the on-disk path in err with the name the program used,
+// so error messages do not reveal where the root lives.
+func relabel(err error, name string) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return &fs.PathError{Op: pathErr.Op, Path: name, Err: pathErr.Err}
+	}
+	return err
+}
@@ -9,10 +9,12 @@ type Lexer struct {
 	position     int  // current position in input [points to current char]
 	readPosition int  // current reading position in input [after current char]
 	ch           byte // current char under examination
+	line         int  // line of ch, starting at 1
+	column       int  // byte offset of ch within its line, starting at 1
 }
 
 func New(source string) *Lexer {
-	l := Lexer{input: source}
+	l := Lexer{input: source, line: 1}
 	l.readChar()
 	return &l
 }
@@ -20,6 +22,12 @@ func New(source string) *Lexer {
 // The purpose of readChar is to give us the next character and advance our cursor in
 // the source code
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -77,10 +85,17 @@ func (l *Lexer) readString() string {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	line, column := l.line, l.column
+	tok := l.nextToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case toByte(token.ASSIGN):
 		{
@@ -89,6 +104,9 @@ func (l *Lexer) NextToken() token.Token {
 				ch := l.ch
 				l.readChar()
 				tok.Literal = string(ch) + string(l.ch)
+			} else if next == toByte(token.GT) {
+				l.readChar()
+				tok = token.Token{Type: token.ARROW, Literal: token.ARROW}
 			} else {
 				tok = newToken(token.ASSIGN, l.ch)
 			}
@@ -98,6 +116,16 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SEMICOLON, l.ch)
 	case toByte(token.COLON):
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: token.ELLIPSIS}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
+	case toByte(token.PIPE):
+		tok = newToken(token.PIPE, l.ch)
 	case toByte(token.LPAREN):
 		tok = newToken(token.LPAREN, l.ch)
 	case toByte(token.RPAREN):
@@ -113,7 +141,26 @@ func (l *Lexer) NextToken() token.Token {
 	case toByte(token.RBRACE):
 		tok = newToken(token.RBRACE, l.ch)
 	case toByte(token.MINUS):
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == toByte(token.GT) {
+			l.readChar()
+			tok = token.Token{Type: token.RARROW, Literal: token.RARROW}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
+	case toByte(token.QUESTION):
+		switch l.peekChar() {
+		case '.':
+			l.readChar()
+			tok = token.Token{Type: token.OPTIONAL_DOT, Literal: token.OPTIONAL_DOT}
+		case toByte(token.LBRACKET):
+			l.readChar()
+			tok = token.Token{Type: token.OPTIONAL_LBRACKET, Literal: token.OPTIONAL_LBRACKET}
+		case toByte(token.QUESTION):
+			l.readChar()
+			tok = token.Token{Type: token.COALESCE, Literal: token.COALESCE}
+		default:
+			tok = newToken(token.QUESTION, l.ch)
+		}
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
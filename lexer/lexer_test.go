@@ -28,6 +28,11 @@ func TestNextToken(t *testing.T) {
 	"foo bar"
 	[1, 2];
 	{"foo": "bar"}
+	struct p.x
+	fn(a: int?) -> int
+	const c
+	a?.b?[0] ?? c
+	yield c
 	`
 
 	tests := []struct {
@@ -130,6 +135,36 @@ func TestNextToken(t *testing.T) {
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
 
+		{token.STRUCT, "struct"},
+		{token.IDENT, "p"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.COLON, ":"},
+		{token.IDENT, "int"},
+		{token.QUESTION, "?"},
+		{token.RPAREN, ")"},
+		{token.RARROW, "->"},
+		{token.IDENT, "int"},
+
+		{token.CONST, "const"},
+		{token.IDENT, "c"},
+
+		{token.IDENT, "a"},
+		{token.OPTIONAL_DOT, "?."},
+		{token.IDENT, "b"},
+		{token.OPTIONAL_LBRACKET, "?["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.COALESCE, "??"},
+		{token.IDENT, "c"},
+
+		{token.YIELD, "yield"},
+		{token.IDENT, "c"},
+
 		{token.EOF, ""},
 	}
 
@@ -149,3 +184,37 @@ func TestNextToken(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  puts(\"a\nb\", x)\n"
+
+	tests := []struct {
+		literal string
+		line    int
+		column  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"puts", 2, 3},
+		{"(", 2, 7},
+		{"a\nb", 2, 8},
+		{",", 3, 3},
+		{"x", 3, 5},
+		{")", 3, 6},
+		{"", 4, 1},
+	}
+
+	l := lexer.New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.literal {
+			t.Fatalf("tests[%d]- expected token literal %q, got %q", i, tt.literal, tok.Literal)
+		}
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Errorf("tests[%d]- %q: expected position %d:%d, got %d:%d", i, tt.literal, tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
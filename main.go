@@ -1,34 +1,239 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/user"
+	"regexp"
 
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/astjson"
+	"github.com/ekediala/jian/checker"
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/filesystem"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/optimizer"
+	"github.com/ekediala/jian/parser"
 	"github.com/ekediala/jian/repl"
+	"github.com/ekediala/jian/testrunner"
 )
 
 func main() {
+	args := os.Args
+	if len(args) >= 2 && args[1] == "test" {
+		os.Exit(runTests(args[2:]))
+	}
+	if len(args) >= 2 && args[1] == "parse" {
+		os.Exit(runParse(args[2:]))
+	}
+	if len(args) >= 2 && args[1] == "check" {
+		os.Exit(runCheck(args[2:]))
+	}
+
+	flags := flag.NewFlagSet("jian", flag.ExitOnError)
+	strict := flags.Bool("strict", false, "check type annotations while the program runs")
+	allowWrite := flags.Bool("allow-write", false, "let file builtins write below the current directory")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: jian [--strict] [--allow-write] [file]\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args[1:])
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	args := os.Args
-	if len(args) == 2 {
-		fileName := args[1]
-		f, err := os.Open(fileName)
+	env, err := newEnvironment(".", *allowWrite)
+	if err != nil {
+		log.Fatal(err)
+	}
+	env.SetStrict(*strict)
+
+	if flags.NArg() == 1 {
+		f, err := os.Open(flags.Arg(0))
 		if err != nil {
 			log.Fatal(err)
 		}
 		defer f.Close()
-		repl.Start(f, os.Stdout)
+		env.SetIO(object.NewIO(f, os.Stdout, os.Stderr))
+		repl.StartWithEnv(env)
 		return
 	}
 
 	fmt.Printf("Hello %s! This is the Jian programming language!\n",
 		user.Username)
 	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.StartWithEnv(env)
+}
+
+// newEnvironment returns a global environment wired to the process's
+// standard streams, with file access confined to root. Files can only be
+// read unless writable is set.
+func newEnvironment(root string, writable bool) (*object.Environment, error) {
+	fsys, err := filesystem.OS(root)
+	if err != nil {
+		return nil, err
+	}
+	if !writable {
+		fsys = filesystem.ReadOnly(fsys)
+	}
+
+	env := object.NewEnvironment()
+	env.SetIO(object.NewIO(os.Stdin, os.Stdout, os.Stderr))
+	env.SetFS(fsys)
+	return env, nil
+}
+
+// runTests implements `jian test [-run regexp] [dir]` and returns the
+// process exit code.
+func runTests(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	run := flags.String("run", "", "only run tests whose name matches `regexp`")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: jian test [-run regexp] [dir]\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	fsys, err := filesystem.OS(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jian test: %s\n", err)
+		return 2
+	}
+
+	opts := testrunner.Options{Out: os.Stdout, FS: fsys}
+	if *run != "" {
+		filter, err := regexp.Compile(*run)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "jian test: invalid -run expression: %s\n", err)
+			return 2
+		}
+		opts.Run = filter
+	}
+
+	summary, err := testrunner.Run(dir, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jian test: %s\n", err)
+		return 2
+	}
+	if !summary.OK() {
+		return 1
+	}
+	return 0
+}
+
+// runParse implements `jian parse [--json] [--optimize] file` and returns
+// the process exit code. It prints the parsed program, as JSON with --json,
+// after running the optimizer with --optimize.
+func runParse(args []string) int {
+	flags := flag.NewFlagSet("parse", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the syntax tree as JSON")
+	optimize := flags.Bool("optimize", false, "print the program as the optimizer leaves it")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: jian parse [--json] [--optimize] file\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	source, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jian parse: %s\n", err)
+		return 2
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		for _, msg := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Arg(0), msg)
+		}
+		return 1
+	}
+
+	if *optimize {
+		program = optimizer.Optimize(program)
+	}
+
+	if !*asJSON {
+		fmt.Println(program.String())
+		return 0
+	}
+
+	data, err := astjson.MarshalIndent(program, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jian parse: %s\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+// runCheck implements `jian check file...` and returns the process exit
+// code. It type-checks each file after expanding its macros and prints the
+// errors it finds.
+func runCheck(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: jian check file...\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, file := range flags.Args() {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "jian check: %s\n", err)
+			return 2
+		}
+
+		p := parser.New(lexer.New(string(source)))
+		program := p.ParseProgram()
+		if errs := p.Errors(); len(errs) != 0 {
+			for _, msg := range errs {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", file, msg)
+			}
+			status = 1
+			continue
+		}
+
+		env := object.NewEnvironment()
+		evaluator.DefineMacros(program, env)
+		expanded, err := evaluator.ExpandMacros(program, env)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			status = 1
+			continue
+		}
+
+		for _, e := range checker.Check(expanded.(*ast.Program)) {
+			fmt.Fprintf(os.Stderr, "%s:%s\n", file, e)
+			status = 1
+		}
+	}
+	return status
 }
@@ -1,9 +1,8 @@
 package object
 
-import "strings"
-
 type Array struct {
 	Elements []Object
+	Frozen   bool // set by Freeze; frozen arrays cannot be modified
 }
 
 func (a *Array) Type() ObjectType {
@@ -11,16 +10,17 @@ func (a *Array) Type() ObjectType {
 }
 
 func (a *Array) Inspect() string {
-	var out strings.Builder
+	return inspect(a, map[Object]bool{})
+}
 
-	elements := make([]string, 0, len(a.Elements))
-	for _, element := range a.Elements {
-		elements = append(elements, element.Inspect())
+// HashKey combines the hash keys of the elements. It is only meaningful when
+// IsHashable(a) is true.
+func (a *Array) HashKey() HashKey {
+	data := make([]byte, 0, 8*len(a.Elements))
+	for _, el := range a.Elements {
+		if h, ok := el.(Hashable); ok {
+			data = append(data, uint64Bytes(h.HashKey().Value)...)
+		}
 	}
-
-	out.WriteByte('[')
-	out.WriteString(strings.Join(elements, ", "))
-	out.WriteByte(']')
-
-	return out.String()
+	return hashKeyOf(ARRAY, data)
 }
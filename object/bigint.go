@@ -0,0 +1,48 @@
+package object
+
+import "math/big"
+
+// BigInteger is an integer that does not fit in an int64. It has the same
+// INTEGER type as Integer, and values that fit in an int64 are always
+// represented as an Integer instead, so each integer value has exactly one
+// representation. Use NewBigInteger to build one.
+type BigInteger struct {
+	Value *big.Int
+}
+
+// NewBigInteger returns v as an *Integer when it fits in an int64 and as a
+// *BigInteger otherwise. It takes ownership of v.
+func NewBigInteger(v *big.Int) Object {
+	if v.IsInt64() {
+		return &Integer{Value: v.Int64()}
+	}
+	return &BigInteger{Value: v}
+}
+
+// BigValue returns the value of an *Integer or *BigInteger as a new big.Int.
+func BigValue(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInteger:
+		return new(big.Int).Set(obj.Value), true
+	default:
+		return nil, false
+	}
+}
+
+func (b *BigInteger) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInteger) Type() ObjectType {
+	return INTEGER
+}
+
+func (b *BigInteger) HashKey() HashKey {
+	sign := byte(0)
+	if b.Value.Sign() < 0 {
+		sign = 1
+	}
+	return hashKeyOf(INTEGER, append([]byte{sign}, b.Value.Bytes()...))
+}
@@ -20,5 +20,5 @@ func (b *Boolean) HashKey() HashKey {
 		value = 1
 	}
 
-	return HashKey{Type: BOOLEAN, Value: value}
+	return hashKeyOf(BOOLEAN, uint64Bytes(value))
 }
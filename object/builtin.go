@@ -1,9 +1,14 @@
 package object
 
-type BuiltinFunction func(args ...Object) Object
+// BuiltinFunction receives the environment of the call site, which gives
+// builtins access to host configuration such as the I/O streams.
+type BuiltinFunction func(env *Environment, args ...Object) Object
 
 type Builtin struct {
 	Fn BuiltinFunction
+	// Signature, if set, is checked before Fn is called and lets callers
+	// pass keyword arguments.
+	Signature *Signature
 }
 
 func (b *Builtin) Type() ObjectType {
@@ -11,5 +16,8 @@ func (b *Builtin) Type() ObjectType {
 }
 
 func (b *Builtin) Inspect() string {
+	if b.Signature != nil {
+		return "builtin function" + b.Signature.String()
+	}
 	return "builtin function"
 }
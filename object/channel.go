@@ -0,0 +1,73 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrClosedChannel = errors.New("channel is closed")
+
+type Channel struct {
+	ch     chan Object
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewChannel(capacity int) *Channel {
+	return &Channel{ch: make(chan Object, capacity)}
+}
+
+func (c *Channel) Type() ObjectType {
+	return CHANNEL
+}
+
+func (c *Channel) Inspect() string {
+	return fmt.Sprintf("channel(%d)", cap(c.ch))
+}
+
+// Chan exposes the underlying Go channel so the evaluator can wait on
+// several channels at once in a select expression.
+func (c *Channel) Chan() chan Object {
+	return c.ch
+}
+
+// Send blocks until value has been delivered or buffered. Sending on a
+// closed channel, including one closed while Send is blocked, returns
+// ErrClosedChannel.
+func (c *Channel) Send(value Object) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = ErrClosedChannel
+		}
+	}()
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return ErrClosedChannel
+	}
+
+	c.ch <- value
+	return nil
+}
+
+// Recv blocks until a value is available. ok is false once the channel has
+// been closed and drained.
+func (c *Channel) Recv() (value Object, ok bool) {
+	value, ok = <-c.ch
+	return value, ok
+}
+
+func (c *Channel) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosedChannel
+	}
+	c.closed = true
+	close(c.ch)
+	return nil
+}
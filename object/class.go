@@ -0,0 +1,108 @@
+package object
+
+import "sync"
+
+// Class is a type declared with class. Its methods are functions that run
+// with self bound to an instance, and it inherits the methods of Super that
+// it does not define itself.
+type Class struct {
+	Name    string
+	Super   *Class
+	Methods map[string]*Function
+}
+
+func (c *Class) Type() ObjectType {
+	return CLASS
+}
+
+func (c *Class) Inspect() string {
+	if c.Super != nil {
+		return "class " + c.Name + " extends " + c.Super.Name
+	}
+	return "class " + c.Name
+}
+
+// Method looks up a method in c and then in its ancestors. It returns the
+// method and the class that defines it, or nils if there is no such method.
+func (c *Class) Method(name string) (*Function, *Class) {
+	for class := c; class != nil; class = class.Super {
+		if method, ok := class.Methods[name]; ok {
+			return method, class
+		}
+	}
+	return nil, nil
+}
+
+// Extends reports whether c is other or inherits from it.
+func (c *Class) Extends(other *Class) bool {
+	for class := c; class != nil; class = class.Super {
+		if class == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Instance is an object created by calling a class. Its fields are created
+// by assigning to them, usually in the init method. An Instance is safe for
+// use by concurrent tasks.
+type Instance struct {
+	Class  *Class
+	mu     sync.RWMutex
+	fields []string // field names in the order they were first assigned
+	values map[string]Object
+	Frozen bool // set by Freeze; the fields of frozen instances cannot be assigned
+}
+
+func NewInstance(class *Class) *Instance {
+	return &Instance{Class: class, values: map[string]Object{}}
+}
+
+func (i *Instance) Type() ObjectType {
+	return INSTANCE
+}
+
+// Inspect shows the class and the fields, such as Dog{name: rex}.
+func (i *Instance) Inspect() string {
+	return inspect(i, map[Object]bool{})
+}
+
+// Fields returns the names of the fields in the order they were created.
+func (i *Instance) Fields() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]string(nil), i.fields...)
+}
+
+func (i *Instance) Get(field string) (Object, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	value, ok := i.values[field]
+	return value, ok
+}
+
+// Set stores value in the named field, creating it if needed.
+func (i *Instance) Set(field string, value Object) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, ok := i.values[field]; !ok {
+		i.fields = append(i.fields, field)
+	}
+	i.values[field] = value
+}
+
+// Super is the value of super in a method: it looks up methods starting at
+// Class, the parent of the class defining the method, and binds them to
+// Self.
+type Super struct {
+	Self  *Instance
+	Class *Class
+}
+
+func (s *Super) Type() ObjectType {
+	return SUPER
+}
+
+func (s *Super) Inspect() string {
+	return "super"
+}
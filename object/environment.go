@@ -1,8 +1,22 @@
 package object
 
+import (
+	"sync"
+
+	"github.com/ekediala/jian/filesystem"
+)
+
+// Environment is safe for concurrent use: functions started with spawn share
+// the environments they close over with the goroutine that created them.
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	mu     sync.RWMutex
+	store  map[string]Object
+	consts map[string]bool // names in store bound by SetConst
+	outer  *Environment
+	io     *IO
+	fs     filesystem.FS
+	strict bool
+	yield  func(Object) Object
 }
 
 func NewEnvironment() *Environment {
@@ -20,7 +34,10 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 }
 
 func (e *Environment) Get(key string) (Object, bool) {
+	e.mu.RLock()
 	obj, ok := e.store[key]
+	e.mu.RUnlock()
+
 	if !ok && e.outer != nil {
 		obj, ok = e.outer.Get(key)
 	}
@@ -29,6 +46,98 @@ func (e *Environment) Get(key string) (Object, bool) {
 }
 
 func (e *Environment) Set(key string, value Object) Object {
+	e.mu.Lock()
+	e.store[key] = value
+	e.mu.Unlock()
+	return value
+}
+
+// SetConst binds key to value as a constant. Declarations in e cannot
+// rebind a constant, though environments enclosed by e may shadow it. Hosts
+// use SetConst, usually with Freeze, to give programs read-only globals.
+func (e *Environment) SetConst(key string, value Object) Object {
+	e.mu.Lock()
 	e.store[key] = value
+	if e.consts == nil {
+		e.consts = map[string]bool{}
+	}
+	e.consts[key] = true
+	e.mu.Unlock()
 	return value
 }
+
+// IsConst reports whether key is bound as a constant in e itself, rather
+// than in an environment enclosing it.
+func (e *Environment) IsConst(key string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.consts[key]
+}
+
+// IO returns the streams of the outermost environment, falling back to the
+// process's standard streams.
+func (e *Environment) IO() *IO {
+	for e.outer != nil {
+		e = e.outer
+	}
+
+	if e.io == nil {
+		return stdio
+	}
+	return e.io
+}
+
+// SetIO sets the streams used by every environment enclosed by e. It should
+// be called on the global environment before evaluation starts.
+func (e *Environment) SetIO(streams *IO) {
+	e.io = streams
+}
+
+// FS returns the filesystem of the outermost environment. Without one,
+// programs have no file access at all.
+func (e *Environment) FS() filesystem.FS {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e.fs
+}
+
+// SetFS sets the filesystem used by the file builtins in every environment
+// enclosed by e.
+func (e *Environment) SetFS(fsys filesystem.FS) {
+	e.fs = fsys
+}
+
+// Strict reports whether type annotations are checked at runtime, which is
+// decided by the outermost environment.
+func (e *Environment) Strict() bool {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e.strict
+}
+
+// SetStrict turns runtime checking of type annotations on or off for every
+// environment enclosed by e.
+func (e *Environment) SetStrict(strict bool) {
+	e.strict = strict
+}
+
+// Yield returns the function that yield statements evaluated in e use to
+// hand a value to the caller of the innermost generator running e, and
+// false outside generators.
+func (e *Environment) Yield() (func(Object) Object, bool) {
+	for ; e != nil; e = e.outer {
+		if e.yield != nil {
+			return e.yield, true
+		}
+	}
+	return nil, false
+}
+
+// SetYield sets the function yield statements evaluated in e and the
+// environments it encloses use. It is set on the environment of the body
+// of a generator function before the body starts.
+func (e *Environment) SetYield(yield func(Object) Object) {
+	e.yield = yield
+}
@@ -0,0 +1,39 @@
+package object_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestEnvironmentConcurrentAccess(t *testing.T) {
+	global := object.NewEnvironment()
+	global.Set("shared", &object.Integer{Value: 1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			local := object.NewEnclosedEnvironment(global)
+			for j := 0; j < 100; j++ {
+				name := fmt.Sprintf("v%d", j%5)
+				global.Set(name, &object.Integer{Value: int64(j)})
+				local.Set(name, &object.Integer{Value: int64(i)})
+				if _, ok := local.Get("shared"); !ok {
+					t.Errorf("shared binding not visible from enclosed environment")
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for j := 0; j < 5; j++ {
+		if _, ok := global.Get(fmt.Sprintf("v%d", j)); !ok {
+			t.Errorf("expected v%d to be bound", j)
+		}
+	}
+}
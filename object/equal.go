@@ -0,0 +1,175 @@
+package object
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Equal reports whether a and b are structurally equal. Values of different
+// types are never equal. Arrays are equal when their elements are, hashes
+// when they hold equal values under the same keys regardless of order,
+// structs when they have the same type and equal fields, and functions when
+// they come from the same literal evaluated in the same environment.
+// Everything else is compared by identity. Values that contain themselves
+// are equal when no difference is found on the way round.
+func Equal(a, b Object) bool {
+	return equal(a, b, nil)
+}
+
+// comparison is a pair of values being compared by equal or compare.
+type comparison struct{ a, b Object }
+
+// enter records that a and b are being compared and reports whether they
+// already were, further out. A comparison that meets itself again has
+// found no difference on the way round, so it can stop there.
+func enter(comparing *map[comparison]bool, a, b Object) bool {
+	if *comparing == nil {
+		*comparing = map[comparison]bool{}
+	}
+	c := comparison{a, b}
+	if (*comparing)[c] {
+		return true
+	}
+	(*comparing)[c] = true
+	return false
+}
+
+func equal(a, b Object, comparing map[comparison]bool) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		other, ok := b.(*Integer)
+		return ok && a.Value == other.Value
+	case *BigInteger:
+		other, ok := b.(*BigInteger)
+		return ok && a.Value.Cmp(other.Value) == 0
+	case *Float:
+		return a.Value == b.(*Float).Value
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *Null:
+		return true
+	case *Array:
+		other := b.(*Array)
+		if len(a.Elements) != len(other.Elements) {
+			return false
+		}
+		if enter(&comparing, a, other) {
+			return true
+		}
+		defer delete(comparing, comparison{a, other})
+		for i := range a.Elements {
+			if !equal(a.Elements[i], other.Elements[i], comparing) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		other := b.(*Hash)
+		if a.Len() != other.Len() {
+			return false
+		}
+		if enter(&comparing, a, other) {
+			return true
+		}
+		defer delete(comparing, comparison{a, other})
+		for _, pair := range a.OrderedPairs() {
+			value, ok := other.Get(pair.Key.(Hashable))
+			if !ok || !equal(pair.Value, value, comparing) {
+				return false
+			}
+		}
+		return true
+	case *Function:
+		other := b.(*Function)
+		return a.Body == other.Body && a.Env == other.Env
+	case *Struct:
+		other := b.(*Struct)
+		if a.Def != other.Def {
+			return false
+		}
+		if enter(&comparing, a, other) {
+			return true
+		}
+		defer delete(comparing, comparison{a, other})
+		for i := range a.Values {
+			if !equal(a.Values[i], other.Values[i], comparing) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// Compare orders two integers, two floats, two strings, or two arrays.
+// Arrays are compared lexicographically, element by element. It returns a
+// negative number when a < b, zero when they are equal and a positive
+// number when a > b.
+func Compare(a, b Object) (int, error) {
+	return compare(a, b, nil)
+}
+
+func compare(a, b Object, comparing map[comparison]bool) (int, error) {
+	switch a := a.(type) {
+	case *Integer:
+		if b, ok := b.(*Integer); ok {
+			switch {
+			case a.Value < b.Value:
+				return -1, nil
+			case a.Value > b.Value:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+		if b, ok := b.(*BigInteger); ok {
+			return big.NewInt(a.Value).Cmp(b.Value), nil
+		}
+	case *BigInteger:
+		if b, ok := BigValue(b); ok {
+			return a.Value.Cmp(b), nil
+		}
+	case *Float:
+		if b, ok := b.(*Float); ok {
+			switch {
+			case a.Value < b.Value:
+				return -1, nil
+			case a.Value > b.Value:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	case *String:
+		if b, ok := b.(*String); ok {
+			return strings.Compare(a.Value, b.Value), nil
+		}
+	case *Array:
+		if b, ok := b.(*Array); ok {
+			if enter(&comparing, a, b) {
+				return 0, nil
+			}
+			defer delete(comparing, comparison{a, b})
+			for i := 0; i < len(a.Elements) && i < len(b.Elements); i++ {
+				c, err := compare(a.Elements[i], b.Elements[i], comparing)
+				if err != nil || c != 0 {
+					return c, err
+				}
+			}
+			return len(a.Elements) - len(b.Elements), nil
+		}
+	}
+
+	return 0, fmt.Errorf("cannot order %s and %s", a.Type(), b.Type())
+}
@@ -0,0 +1,145 @@
+package object_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestEqual(t *testing.T) {
+	ints := func(values ...int64) *object.Array {
+		arr := &object.Array{}
+		for _, v := range values {
+			arr.Elements = append(arr.Elements, &object.Integer{Value: v})
+		}
+		return arr
+	}
+	hash := func(pairs ...object.Object) *object.Hash {
+		h := object.NewHash(0)
+		for i := 0; i < len(pairs); i += 2 {
+			h.Set(pairs[i].(object.Hashable), pairs[i+1])
+		}
+		return h
+	}
+	str := func(s string) *object.String { return &object.String{Value: s} }
+
+	env := object.NewEnvironment()
+	fn := &object.Function{Env: env}
+
+	point := object.NewStructType("Point", []string{"x", "y"})
+	pair := object.NewStructType("Pair", []string{"x", "y"})
+	newStruct := func(st *object.StructType, values ...object.Object) *object.Struct {
+		return &object.Struct{Def: st, Values: values}
+	}
+
+	tests := []struct {
+		a, b     object.Object
+		expected bool
+	}{
+		{ints(1, 2), ints(1, 2), true},
+		{ints(1, 2), ints(1), false},
+		{&object.Array{Elements: []object.Object{ints(1), str("x")}}, &object.Array{Elements: []object.Object{ints(1), str("x")}}, true},
+		{hash(str("a"), ints(1), str("b"), str("c")), hash(str("b"), str("c"), str("a"), ints(1)), true},
+		{hash(str("a"), ints(1)), hash(str("a"), ints(2)), false},
+		{hash(str("a"), ints(1)), hash(str("b"), ints(1)), false},
+		{&object.Null{}, &object.Null{}, true},
+		{&object.Null{}, &object.Boolean{}, false},
+		{&object.Integer{Value: 1}, str("1"), false},
+		{&object.Float{Value: 1.5}, &object.Float{Value: 1.5}, true},
+		{&object.Float{Value: 1}, &object.Integer{Value: 1}, false},
+		{fn, fn, true},
+		{fn, &object.Function{Env: env}, true},
+		{fn, &object.Function{Env: object.NewEnvironment()}, false},
+		{object.NewChannel(0), object.NewChannel(0), false},
+		{newStruct(point, ints(1), str("a")), newStruct(point, ints(1), str("a")), true},
+		{newStruct(point, ints(1), str("a")), newStruct(point, ints(2), str("a")), false},
+		{newStruct(point, ints(1), str("a")), newStruct(pair, ints(1), str("a")), false},
+	}
+
+	for i, tt := range tests {
+		if got := object.Equal(tt.a, tt.b); got != tt.expected {
+			t.Errorf("tests[%d]: Equal(%s, %s): expected %t, got %t", i, tt.a.Inspect(), tt.b.Inspect(), tt.expected, got)
+		}
+		if got := object.Equal(tt.b, tt.a); got != tt.expected {
+			t.Errorf("tests[%d]: Equal is not symmetric for %s and %s", i, tt.a.Inspect(), tt.b.Inspect())
+		}
+	}
+}
+
+func TestCyclicValues(t *testing.T) {
+	cyclicHash := func(n int64) *object.Hash {
+		h := object.NewHash(0)
+		h.Set(&object.String{Value: "n"}, &object.Integer{Value: n})
+		h.Set(&object.String{Value: "self"}, h)
+		return h
+	}
+	cyclicArray := func(n int64) *object.Array {
+		arr := &object.Array{Elements: []object.Object{&object.Integer{Value: n}, nil}}
+		arr.Elements[1] = &object.Array{Elements: []object.Object{arr}}
+		return arr
+	}
+
+	if got := cyclicHash(1).Inspect(); got != "{n: 1,self: {...}}" {
+		t.Errorf("expected {n: 1,self: {...}}, got %s", got)
+	}
+	if got := cyclicArray(1).Inspect(); got != "[1, [[...]]]" {
+		t.Errorf("expected [1, [[...]]], got %s", got)
+	}
+
+	if !object.Equal(cyclicHash(1), cyclicHash(1)) {
+		t.Errorf("expected equal cyclic hashes to be Equal")
+	}
+	if object.Equal(cyclicHash(1), cyclicHash(2)) {
+		t.Errorf("expected cyclic hashes with different fields not to be Equal")
+	}
+	if !object.Equal(cyclicArray(1), cyclicArray(1)) {
+		t.Errorf("expected equal cyclic arrays to be Equal")
+	}
+	if got, err := object.Compare(cyclicArray(1), cyclicArray(2)); err != nil || got != -1 {
+		t.Errorf("expected Compare to order cyclic arrays by their first element, got %d (%v)", got, err)
+	}
+	if got, err := object.Compare(cyclicArray(1), cyclicArray(1)); err != nil || got != 0 {
+		t.Errorf("expected equal cyclic arrays to Compare as 0, got %d (%v)", got, err)
+	}
+	if object.IsHashable(cyclicArray(1)) {
+		t.Errorf("expected a cyclic array not to be hashable")
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	big1, _ := new(big.Int).SetString("100000000000000000000", 10)
+	big2, _ := new(big.Int).SetString("100000000000000000000", 10)
+
+	a := object.NewBigInteger(big1)
+	b := object.NewBigInteger(big2)
+	if _, ok := a.(*object.BigInteger); !ok {
+		t.Fatalf("NewBigInteger did not return *object.BigInteger. got=%T", a)
+	}
+	if !object.Equal(a, b) {
+		t.Errorf("equal big integers are not Equal")
+	}
+	if a.(object.Hashable).HashKey() != b.(object.Hashable).HashKey() {
+		t.Errorf("equal big integers have different hash keys")
+	}
+	neg := object.NewBigInteger(new(big.Int).Neg(big1))
+	if a.(object.Hashable).HashKey() == neg.(object.Hashable).HashKey() {
+		t.Errorf("big integers of opposite sign have the same hash key")
+	}
+
+	small := object.NewBigInteger(big.NewInt(42))
+	integer, ok := small.(*object.Integer)
+	if !ok || integer.Value != 42 {
+		t.Fatalf("NewBigInteger did not normalise to *object.Integer. got=%T (%+v)", small, small)
+	}
+	if object.Equal(a, small) {
+		t.Errorf("different integers are Equal")
+	}
+
+	if c, err := object.Compare(small, a); err != nil || c >= 0 {
+		t.Errorf("Compare(42, %s) = %d, %v; want negative", a.Inspect(), c, err)
+	}
+	if c, err := object.Compare(neg, small); err != nil || c >= 0 {
+		t.Errorf("Compare(%s, 42) = %d, %v; want negative", neg.Inspect(), c, err)
+	}
+}
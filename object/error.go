@@ -1,9 +1,33 @@
 package object
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Error struct {
 	Message string
+	// Trace lists the calls the error propagated out of, innermost first.
+	Trace []Frame
+}
+
+// Frame is a call to a Jian function that was in progress when an error
+// occurred.
+type Frame struct {
+	Function string // the function's name, or "" if it has none
+	Line     int    // the position of the call, or 0 if unknown
+	Column   int
+}
+
+func (f Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = "<anonymous>"
+	}
+	if f.Line == 0 {
+		return "at " + name
+	}
+	return fmt.Sprintf("at %s (%d:%d)", name, f.Line, f.Column)
 }
 
 func (e *Error) Inspect() string {
@@ -24,3 +48,21 @@ func NewError(format string, args ...interface{}) *Error {
 	}
 	return &error
 }
+
+// WithFrame returns a copy of e with frame added to the end of its trace.
+func (e *Error) WithFrame(frame Frame) *Error {
+	trace := make([]Frame, len(e.Trace), len(e.Trace)+1)
+	copy(trace, e.Trace)
+	return &Error{Message: e.Message, Trace: append(trace, frame)}
+}
+
+// Traceback returns the message followed by the trace, one frame per line.
+func (e *Error) Traceback() string {
+	var out strings.Builder
+	out.WriteString(e.Message)
+	for _, frame := range e.Trace {
+		out.WriteString("\n    ")
+		out.WriteString(frame.String())
+	}
+	return out.String()
+}
@@ -0,0 +1,26 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Float is a 64-bit floating-point number. Jian has no float literals;
+// floats come from decoding JSON numbers with a fraction or an exponent.
+type Float struct {
+	Value float64
+}
+
+// Inspect formats the value with as few digits as identify it, keeping a
+// decimal point so that it does not read as an integer: 2.0, 0.5, 1e+21.
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT
+}
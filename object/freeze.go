@@ -0,0 +1,76 @@
+package object
+
+// Freeze makes value immutable, along with every array, hash, struct and
+// instance it contains, and returns it. Values that are already frozen are
+// left alone, so values that contain themselves are frozen only once.
+func Freeze(value Object) Object {
+	switch value := value.(type) {
+	case *Array:
+		if value.Frozen {
+			break
+		}
+		value.Frozen = true
+		for _, el := range value.Elements {
+			Freeze(el)
+		}
+	case *Hash:
+		if value.Frozen {
+			break
+		}
+		value.Frozen = true
+		for _, pair := range value.OrderedPairs() {
+			Freeze(pair.Value)
+		}
+	case *Struct:
+		if value.Frozen {
+			break
+		}
+		value.Frozen = true
+		for _, field := range value.Values {
+			Freeze(field)
+		}
+	case *Instance:
+		if value.Frozen {
+			break
+		}
+		value.Frozen = true
+		for _, field := range value.Fields() {
+			v, _ := value.Get(field)
+			Freeze(v)
+		}
+	}
+	return value
+}
+
+// IsFrozen reports whether value can no longer be modified. Values that can
+// never be modified, such as integers and strings, are not frozen.
+func IsFrozen(value Object) bool {
+	switch value := value.(type) {
+	case *Array:
+		return value.Frozen
+	case *Hash:
+		return value.Frozen
+	case *Struct:
+		return value.Frozen
+	case *Instance:
+		return value.Frozen
+	default:
+		return false
+	}
+}
+
+// frozenKey returns a frozen copy of an array used as a hash key. Hashable
+// arrays only contain hashable values, so only nested arrays need copying.
+func frozenKey(arr *Array) *Array {
+	if arr.Frozen {
+		return arr
+	}
+	elements := make([]Object, len(arr.Elements))
+	for i, el := range arr.Elements {
+		if nested, ok := el.(*Array); ok {
+			el = frozenKey(nested)
+		}
+		elements[i] = el
+	}
+	return &Array{Elements: elements, Frozen: true}
+}
@@ -1,38 +1,60 @@
 package object
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/ekediala/jian/ast"
 )
 
 type Function struct {
+	Name       string // the declared or inferred name, "" for anonymous functions
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // parallel to Parameters; nil entries are required
+	Patterns   []ast.Pattern    // parallel to Parameters; non-nil entries destructure
+	Rest       *ast.Identifier
+	ParamTypes []ast.TypeExpression // parallel to Parameters; checked in strict mode
+	RestType   ast.TypeExpression
+	ReturnType ast.TypeExpression
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Generator  bool // whether Body yields, so that calls return a Generator
 }
 
 func (f *Function) Type() ObjectType {
 	return FUNCTION
 }
 
+// Inspect returns the function's name and arity, such as <fn add/2>. A
+// function with a rest parameter shows a "+" after its arity.
 func (f *Function) Inspect() string {
 	var out strings.Builder
-	params := make([]string, 0, len(f.Parameters))
-
-	for _, param := range f.Parameters {
-		params = append(params, param.Value)
+	out.WriteString("<fn")
+	if f.Name != "" {
+		out.WriteString(" ")
+		out.WriteString(f.Name)
 	}
-
-	out.WriteString("fn (")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString("){\n")
-	out.WriteString(f.Body.String())
-	out.WriteString("\n}")
-
+	fmt.Fprintf(&out, "/%d", len(f.Parameters))
+	if f.Rest != nil {
+		out.WriteString("+")
+	}
+	out.WriteString(">")
 	return out.String()
 }
 
 func NewFunction(params []*ast.Identifier, body *ast.BlockStatement, env *Environment) *Function {
-	return &Function{params, body, env}
+	return &Function{Parameters: params, Body: body, Env: env}
+}
+
+// Arity returns the fewest and the most arguments f accepts; max is -1 when
+// f has a rest parameter.
+func (f *Function) Arity() (min, max int) {
+	min = len(f.Parameters)
+	for min > 0 && min <= len(f.Defaults) && f.Defaults[min-1] != nil {
+		min--
+	}
+	if f.Rest != nil {
+		return min, -1
+	}
+	return min, len(f.Parameters)
 }
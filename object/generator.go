@@ -0,0 +1,99 @@
+package object
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Generator produces a sequence of values one at a time, when they are asked
+// for. Calling a generator function returns one, as do builtins such as
+// map_iter.
+//
+// A generator that is no longer reachable is stopped when it is garbage
+// collected, which releases the goroutine running a generator function.
+// The goroutine itself keeps everything the function's body can reach
+// alive, so a generator reachable from its own body is only released once
+// it is stopped or runs to the end.
+type Generator struct {
+	Name string // the name of the generator function, "" if it has none
+
+	mu      sync.Mutex
+	next    func() (Object, bool)
+	stop    func()
+	running bool
+	done    bool
+}
+
+// NewGenerator returns a generator whose values come from next, which
+// reports false once there are none left. stop, if not nil, releases what
+// next holds; it is called once the generator has ended or been stopped.
+func NewGenerator(name string, next func() (Object, bool), stop func()) *Generator {
+	g := &Generator{Name: name, next: next, stop: stop}
+	if stop != nil {
+		runtime.SetFinalizer(g, (*Generator).Stop)
+	}
+	return g
+}
+
+func (g *Generator) Type() ObjectType {
+	return GENERATOR
+}
+
+func (g *Generator) Inspect() string {
+	if g.Name == "" {
+		return "<generator>"
+	}
+	return "<generator " + g.Name + ">"
+}
+
+// Next returns the next value of g, or false once g has no values left. An
+// error ends g. Asking a generator for a value while it is producing one,
+// as a generator function asking itself does, is an error.
+func (g *Generator) Next() (Object, bool) {
+	g.mu.Lock()
+	if g.done {
+		g.mu.Unlock()
+		return nil, false
+	}
+	if g.running {
+		g.mu.Unlock()
+		return NewError("generator is already running"), true
+	}
+	g.running = true
+	g.mu.Unlock()
+
+	value, ok := g.next()
+
+	g.mu.Lock()
+	g.running = false
+	g.mu.Unlock()
+	if !ok || value.Type() == ERROR {
+		g.Stop()
+	}
+	return value, ok
+}
+
+// Stop ends g early. It is safe to call more than once.
+func (g *Generator) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.done {
+		return
+	}
+	g.done = true
+	if g.stop != nil {
+		g.stop()
+	}
+}
+
+// Done is the value next returns for a generator with no values left.
+type Done struct{}
+
+func (d *Done) Type() ObjectType {
+	return DONE
+}
+
+func (d *Done) Inspect() string {
+	return "done"
+}
@@ -0,0 +1,86 @@
+package object_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+// counter returns a generator over 1 to n and a count of its stop calls.
+func counter(n int64) (*object.Generator, *int) {
+	i := int64(0)
+	stops := 0
+	gen := object.NewGenerator("counter", func() (object.Object, bool) {
+		if i >= n {
+			return nil, false
+		}
+		i++
+		return &object.Integer{Value: i}, true
+	}, func() { stops++ })
+	return gen, &stops
+}
+
+func TestGeneratorEndsOnce(t *testing.T) {
+	gen, stops := counter(2)
+
+	for _, want := range []string{"1", "2"} {
+		value, ok := gen.Next()
+		if !ok || value.Inspect() != want {
+			t.Fatalf("expected %s, got %v (%t)", want, value, ok)
+		}
+	}
+	for range 2 {
+		if value, ok := gen.Next(); ok {
+			t.Fatalf("expected the generator to be exhausted, got %v", value)
+		}
+	}
+	gen.Stop()
+	if *stops != 1 {
+		t.Errorf("expected stop to be called once, got %d", *stops)
+	}
+}
+
+func TestGeneratorStop(t *testing.T) {
+	gen, stops := counter(5)
+	gen.Next()
+	gen.Stop()
+	gen.Stop()
+
+	if value, ok := gen.Next(); ok {
+		t.Errorf("expected a stopped generator to give no values, got %v", value)
+	}
+	if *stops != 1 {
+		t.Errorf("expected stop to be called once, got %d", *stops)
+	}
+}
+
+func TestGeneratorEndsAfterError(t *testing.T) {
+	calls := 0
+	gen := object.NewGenerator("", func() (object.Object, bool) {
+		calls++
+		return object.NewError("boom"), true
+	}, nil)
+
+	if value, ok := gen.Next(); !ok || value.Type() != object.ERROR {
+		t.Fatalf("expected the error, got %v (%t)", value, ok)
+	}
+	if value, ok := gen.Next(); ok {
+		t.Errorf("expected no values after an error, got %v", value)
+	}
+	if calls != 1 {
+		t.Errorf("expected next to be called once, got %d", calls)
+	}
+}
+
+func TestGeneratorRejectsReentrantNext(t *testing.T) {
+	var gen *object.Generator
+	gen = object.NewGenerator("", func() (object.Object, bool) {
+		return gen.Next()
+	}, nil)
+
+	value, ok := gen.Next()
+	errObj, isErr := value.(*object.Error)
+	if !ok || !isErr || errObj.Message != "generator is already running" {
+		t.Errorf("expected an error, got %v (%t)", value, ok)
+	}
+}
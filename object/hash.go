@@ -1,39 +1,90 @@
 package object
 
-import (
-	"fmt"
-	"strings"
-)
+import "sync"
 
 type HashPair struct {
 	Key   Object
 	Value Object
 }
 
+// Hash maps keys to values and remembers the order in which keys were first
+// inserted. Lookups go through the keys' HashKey and then compare the keys
+// themselves, so colliding hash keys never overwrite each other. A Hash is
+// safe for use by concurrent tasks.
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	mu     sync.RWMutex
+	pairs  []HashPair
+	index  map[HashKey][]int // positions in pairs of the keys with a given HashKey
+	Frozen bool              // set by Freeze; frozen hashes cannot be modified
 }
 
-type Hashable interface {
-	HashKey() HashKey
+func NewHash(capacity int) *Hash {
+	return &Hash{
+		pairs: make([]HashPair, 0, capacity),
+		index: make(map[HashKey][]int, capacity),
+	}
 }
 
-func (h *Hash) Type() ObjectType {
-	return HASH
+// find returns the position of key in pairs. The caller must hold h.mu.
+func (h *Hash) find(key Hashable) (int, bool) {
+	for _, i := range h.index[key.HashKey()] {
+		if Equal(h.pairs[i].Key, key) {
+			return i, true
+		}
+	}
+	return -1, false
 }
 
-func (h *Hash) Inspect() string {
-	var s strings.Builder
+// Set binds key to value. Updating an existing key keeps its position. An
+// array used as a new key is copied and frozen, so that modifying the array
+// afterwards cannot change the key.
+func (h *Hash) Set(key Hashable, value Object) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i, ok := h.find(key); ok {
+		h.pairs[i].Value = value
+		return
+	}
+	if arr, ok := key.(*Array); ok {
+		key = frozenKey(arr)
+	}
+
+	hashKey := key.HashKey()
+	h.index[hashKey] = append(h.index[hashKey], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+}
 
-	pairs := make([]string, 0, len(h.Pairs))
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	if i, ok := h.find(key); ok {
+		return h.pairs[i].Value, true
 	}
+	return nil, false
+}
+
+func (h *Hash) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.pairs)
+}
+
+// OrderedPairs returns the pairs in insertion order.
+func (h *Hash) OrderedPairs() []HashPair {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	pairs := make([]HashPair, len(h.pairs))
+	copy(pairs, h.pairs)
+	return pairs
+}
 
-	s.WriteByte('{')
-	s.WriteString(strings.Join(pairs, ","))
-	s.WriteByte('}')
+func (h *Hash) Type() ObjectType {
+	return HASH
+}
 
-	return s.String()
+func (h *Hash) Inspect() string {
+	return inspect(h, map[Object]bool{})
 }
@@ -0,0 +1,139 @@
+package object_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestHashPreservesInsertionOrder(t *testing.T) {
+	h := object.NewHash(0)
+	keys := []string{"zeta", "alpha", "mu", "beta", "omega", "gamma", "delta", "epsilon"}
+	for i, key := range keys {
+		h.Set(&object.String{Value: key}, &object.Integer{Value: int64(i)})
+	}
+
+	// updating an existing key keeps its original position
+	h.Set(&object.String{Value: "mu"}, &object.Integer{Value: 100})
+
+	if h.Len() != len(keys) {
+		t.Fatalf("expected %d pairs, got %d", len(keys), h.Len())
+	}
+
+	for i, pair := range h.OrderedPairs() {
+		if got := pair.Key.Inspect(); got != keys[i] {
+			t.Errorf("pair %d: expected key %q, got %q", i, keys[i], got)
+		}
+	}
+
+	value, ok := h.Get(&object.String{Value: "mu"})
+	if !ok || value.Inspect() != "100" {
+		t.Errorf("expected mu to be 100, got %v", value)
+	}
+
+	expected := "{zeta: 0,alpha: 1,mu: 100,beta: 3,omega: 4,gamma: 5,delta: 6,epsilon: 7}"
+	for i := 0; i < 10; i++ {
+		if got := h.Inspect(); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+}
+
+// collidingKey always produces the same hash key, so distinct instances can
+// only be told apart by comparing the keys themselves.
+type collidingKey struct {
+	name string
+}
+
+func (c *collidingKey) Type() object.ObjectType { return "COLLIDING" }
+func (c *collidingKey) Inspect() string         { return c.name }
+func (c *collidingKey) HashKey() object.HashKey {
+	return object.HashKey{Type: "COLLIDING", Value: 42}
+}
+
+func TestHashCollisionsDoNotOverwrite(t *testing.T) {
+	a, b := &collidingKey{"a"}, &collidingKey{"b"}
+
+	h := object.NewHash(0)
+	h.Set(a, &object.Integer{Value: 1})
+	h.Set(b, &object.Integer{Value: 2})
+
+	if h.Len() != 2 {
+		t.Fatalf("expected 2 pairs, got %d", h.Len())
+	}
+
+	for key, expected := range map[*collidingKey]string{a: "1", b: "2"} {
+		value, ok := h.Get(key)
+		if !ok || value.Inspect() != expected {
+			t.Errorf("%s: expected %s, got %v", key.name, expected, value)
+		}
+	}
+
+	if _, ok := h.Get(&collidingKey{"c"}); ok {
+		t.Errorf("expected lookup of an unknown colliding key to fail")
+	}
+}
+
+func TestHashKeyEquality(t *testing.T) {
+	h := object.NewHash(0)
+	h.Set(&object.Integer{Value: 1}, &object.String{Value: "int"})
+	h.Set(&object.String{Value: "1"}, &object.String{Value: "string"})
+	h.Set(&object.Boolean{Value: true}, &object.String{Value: "bool"})
+	h.Set(&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}, &object.String{Value: "array"})
+
+	tests := []struct {
+		key      object.Hashable
+		expected string
+	}{
+		{&object.Integer{Value: 1}, "int"},
+		{&object.String{Value: "1"}, "string"},
+		{&object.Boolean{Value: true}, "bool"},
+		{&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}, "array"},
+	}
+
+	for _, tt := range tests {
+		value, ok := h.Get(tt.key)
+		if !ok || value.Inspect() != tt.expected {
+			t.Errorf("%s %s: expected %q, got %v", tt.key.Type(), tt.key.Inspect(), tt.expected, value)
+		}
+	}
+}
+
+func TestIsHashable(t *testing.T) {
+	tests := []struct {
+		obj      object.Object
+		expected bool
+	}{
+		{&object.Integer{Value: 1}, true},
+		{&object.Array{}, true},
+		{&object.Array{Elements: []object.Object{&object.Array{Elements: []object.Object{&object.String{}}}}}, true},
+		{&object.Array{Elements: []object.Object{object.NewHash(0)}}, false},
+		{object.NewHash(0), false},
+		{&object.Null{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := object.IsHashable(tt.obj); got != tt.expected {
+			t.Errorf("IsHashable(%s): expected %t, got %t", tt.obj.Inspect(), tt.expected, got)
+		}
+	}
+}
+
+func TestHashCopiesArrayKeys(t *testing.T) {
+	key := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Array{}}}
+	h := object.NewHash(1)
+	h.Set(key, object.Freeze(&object.Array{}))
+
+	key.Elements[0] = &object.Integer{Value: 2}
+
+	if _, ok := h.Get(&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Array{}}}); !ok {
+		t.Fatalf("expected the original key to still be found")
+	}
+	stored := h.OrderedPairs()[0].Key
+	if !object.IsFrozen(stored) || !object.IsFrozen(stored.(*object.Array).Elements[1]) {
+		t.Errorf("expected the stored key to be frozen, got %s", stored.Inspect())
+	}
+	if object.IsFrozen(key) {
+		t.Errorf("expected the array passed as the key to stay mutable")
+	}
+}
@@ -0,0 +1,61 @@
+package object
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// HashKey is a digest of a hashable value. Different values may share a
+// HashKey, so a Hash only uses it to find candidate pairs and then compares
+// the keys themselves.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func hashKeyOf(t ObjectType, data []byte) HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(t))
+	h.Write(data)
+	return HashKey{Type: t, Value: h.Sum64()}
+}
+
+func uint64Bytes(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+// IsHashable reports whether obj can be used as a hash key. Arrays are
+// hashable when all of their elements are, and when they do not contain
+// themselves.
+func IsHashable(obj Object) bool {
+	return isHashable(obj, nil)
+}
+
+func isHashable(obj Object, visiting map[*Array]bool) bool {
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return false
+		}
+		if visiting == nil {
+			visiting = map[*Array]bool{}
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+		for _, el := range obj.Elements {
+			if !isHashable(el, visiting) {
+				return false
+			}
+		}
+		return true
+	case Hashable:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inspect formats value the way its Inspect method does. Arrays, hashes,
+// structs and instances can contain themselves once they are modified, so
+// visiting holds those being formatted, and one met again inside itself is
+// shown as [...], {...} or Name{...} instead of being formatted forever.
+func inspect(value Object, visiting map[Object]bool) string {
+	switch value := value.(type) {
+	case *Array:
+		if visiting[value] {
+			return "[...]"
+		}
+		visiting[value] = true
+		defer delete(visiting, value)
+
+		elements := make([]string, 0, len(value.Elements))
+		for _, element := range value.Elements {
+			elements = append(elements, inspect(element, visiting))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+
+	case *Hash:
+		if visiting[value] {
+			return "{...}"
+		}
+		visiting[value] = true
+		defer delete(visiting, value)
+
+		ordered := value.OrderedPairs()
+		pairs := make([]string, 0, len(ordered))
+		for _, pair := range ordered {
+			pairs = append(pairs, fmt.Sprintf("%s: %s", inspect(pair.Key, visiting), inspect(pair.Value, visiting)))
+		}
+		return "{" + strings.Join(pairs, ",") + "}"
+
+	case *Struct:
+		if visiting[value] {
+			return value.Def.Name + "{...}"
+		}
+		visiting[value] = true
+		defer delete(visiting, value)
+
+		fields := make([]string, 0, len(value.Values))
+		for i, field := range value.Def.Fields() {
+			fields = append(fields, field+": "+inspect(value.Values[i], visiting))
+		}
+		return value.Def.Name + "{" + strings.Join(fields, ", ") + "}"
+
+	case *Instance:
+		if visiting[value] {
+			return value.Class.Name + "{...}"
+		}
+		visiting[value] = true
+		defer delete(visiting, value)
+
+		fields := value.Fields()
+		for i, field := range fields {
+			v, _ := value.Get(field)
+			fields[i] = field + ": " + inspect(v, visiting)
+		}
+		return value.Class.Name + "{" + strings.Join(fields, ", ") + "}"
+
+	default:
+		return value.Inspect()
+	}
+}
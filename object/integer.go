@@ -15,5 +15,5 @@ func (i *Integer) Type() ObjectType {
 }
 
 func (i *Integer) HashKey() HashKey {
-	return HashKey{Type: INTEGER, Value: uint64(i.Value)}
+	return hashKeyOf(INTEGER, uint64Bytes(uint64(i.Value)))
 }
@@ -0,0 +1,69 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// IO holds the streams a program reads from and writes to. Hosts embedding
+// the interpreter attach one to the global environment with SetIO so that
+// output can be captured and several interpreters can run side by side.
+type IO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	writeMu sync.Mutex
+	readMu  sync.Mutex
+	reader  *bufio.Reader
+}
+
+var stdio = NewIO(os.Stdin, os.Stdout, os.Stderr)
+
+func NewIO(stdin io.Reader, stdout, stderr io.Writer) *IO {
+	return &IO{Stdin: stdin, Stdout: stdout, Stderr: stderr}
+}
+
+// Print writes text to Stdout. Writes from concurrent tasks never interleave
+// within a single call.
+func (s *IO) Print(text string) error {
+	return s.write(s.Stdout, text)
+}
+
+// Eprint writes text to Stderr.
+func (s *IO) Eprint(text string) error {
+	return s.write(s.Stderr, text)
+}
+
+func (s *IO) write(w io.Writer, text string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+// ReadLine reads the next line from Stdin without its line ending. It
+// returns io.EOF once the input is exhausted.
+func (s *IO) ReadLine() (string, error) {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+
+	if s.reader == nil {
+		s.reader = bufio.NewReader(s.Stdin)
+	}
+
+	line, err := s.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
@@ -0,0 +1,34 @@
+package object
+
+import (
+	"strings"
+
+	"github.com/ekediala/jian/ast"
+)
+
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType {
+	return MACRO
+}
+
+func (m *Macro) Inspect() string {
+	var out strings.Builder
+	params := make([]string, 0, len(m.Parameters))
+
+	for _, param := range m.Parameters {
+		params = append(params, param.Value)
+	}
+
+	out.WriteString("macro (")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString("){\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
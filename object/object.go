@@ -1,10 +1,5 @@
 package object
 
-type HashKey struct {
-	Type  ObjectType
-	Value uint64
-}
-
 type ObjectType string
 
 func (o ObjectType) String() string {
@@ -13,6 +8,7 @@ func (o ObjectType) String() string {
 
 const (
 	INTEGER      ObjectType = "INTEGER"
+	FLOAT        ObjectType = "FLOAT"
 	BOOLEAN      ObjectType = "BOOLEAN"
 	NULL         ObjectType = "NULL"
 	RETURN_VALUE ObjectType = "RETURN_VALUE"
@@ -22,6 +18,17 @@ const (
 	BUILTIN      ObjectType = "BUILTIN"
 	ARRAY        ObjectType = "ARRAY"
 	HASH         ObjectType = "HASH"
+	TASK         ObjectType = "TASK"
+	CHANNEL      ObjectType = "CHANNEL"
+	QUOTE        ObjectType = "QUOTE"
+	MACRO        ObjectType = "MACRO"
+	STRUCT       ObjectType = "STRUCT"
+	STRUCT_TYPE  ObjectType = "STRUCT_TYPE"
+	CLASS        ObjectType = "CLASS"
+	INSTANCE     ObjectType = "INSTANCE"
+	SUPER        ObjectType = "SUPER"
+	GENERATOR    ObjectType = "GENERATOR"
+	DONE         ObjectType = "DONE"
 )
 
 type Object interface {
@@ -0,0 +1,17 @@
+package object
+
+import "github.com/ekediala/jian/ast"
+
+// Quote is an unevaluated piece of code, produced by quote() and passed to
+// macros in place of their arguments.
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType {
+	return QUOTE
+}
+
+func (q *Quote) Inspect() string {
+	return "QUOTE(" + q.Node.String() + ")"
+}
@@ -0,0 +1,127 @@
+package object
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Keyword is an argument passed by parameter name, as in f(b: 2).
+type Keyword struct {
+	Name  string
+	Value Object
+}
+
+// Signature describes the parameters of a builtin, so that it can be called
+// with keyword arguments and its arity checked before it runs.
+type Signature struct {
+	Params   []string // parameter names, required ones first
+	Required int      // how many of Params are required
+	Variadic string   // the parameter collecting further arguments, if any
+}
+
+// NewSignature builds a signature from parameter names. A trailing "?"
+// marks a parameter as optional, and a leading "..." marks the last
+// parameter as variadic:
+//
+//	NewSignature("value", "indent?")
+//	NewSignature("fn", "...args")
+func NewSignature(params ...string) *Signature {
+	s := &Signature{}
+	for _, param := range params {
+		switch {
+		case strings.HasPrefix(param, "..."):
+			s.Variadic = strings.TrimPrefix(param, "...")
+		case strings.HasSuffix(param, "?"):
+			s.Params = append(s.Params, strings.TrimSuffix(param, "?"))
+		default:
+			s.Params = append(s.Params, param)
+			s.Required = len(s.Params)
+		}
+	}
+	return s
+}
+
+// Max returns the largest number of arguments the signature accepts, or -1
+// if it is variadic.
+func (s *Signature) Max() int {
+	if s.Variadic != "" {
+		return -1
+	}
+	return len(s.Params)
+}
+
+// Check returns an error if got arguments do not fit the signature.
+func (s *Signature) Check(got int) *Error {
+	if got >= s.Required && (s.Max() < 0 || got <= s.Max()) {
+		return nil
+	}
+	if s.Max() < 0 {
+		return NewError("wrong number of arguments. got=%d, want %s", got, DescribeArity(s.Required, -1))
+	}
+	return NewError("wrong number of arguments. got=%d, want=%s", got, DescribeArity(s.Required, s.Max()))
+}
+
+// Bind merges positional arguments and keywords into the argument list the
+// builtin receives. Keywords may name any parameter except the variadic
+// one, but may not leave a gap before a parameter that has a value.
+func (s *Signature) Bind(args []Object, keywords []Keyword) ([]Object, *Error) {
+	if len(keywords) == 0 {
+		return args, s.Check(len(args))
+	}
+
+	slots := make([]Object, len(s.Params))
+	n := copy(slots, args)
+	extra := args[n:]
+	for _, kw := range keywords {
+		i := slices.Index(s.Params, kw.Name)
+		if i < 0 {
+			return nil, NewError("unexpected keyword argument `%s`", kw.Name)
+		}
+		if slots[i] != nil {
+			return nil, NewError("got multiple values for argument `%s`", kw.Name)
+		}
+		slots[i] = kw.Value
+		n = max(n, i+1)
+	}
+
+	for i, slot := range slots[:max(n, s.Required)] {
+		if slot == nil {
+			return nil, NewError("missing argument `%s`", s.Params[i])
+		}
+	}
+	bound := append(slots[:n], extra...)
+	return bound, s.Check(len(bound))
+}
+
+// String formats the signature as a parameter list, such as
+// (value, indent?).
+func (s *Signature) String() string {
+	params := make([]string, 0, len(s.Params)+1)
+	for i, param := range s.Params {
+		if i >= s.Required {
+			param += "?"
+		}
+		params = append(params, param)
+	}
+	if s.Variadic != "" {
+		params = append(params, "..."+s.Variadic)
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
+// DescribeArity describes how many arguments a callable taking between min
+// and max of them accepts, such as "2", "1 or 2", "1 to 3" or "at least 1".
+// A negative max means there is no upper bound.
+func DescribeArity(min, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("at least %d", min)
+	case min == max:
+		return fmt.Sprint(min)
+	case min+1 == max:
+		return fmt.Sprintf("%d or %d", min, max)
+	default:
+		return fmt.Sprintf("%d to %d", min, max)
+	}
+}
@@ -0,0 +1,55 @@
+package object_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/object"
+)
+
+func TestSignature(t *testing.T) {
+	one := &object.Integer{Value: 1}
+	two := &object.Integer{Value: 2}
+
+	tests := []struct {
+		params   []string
+		args     []object.Object
+		keywords []object.Keyword
+		expected int    // number of bound arguments
+		err      string // expected error message, if any
+	}{
+		{[]string{"value"}, []object.Object{one}, nil, 1, ""},
+		{[]string{"value"}, nil, nil, 0, "wrong number of arguments. got=0, want=1"},
+		{[]string{"value", "indent?"}, []object.Object{one, two, one}, nil, 0, "wrong number of arguments. got=3, want=1 or 2"},
+		{[]string{"a", "b?", "c?"}, nil, nil, 0, "wrong number of arguments. got=0, want=1 to 3"},
+		{[]string{"fn", "...args"}, nil, nil, 0, "wrong number of arguments. got=0, want at least 1"},
+		{[]string{"fn", "...args"}, []object.Object{one, two, two}, nil, 3, ""},
+		{[]string{"value", "indent?"}, []object.Object{one}, []object.Keyword{{Name: "indent", Value: two}}, 2, ""},
+		{[]string{"a", "b"}, nil, []object.Keyword{{Name: "b", Value: two}, {Name: "a", Value: one}}, 2, ""},
+		{[]string{"a", "b?", "c?"}, []object.Object{one}, []object.Keyword{{Name: "c", Value: two}}, 0, "missing argument `b`"},
+		{[]string{"a"}, []object.Object{one}, []object.Keyword{{Name: "a", Value: two}}, 0, "got multiple values for argument `a`"},
+		{[]string{"a", "...rest"}, nil, []object.Keyword{{Name: "rest", Value: two}}, 0, "unexpected keyword argument `rest`"},
+	}
+
+	for _, tt := range tests {
+		sig := object.NewSignature(tt.params...)
+		bound, err := sig.Bind(tt.args, tt.keywords)
+		if tt.err != "" {
+			if err == nil || err.Message != tt.err {
+				t.Errorf("%s: expected error %q, got %v", sig, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", sig, err.Message)
+			continue
+		}
+		if len(bound) != tt.expected {
+			t.Errorf("%s: expected %d arguments, got %d", sig, tt.expected, len(bound))
+		}
+	}
+
+	bound, _ := object.NewSignature("a", "b").Bind(nil, []object.Keyword{{Name: "b", Value: two}, {Name: "a", Value: one}})
+	if bound[0] != one || bound[1] != two {
+		t.Errorf("expected keywords bound in parameter order, got %v", bound)
+	}
+}
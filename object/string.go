@@ -1,7 +1,5 @@
 package object
 
-import "hash/fnv"
-
 type String struct {
 	Value string
 }
@@ -15,8 +13,5 @@ func (s *String) Type() ObjectType {
 }
 
 func (s *String) HashKey() HashKey {
-	h := fnv.New64a()
-	h.Write([]byte(s.Value))
-
-	return HashKey{Type: STRING, Value: h.Sum64()}
+	return hashKeyOf(STRING, []byte(s.Value))
 }
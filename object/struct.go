@@ -0,0 +1,80 @@
+package object
+
+import (
+	"slices"
+	"strings"
+)
+
+// StructType is a type declared with struct, such as struct Point { x, y }.
+// Calling it constructs an instance; its arguments are bound to the fields
+// in order or by name, and every field must be given a value.
+type StructType struct {
+	Name      string
+	Signature *Signature // one required parameter per field
+}
+
+func NewStructType(name string, fields []string) *StructType {
+	return &StructType{Name: name, Signature: NewSignature(fields...)}
+}
+
+func (st *StructType) Type() ObjectType {
+	return STRUCT_TYPE
+}
+
+func (st *StructType) Inspect() string {
+	if len(st.Fields()) == 0 {
+		return "struct " + st.Name + " {}"
+	}
+	return "struct " + st.Name + " { " + strings.Join(st.Fields(), ", ") + " }"
+}
+
+// Fields returns the names of the fields in declaration order.
+func (st *StructType) Fields() []string {
+	return st.Signature.Params
+}
+
+// New builds an instance from positional arguments and keywords.
+func (st *StructType) New(args []Object, keywords []Keyword) (*Struct, *Error) {
+	values, errObj := st.Signature.Bind(args, keywords)
+	if errObj != nil {
+		errObj.Message = st.Name + ": " + errObj.Message
+		return nil, errObj
+	}
+	return &Struct{Def: st, Values: slices.Clone(values)}, nil
+}
+
+// Struct is an instance of a StructType. Values holds the fields in the
+// order the type declares them.
+type Struct struct {
+	Def    *StructType
+	Values []Object
+	Frozen bool // set by Freeze; the fields of frozen structs cannot be assigned
+}
+
+func (s *Struct) Type() ObjectType {
+	return STRUCT
+}
+
+// Inspect shows the type and its fields, such as Point{x: 1, y: 2}.
+func (s *Struct) Inspect() string {
+	return inspect(s, map[Object]bool{})
+}
+
+// Get returns the value of the named field.
+func (s *Struct) Get(field string) (Object, bool) {
+	i := slices.Index(s.Def.Fields(), field)
+	if i < 0 {
+		return nil, false
+	}
+	return s.Values[i], true
+}
+
+// Set stores value in the named field, reporting whether the field exists.
+func (s *Struct) Set(field string, value Object) bool {
+	i := slices.Index(s.Def.Fields(), field)
+	if i < 0 {
+		return false
+	}
+	s.Values[i] = value
+	return true
+}
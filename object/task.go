@@ -0,0 +1,37 @@
+package object
+
+// Task is the handle returned by spawn. It runs a function on its own
+// goroutine and holds the result once the function returns.
+type Task struct {
+	done   chan struct{}
+	result Object
+}
+
+func NewTask(run func() Object) *Task {
+	t := &Task{done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		t.result = run()
+	}()
+	return t
+}
+
+func (t *Task) Type() ObjectType {
+	return TASK
+}
+
+func (t *Task) Inspect() string {
+	select {
+	case <-t.done:
+		return "task(done)"
+	default:
+		return "task(running)"
+	}
+}
+
+// Wait blocks until the task has finished and returns its result. It may be
+// called any number of times, from any goroutine.
+func (t *Task) Wait() Object {
+	<-t.done
+	return t.result
+}
@@ -0,0 +1,242 @@
+package optimizer
+
+import "github.com/ekediala/jian/ast"
+
+// analysis records what Optimize needs to know about a program before
+// rewriting it.
+type analysis struct {
+	// quoted holds the nodes inside quote(...) calls, which are code
+	// rather than values and must be kept as written.
+	quoted map[ast.Node]bool
+	// lets holds, by name, the let statements that bind a name no other
+	// let, function declaration, parameter, pattern or select case in the
+	// program binds.
+	lets map[string]*binding
+	// refs holds the identifiers that are read rather than bound.
+	refs map[*ast.Identifier]*reference
+}
+
+type binding struct {
+	scope ast.Node // the Program or BlockStatement holding the let
+	end   int      // the visit index of the last node of the let statement
+}
+
+type reference struct {
+	index  int        // the visit index of the identifier
+	scopes []ast.Node // the Programs and BlockStatements enclosing it
+	// hoisted holds the function declarations enclosing the identifier,
+	// which are bound before anything else in their block runs.
+	hoisted []hoist
+}
+
+type hoist struct {
+	scopes []ast.Node // the Programs and BlockStatements enclosing the declaration
+	start  int        // the visit index of the block the declaration is in
+}
+
+// canInline reports whether the identifier ident always reads the value of
+// the only let statement binding its name: the let comes before ident in
+// the same or an enclosing block. An identifier inside a function
+// declaration counts as being where the declaration is hoisted to, the
+// start of its block, since the function can be called from there.
+func (a *analysis) canInline(ident *ast.Identifier) bool {
+	ref, ok := a.refs[ident]
+	let := a.lets[ident.Value]
+	if !ok || let == nil || ref.index <= let.end {
+		return false
+	}
+	for _, h := range ref.hoisted {
+		if contains(h.scopes, let.scope) && h.start <= let.end {
+			return false
+		}
+	}
+	return contains(ref.scopes, let.scope)
+}
+
+func contains(nodes []ast.Node, node ast.Node) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+func analyze(program *ast.Program) *analysis {
+	a := &analysis{
+		quoted: map[ast.Node]bool{},
+		lets:   map[string]*binding{},
+		refs:   map[*ast.Identifier]*reference{},
+	}
+
+	bound := map[string]int{}
+	var stack []ast.Node
+	var visited []int // the visit index of each node on the stack
+	index, quoteDepth := 0, 0
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node == nil {
+			exited := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			visited = visited[:len(visited)-1]
+			if isQuoteCall(exited) {
+				quoteDepth--
+			}
+			if let, ok := exited.(*ast.LetStatement); ok && let.Name != nil && !a.quoted[let] {
+				a.lets[let.Name.Value].end = index
+			}
+			return false
+		}
+
+		index++
+		var parent ast.Node
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		stack = append(stack, node)
+		visited = append(visited, index)
+
+		if isQuoteCall(node) {
+			quoteDepth++
+		}
+		if quoteDepth > 0 {
+			a.quoted[node] = true
+		}
+
+		if _, ok := node.(*ast.ClassStatement); ok {
+			// methods bind self and super implicitly
+			bound["self"]++
+			bound["super"]++
+		}
+
+		ident, ok := node.(*ast.Identifier)
+		if !ok {
+			return true
+		}
+
+		switch parent := parent.(type) {
+		case *ast.LetStatement:
+			if parent.Name == ident {
+				bound[ident.Value]++
+				if !a.quoted[ident] {
+					a.lets[ident.Value] = &binding{scope: enclosingScope(stack)}
+				}
+				return true
+			}
+		case *ast.FunctionStatement:
+			if parent.Name == ident {
+				bound[ident.Value]++
+				return true
+			}
+		case *ast.ClassStatement:
+			if parent.Name == ident {
+				bound[ident.Value]++
+				return true
+			}
+		case *ast.StructStatement:
+			if parent.Name == ident {
+				bound[ident.Value]++
+			}
+			// fields are names, not reads
+			return true
+		case *ast.FieldExpression:
+			if parent.Field == ident {
+				return true
+			}
+		case *ast.FunctionLiteral:
+			if parent.Rest == ident || isParameter(parent.Parameters, ident) {
+				bound[ident.Value]++
+				return true
+			}
+		case *ast.MacroLiteral, *ast.BindingPattern, *ast.ArrayPattern:
+			bound[ident.Value]++
+			return true
+		case *ast.KeywordArgument:
+			if parent.Name == ident {
+				// a parameter name, not a read
+				return true
+			}
+		case *ast.SelectCase:
+			if parent.Binding == ident {
+				bound[ident.Value]++
+				return true
+			}
+		}
+
+		if !a.quoted[ident] {
+			a.refs[ident] = &reference{index: index, scopes: scopes(stack), hoisted: hoisted(stack, visited)}
+		}
+		return true
+	})
+
+	for name, count := range bound {
+		if count > 1 {
+			delete(a.lets, name)
+		}
+	}
+
+	return a
+}
+
+func isParameter(params []*ast.Identifier, ident *ast.Identifier) bool {
+	for _, param := range params {
+		if param == ident {
+			return true
+		}
+	}
+	return false
+}
+
+func isQuoteCall(node ast.Node) bool {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	return ok && ident.Value == "quote"
+}
+
+func isScope(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Program, *ast.BlockStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+func scopes(stack []ast.Node) []ast.Node {
+	var found []ast.Node
+	for _, node := range stack {
+		if isScope(node) {
+			found = append(found, node)
+		}
+	}
+	return found
+}
+
+// hoisted describes the function declarations on the stack.
+func hoisted(stack []ast.Node, visited []int) []hoist {
+	var found []hoist
+	for i, node := range stack {
+		if _, ok := node.(*ast.FunctionStatement); !ok {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if isScope(stack[j]) {
+				found = append(found, hoist{scopes: scopes(stack[:i]), start: visited[j]})
+				break
+			}
+		}
+	}
+	return found
+}
+
+func enclosingScope(stack []ast.Node) ast.Node {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if isScope(stack[i]) {
+			return stack[i]
+		}
+	}
+	return nil
+}
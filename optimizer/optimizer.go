@@ -0,0 +1,195 @@
+// Package optimizer simplifies parsed programs before they are evaluated.
+//
+// Optimize folds operators applied to literals into a single literal, drops
+// the branch of an if expression that can never run, and replaces names
+// bound once to a literal with the literal itself. Folding is done by the
+// evaluator, so folded results are exactly what evaluation would produce;
+// expressions that would fail at runtime, such as 1 / 0, are left as they are
+// so that the error still happens when the program runs.
+package optimizer
+
+import (
+	"math/big"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/token"
+)
+
+// Optimize returns an optimized copy of program. program itself is not
+// modified.
+func Optimize(program *ast.Program) *ast.Program {
+	return optimize(program, true)
+}
+
+// OptimizeInput is like Optimize for a program evaluated in an environment
+// that outlives it, such as one input of a REPL session. Later input may
+// bind the program's top-level names again, and functions the program
+// defines must then see the new values, so only top-level constants are
+// inlined.
+func OptimizeInput(program *ast.Program) *ast.Program {
+	return optimize(program, false)
+}
+
+// optimize optimizes program, inlining top-level let bindings only if
+// inlineGlobals is set.
+func optimize(program *ast.Program, inlineGlobals bool) *ast.Program {
+	s := analyze(program)
+	constants := map[string]ast.Expression{}
+
+	optimized := ast.Modify(program, func(node ast.Node) ast.Node {
+		switch node := node.(type) {
+		case *ast.PrefixExpression:
+			if isLiteral(node.Right) && !s.quoted[node.Right] {
+				return fold(node, node.Token)
+			}
+
+		case *ast.InfixExpression:
+			if isLiteral(node.Left) && isLiteral(node.Right) && !s.quoted[node.Left] && !s.quoted[node.Right] {
+				return fold(node, tokenOf(node.Left))
+			}
+			// Literals are never null, so the right side is never used.
+			if node.Operator == token.COALESCE && isLiteral(node.Left) && !s.quoted[node.Left] {
+				return node.Left
+			}
+
+		case *ast.IfExpression:
+			if isLiteral(node.Condition) && !s.quoted[node.Condition] {
+				return pruneIf(node)
+			}
+
+		case *ast.LetStatement:
+			if node.Name == nil || !isLiteral(node.Value) {
+				break
+			}
+			if let := s.lets[node.Name.Value]; let != nil &&
+				(inlineGlobals || node.Const || let.scope != program) {
+				constants[node.Name.Value] = node.Value
+			}
+
+		case *ast.Identifier:
+			value, ok := constants[node.Value]
+			if ok && s.canInline(node) {
+				return relocate(value, node.Token)
+			}
+		}
+		return node
+	})
+
+	return optimized.(*ast.Program)
+}
+
+func isLiteral(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.IntegerLiteral, *ast.StringLiteral, *ast.Boolean:
+		return true
+	default:
+		return false
+	}
+}
+
+func tokenOf(exp ast.Expression) token.Token {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return exp.Token
+	case *ast.StringLiteral:
+		return exp.Token
+	case *ast.Boolean:
+		return exp.Token
+	default:
+		return token.Token{}
+	}
+}
+
+// fold evaluates exp, whose operands are literals, and returns the result
+// as a literal positioned at pos. exp is returned unchanged if evaluating it
+// fails.
+func fold(exp ast.Expression, pos token.Token) ast.Node {
+	result := evaluator.Eval(exp, object.NewEnvironment())
+	if literal := literalFor(result, pos); literal != nil {
+		return literal
+	}
+	return exp
+}
+
+// literalFor returns a literal that evaluates to obj, or nil if obj is not
+// an integer, string or boolean.
+func literalFor(obj object.Object, pos token.Token) ast.Expression {
+	tok := token.Token{Line: pos.Line, Column: pos.Column, Literal: obj.Inspect()}
+
+	switch obj := obj.(type) {
+	case *object.Integer:
+		tok.Type = token.INT
+		return &ast.IntegerLiteral{Token: tok, Value: obj.Value}
+	case *object.BigInteger:
+		tok.Type = token.INT
+		return &ast.IntegerLiteral{Token: tok, Big: new(big.Int).Set(obj.Value)}
+	case *object.String:
+		tok.Type = token.STRING
+		return &ast.StringLiteral{Token: tok, Value: obj.Value}
+	case *object.Boolean:
+		tok.Type = token.FALSE
+		if obj.Value {
+			tok.Type = token.TRUE
+		}
+		return &ast.Boolean{Token: tok, Value: obj.Value}
+	default:
+		return nil
+	}
+}
+
+// relocate returns a copy of the literal exp positioned at pos.
+func relocate(exp ast.Expression, pos token.Token) ast.Expression {
+	tok := tokenOf(exp)
+	tok.Line, tok.Column = pos.Line, pos.Column
+
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return &ast.IntegerLiteral{Token: tok, Value: exp.Value, Big: exp.Big}
+	case *ast.StringLiteral:
+		return &ast.StringLiteral{Token: tok, Value: exp.Value}
+	case *ast.Boolean:
+		return &ast.Boolean{Token: tok, Value: exp.Value}
+	default:
+		return exp
+	}
+}
+
+// pruneIf simplifies an if expression whose condition is a literal. A block
+// holding a single expression replaces the whole if expression; otherwise
+// the branch that cannot run is dropped.
+func pruneIf(node *ast.IfExpression) ast.Node {
+	taken, dropped := node.Consequence, node.Alternative
+	if !isTruthyLiteral(node.Condition) {
+		if node.Alternative == nil {
+			// The value is null, which has no literal form.
+			return node
+		}
+		taken, dropped = node.Alternative, node.Consequence
+	}
+	if dropped != nil && ast.Yields(dropped) {
+		// Removing the only yield in a function would stop it being a
+		// generator function.
+		return node
+	}
+
+	if len(taken.Statements) == 1 {
+		if stmt, ok := taken.Statements[0].(*ast.ExpressionStatement); ok && stmt.Expression != nil {
+			return stmt.Expression
+		}
+	}
+
+	return &ast.IfExpression{
+		Token:       node.Token,
+		Condition:   &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true},
+		Consequence: taken,
+	}
+}
+
+func isTruthyLiteral(exp ast.Expression) bool {
+	if b, ok := exp.(*ast.Boolean); ok {
+		return b.Value
+	}
+	return true
+}
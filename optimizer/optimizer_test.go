@@ -0,0 +1,195 @@
+package optimizer_test
+
+import (
+	"testing"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/optimizer"
+	"github.com/ekediala/jian/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, errs)
+	}
+	return program
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// constant folding
+		{"1 + 2 * 3", "7"},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", "50"},
+		{"-(3 - 5)", "2"},
+		{"!true", "false"},
+		{"!!5", "true"},
+		{"1 < 2", "true"},
+		{"2 > 1 == false", "false"},
+		{`"a" + "b" + "c"`, "abc"},
+		{`"a" == "a"`, "true"},
+		{`1 == "1"`, "false"},
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"x + 1 * 2", "(x + 2)"},
+		{"f(2 * 3)", "f(6)"},
+		{"[1 + 1, {\"k\" + \"ey\": 2 * 2}]", "[2, {key:4}]"},
+
+		// runtime errors stay
+		{"1 / 0", "(1 / 0)"},
+		{"1 + 2 / 0", "(1 + (2 / 0))"},
+		{"5 + true", "(5 + true)"},
+		{"-\"a\"", "(-a)"},
+		{`"a" - "b"`, "(a - b)"},
+
+		// dead branches
+		{"if (true) { 1 } else { 2 }", "1"},
+		{"if (false) { 1 } else { 2 }", "2"},
+		{"if (1 < 2) { x } else { y }", "x"},
+		{"if (0) { x }", "x"},
+		{"if (false) { x }", "iffalse x"},
+		{"if (true) { let a = 1; a } else { 2 }", "iftrue let a = 1;1"},
+		{"if (false) { 1 } else { return 2; }", "iftrue return 2;"},
+		{"if (x) { 1 + 1 } else { 2 + 2 }", "ifx 2else 4"},
+		{"if (true) { 1 } else { yield 2; }", "iftrue 1else yield 2;"},
+		{"if (false) { fn() { yield 1; } } else { 2 }", "2"},
+
+		// constant inlining
+		{"let a = 2; let b = a * 3; b + 1", "let a = 2;let b = 6;7"},
+		{"let s = \"hi\"; s + \"!\"", "let s = hi;hi!"},
+		{"let f = fn() { a }; let a = 1; f() + a", "let f = fn() a;let a = 1;(f() + 1)"},
+		{"let a = 1; let a = 2; a", "let a = 1;let a = 2;a"},
+		{"let a = 1; let f = fn(a) { a }; a", "let a = 1;let f = fn(a) a;a"},
+		{"let a = 1; let f = fn() { a + 1 }; f", "let a = 1;let f = fn() 2;f"},
+		{"let f = fn() { let a = 1; a }; a", "let f = fn() let a = 1;1;a"},
+		{"let a = [1]; a", "let a = [1];a"},
+		{"let a = a + 1; a", "let a = (a + 1);a"},
+		{"let a = 1 / 0; a", "let a = (1 / 0);a"},
+		{"let flag = false; if (flag) { 1 } else { 2 }", "let flag = false;2"},
+		{"let a = 1; let f = fn(b = a + 1) { b }; f(a: a)", "let a = 1;let f = fn(b = 2) b;f(a: 1)"},
+		{"let a = 1; let f = fn(b = a, ...a) { b }; a", "let a = 1;let f = fn(b = a, ...a) b;a"},
+		{"let n = 1; match (2) { n => n + 1 }", "let n = 1;match (2) { n => (n + 1) }"},
+		{"let a = 1; let [a, b = a + 1] = [2]; b", "let a = 1;let [a, b = (a + 1)] = [2];b"},
+		{"let a = 1; let [b = a + 1] = []; b", "let a = 1;let [b = 2] = [];b"},
+		{"let a = 1; let f = fn([a]) { a }; a", "let a = 1;let f = fn([a]) a;a"},
+		{"let x = 1; struct P { x } P(x).x", "let x = 1;struct P { x }(P(1).x)"},
+		{"let P = 1; struct P { x } P", "let P = 1;struct P { x }P"},
+		{"let self = 1; class A { fn f() { self } } self", "let self = 1;class A { fn f() self }self"},
+		{"let A = 1; class A {} A", "let A = 1;class A {}A"},
+		{`let h = {}; h?["a"] ?? 1 + 1`, "let h = {};((h?[a]) ?? 2)"},
+		{`"a" ?? 1 / 0`, "a"},
+		{"const x = 2; let xs = [0]; xs[0] = x * 3;", "const x = 2;let xs = [0];(xs[0]) = 6;"},
+		{"let n: int = 1 + 1; fn f(a: int = n) -> int { a } f()", "let n: int = 2;fn f(a: int = n) -> int af()"},
+		{"f(); let x = 5; fn f() { x }", "f()let x = 5;fn f() x"},
+		{"let x = 5; let g = fn() { fn f() { x } f() };", "let x = 5;let g = fn() fn f() 5f();"},
+		{"fn f() { let x = 1; fn g() { x } x }", "fn f() let x = 1;fn g() x1"},
+		{"let v = 1; select { case let v = recv(c) { v } }", "let v = 1;select { case let v = recv(c) { v } }"},
+
+		// quoted code is left alone
+		{"quote(1 + 2)", "quote((1 + 2))"},
+		{"let a = 1; quote(a + unquote(a))", "let a = 1;quote((a + unquote(a)))"},
+		{"quote(if (true) { 1 })", "quote(iftrue 1)"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		before := program.String()
+
+		optimized := optimizer.Optimize(program)
+		if got := optimized.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+		if program.String() != before {
+			t.Errorf("%s: the original program was modified", tt.input)
+		}
+	}
+}
+
+func TestOptimizeKeepsPositions(t *testing.T) {
+	program := optimizer.Optimize(parse(t, "let a = 1;\n  a + 2 * 3"))
+
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("expected *ast.IntegerLiteral, got %T (%s)", stmt.Expression, stmt.Expression)
+	}
+	if literal.Value != 7 {
+		t.Errorf("expected 7, got %d", literal.Value)
+	}
+	if literal.Token.Line != 2 || literal.Token.Column != 3 {
+		t.Errorf("expected position 2:3, got %d:%d", literal.Token.Line, literal.Token.Column)
+	}
+}
+
+func TestOptimizedProgramsBehaveTheSame(t *testing.T) {
+	inputs := []string{
+		"let a = 2; let b = a * 3; b + 1",
+		"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(12)",
+		"let limit = 10; let count = fn(n) { if (n > limit) { n } else { count(n + 1) } }; count(0)",
+		"let f = fn() { a }; let a = 1; f() + a",
+		"let early = fn() { x }; early()",
+		"let r = fn() { if (true) { return 1; } 2 }; r()",
+		"if (false) { 1 }",
+		"let g = fn() { let a = 1; a }; g(); a",
+		"let x = 10; let h = {\"x\": x, x: \"x\"}; [h[\"x\"], h[10]]",
+		"let d = 0; 10 / d",
+		"let big = 9223372036854775807; big * big",
+		"let e = 1 / 0; 1",
+		`let name = "jian"; let greet = fn(greeting) { greeting + ", " + name }; greet("hi")`,
+		"let g = fn() { if (false) { yield 1; } }; collect(g())",
+		"f(); let x = 5; fn f() { x }",
+		"fn outer() { f(); let x = 5; fn f() { x } } outer()",
+	}
+
+	for _, input := range inputs {
+		expected := evaluator.Eval(parse(t, input), object.NewEnvironment())
+		got := evaluator.Eval(optimizer.Optimize(parse(t, input)), object.NewEnvironment())
+
+		if inspect(got) != inspect(expected) {
+			t.Errorf("%s: expected %s, got %s", input, inspect(expected), inspect(got))
+		}
+	}
+}
+
+func TestOptimizeInputKeepsGlobals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1; x + 1", "let x = 1;(x + 1)"},
+		{"let x = 1; let f = fn() { x };", "let x = 1;let f = fn() x;"},
+		{"const x = 1; x + 1", "const x = 1;2"},
+		{"let f = fn() { let y = 2; y * 3 };", "let f = fn() let y = 2;6;"},
+	}
+
+	for _, tt := range tests {
+		if got := optimizer.OptimizeInput(parse(t, tt.input)).String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	// Each input runs in the environment the ones before it left behind.
+	inputs := []string{"let x = 1; let f = fn() { x };", "let x = 2;", "f()"}
+	env := object.NewEnvironment()
+	var got object.Object
+	for _, input := range inputs {
+		got = evaluator.Eval(optimizer.OptimizeInput(parse(t, input)), env)
+	}
+	if inspect(got) != "INTEGER 2" {
+		t.Errorf("expected f() to see the rebound x, got %s", inspect(got))
+	}
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	return string(obj.Type()) + " " + obj.Inspect()
+}
@@ -0,0 +1,296 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/token"
+)
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	exp := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	exp.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		exp.Arms = append(exp.Arms, arm)
+
+		if !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RBRACE) {
+			msg := fmt.Sprintf("expected , or } after match arm, got %s", p.peekToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+		}
+	}
+	p.nextToken() // advance to }
+
+	if len(exp.Arms) == 0 {
+		p.errors = append(p.errors, "match expression has no arms")
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.curToken}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil || !p.checkPatternBindings(arm.Pattern) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	p.nextToken()
+	arm.Body = p.parseExpression(LOWEST)
+	if arm.Body == nil {
+		return nil
+	}
+
+	return arm
+}
+
+// parsePattern parses a pattern starting at the current token, including
+// alternatives separated by |.
+func (p *Parser) parsePattern() ast.Pattern {
+	tok := p.curToken
+	pattern := p.parsePrimaryPattern()
+	if pattern == nil || !p.peekTokenIs(token.PIPE) {
+		return pattern
+	}
+
+	alt := &ast.AlternativePattern{Token: tok, Alternatives: []ast.Pattern{pattern}}
+	for p.peekTokenIs(token.PIPE) {
+		p.nextToken() // go to |
+		p.nextToken() // go to the next alternative
+		pattern := p.parsePrimaryPattern()
+		if pattern == nil {
+			return nil
+		}
+		alt.Alternatives = append(alt.Alternatives, pattern)
+	}
+	return alt
+}
+
+func (p *Parser) parsePrimaryPattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.INT, token.STRING, token.TRUE, token.FALSE:
+		return &ast.LiteralPattern{Token: p.curToken, Value: p.parseLiteral()}
+
+	case token.MINUS:
+		tok := p.curToken
+		if !p.expectPeek(token.INT) {
+			return nil
+		}
+		value := &ast.PrefixExpression{Token: tok, Operator: tok.Literal, Right: p.parseIntegerLiteral()}
+		return &ast.LiteralPattern{Token: tok, Value: value}
+
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+		return &ast.BindingPattern{
+			Token: p.curToken,
+			Name:  &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		}
+
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+
+	case token.LBRACE:
+		return p.parseHashPattern()
+
+	default:
+		msg := fmt.Sprintf("expected a pattern, got %s", p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+// parseLiteral parses the integer, string or boolean literal at the current
+// token.
+func (p *Parser) parseLiteral() ast.Expression {
+	switch p.curToken.Type {
+	case token.INT:
+		return p.parseIntegerLiteral()
+	case token.STRING:
+		return p.parseStringLiteral()
+	default:
+		return p.parseBooleanExpression()
+	}
+}
+
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.peekTokenIs(token.RBRACKET) {
+				p.errors = append(p.errors, "the rest element must be last in an array pattern")
+				return nil
+			}
+			break
+		}
+
+		el := p.parsePatternElement()
+		if el == nil {
+			return nil
+		}
+		if _, ok := el.(*ast.DefaultPattern); !ok && len(pattern.Elements) > 0 {
+			if _, ok := pattern.Elements[len(pattern.Elements)-1].(*ast.DefaultPattern); ok {
+				msg := fmt.Sprintf("array pattern element %s without a default follows an element with one", el)
+				p.errors = append(p.errors, msg)
+				return nil
+			}
+		}
+		pattern.Elements = append(pattern.Elements, el)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // advance to ]
+
+	return pattern
+}
+
+// parsePatternElement parses an element of an array or hash pattern, which
+// may be followed by = and a default value.
+func (p *Parser) parsePatternElement() ast.Pattern {
+	pattern := p.parsePattern()
+	if pattern == nil || !p.peekTokenIs(token.ASSIGN) {
+		return pattern
+	}
+
+	p.nextToken()
+	el := &ast.DefaultPattern{Token: p.curToken, Pattern: pattern}
+	p.nextToken()
+	el.Default = p.parseExpression(LOWEST)
+	if el.Default == nil {
+		return nil
+	}
+	return el
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.curToken}
+	seen := map[string]bool{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.INT, token.STRING, token.TRUE, token.FALSE:
+		default:
+			msg := fmt.Sprintf("hash pattern keys must be integer, string or boolean literals, got %s", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		key := p.parseLiteral()
+		if key == nil {
+			return nil
+		}
+		id := string(p.curToken.Type) + " " + key.String()
+		if seen[id] {
+			msg := fmt.Sprintf("hash pattern has duplicate key %s", key.String())
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		seen[id] = true
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parsePatternElement()
+		if value == nil {
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key)
+		pattern.Values = append(pattern.Values, value)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // advance to }
+
+	return pattern
+}
+
+// checkPatternBindings reports an error if pattern binds a name more than
+// once, or has alternatives that bind different names.
+func (p *Parser) checkPatternBindings(pattern ast.Pattern) bool {
+	var msg string
+	ast.Inspect(pattern, func(node ast.Node) bool {
+		if alt, ok := node.(*ast.AlternativePattern); ok && msg == "" {
+			for _, other := range alt.Alternatives[1:] {
+				if !sameBindings(alt.Alternatives[0], other) {
+					msg = fmt.Sprintf("alternatives in pattern %s must bind the same names", alt)
+				}
+			}
+		}
+		return msg == ""
+	})
+
+	seen := map[string]bool{}
+	for _, ident := range ast.PatternBindings(pattern) {
+		if seen[ident.Value] && msg == "" {
+			msg = fmt.Sprintf("%s is bound more than once in pattern %s", ident.Value, pattern)
+		}
+		seen[ident.Value] = true
+	}
+
+	if msg != "" {
+		p.errors = append(p.errors, msg)
+		return false
+	}
+	return true
+}
+
+func sameBindings(a, b ast.Pattern) bool {
+	names := map[string]int{}
+	for _, ident := range ast.PatternBindings(a) {
+		names[ident.Value]++
+	}
+	for _, ident := range ast.PatternBindings(b) {
+		names[ident.Value]--
+	}
+	for _, n := range names {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 
 	"strconv"
 
@@ -13,6 +15,7 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	COALESCE    // ??
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
@@ -25,6 +28,7 @@ const (
 var precedences = map[token.TokenType]int{
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
+	token.IS:       EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
 	token.PLUS:     SUM,
@@ -33,6 +37,11 @@ var precedences = map[token.TokenType]int{
 	token.ASTERISK: PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
+	token.COALESCE: COALESCE,
+
+	token.OPTIONAL_DOT:      INDEX,
+	token.OPTIONAL_LBRACKET: INDEX,
 }
 
 type (
@@ -73,6 +82,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefixFn(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefixFn(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefixFn(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefixFn(token.SELECT, p.parseSelectExpression)
+	p.registerPrefixFn(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefixFn(token.MATCH, p.parseMatchExpression)
 
 	// infix parsing functions
 	p.registerInfixFn(token.PLUS, p.parseInfixExpression)
@@ -81,10 +93,15 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfixFn(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfixFn(token.EQ, p.parseInfixExpression)
 	p.registerInfixFn(token.NOT_EQ, p.parseInfixExpression)
+	p.registerInfixFn(token.IS, p.parseInfixExpression)
 	p.registerInfixFn(token.GT, p.parseInfixExpression)
 	p.registerInfixFn(token.LT, p.parseInfixExpression)
 	p.registerInfixFn(token.LPAREN, p.parseCallExpression)
 	p.registerInfixFn(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfixFn(token.DOT, p.parseFieldExpression)
+	p.registerInfixFn(token.OPTIONAL_DOT, p.parseFieldExpression)
+	p.registerInfixFn(token.OPTIONAL_LBRACKET, p.parseIndexExpression)
+	p.registerInfixFn(token.COALESCE, p.parseInfixExpression)
 	return &p
 }
 
@@ -109,6 +126,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 	value := p.parseExpression(LOWEST)
 	hash.Pairs[key] = value
+	hash.Keys = append(hash.Keys, key)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken() // advance to comma
@@ -123,6 +141,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 		value = p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 	}
 
 	if !p.expectPeek(token.RBRACE) {
@@ -132,15 +151,117 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return &hash
 }
 
+func (p *Parser) parseSelectExpression() ast.Expression {
+	exp := &ast.SelectExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.nextToken() // advance past {
+
+	for !p.curTokenIs(token.RBRACE) {
+		switch p.curToken.Type {
+		case token.CASE:
+			c := p.parseSelectCase()
+			if c == nil {
+				return nil
+			}
+			exp.Cases = append(exp.Cases, c)
+		case token.DEFAULT:
+			if exp.Default != nil {
+				p.errors = append(p.errors, "select expression has more than one default case")
+				return nil
+			}
+			if !p.expectPeek(token.LBRACE) {
+				return nil
+			}
+			exp.Default = p.parseBlockStatement()
+		default:
+			msg := fmt.Sprintf("expected case or default in select expression, got %s", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		p.nextToken()
+	}
+
+	return exp
+}
+
+func (p *Parser) parseSelectCase() *ast.SelectCase {
+	c := &ast.SelectCase{Token: p.curToken}
+
+	if p.peekTokenIs(token.LET) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		c.Binding = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeek(token.ASSIGN) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	call, ok := p.parseExpression(LOWEST).(*ast.CallExpression)
+	if !ok || !isSelectOperation(call, c.Binding != nil) {
+		p.errors = append(p.errors, "select case must be `recv(channel)`, `let name = recv(channel)` or `send(channel, value)`")
+		return nil
+	}
+	c.Operation = call
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	c.Body = p.parseBlockStatement()
+
+	return c
+}
+
+func isSelectOperation(call *ast.CallExpression, hasBinding bool) bool {
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+
+	switch ident.Value {
+	case "recv":
+		return len(call.Arguments) == 1
+	case "send":
+		return len(call.Arguments) == 2 && !hasBinding
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	indexExp := &ast.IndexExpression{
-		Token: p.curToken,
-		Left:  left,
+		Token:    p.curToken,
+		Left:     left,
+		Optional: p.curTokenIs(token.OPTIONAL_LBRACKET),
 	}
 
-	p.nextToken()
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		indexExp.Index = p.parseExpression(LOWEST)
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			return indexExp
+		}
+	}
 
-	indexExp.Index = p.parseExpression(LOWEST)
+	p.nextToken()
+	indexExp.Slice = true
+	indexExp.Start, indexExp.Index = indexExp.Index, nil
+	indexExp.End = p.parseSliceBound()
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		indexExp.Step = p.parseSliceBound()
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -149,15 +270,32 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return indexExp
 }
 
+// parseSliceBound parses the expression after a colon in a slice, returning
+// nil if it is omitted.
+func (p *Parser) parseSliceBound() ast.Expression {
+	if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBRACKET) {
+		return nil
+	}
+	p.nextToken()
+	return p.parseExpression(LOWEST)
+}
+
+func (p *Parser) parseFieldExpression(left ast.Expression) ast.Expression {
+	exp := &ast.FieldExpression{Token: p.curToken, Left: left, Optional: p.curTokenIs(token.OPTIONAL_DOT)}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Field = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return exp
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
-	array := &ast.ArrayLiteral{
-		Token:    p.curToken,
-		Elements: p.parseExpressionList(token.RBRACKET),
-	}
+	array := &ast.ArrayLiteral{Token: p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
 	return array
 }
 
@@ -178,6 +316,11 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	v, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if b, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.IntegerLiteral{Token: p.curToken, Big: b}
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
@@ -187,16 +330,30 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: p.curToken}
+	stmt := &ast.LetStatement{Token: p.curToken, Const: p.curTokenIs(token.CONST)}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Pattern = p.parsePrimaryPattern()
+		if stmt.Pattern == nil || !p.checkPatternBindings(stmt.Pattern) {
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{
+			Token: p.curToken,
+			Value: p.curToken.Literal,
+		}
 	}
 
-	stmt.Name = &ast.Identifier{
-		Token: p.curToken,
-		Value: p.curToken.Literal,
+	typ, ok := p.parseTypeAnnotation()
+	if !ok {
+		return nil
 	}
+	stmt.Type = typ
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -229,6 +386,20 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := ast.BlockStatement{
 		Token: p.curToken,
@@ -312,40 +483,102 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return &expression
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	parameters := make([]*ast.Identifier, 0, 5)
+// parseFunctionParameters parses a parameter list such as
+// (a, [b, c], d = 10, ...rest) into fn. Defaults and Patterns stay nil
+// unless some parameter has a default value or destructures its argument,
+// and a parameter with a default may only be followed by other parameters
+// with defaults and the rest parameter. Parameters may be annotated with a
+// type, as in (a: int), and the list may be followed by a return type, as
+// in () -> int.
+func (p *Parser) parseFunctionParameters(fn *ast.FunctionLiteral) {
+	fn.Parameters = make([]*ast.Identifier, 0, 5)
 
 	if p.peekTokenIs(token.RPAREN) {
 		// function has no parameters
 		p.nextToken()
-		return parameters
+		p.parseReturnType(fn)
+		return
 	}
 
-	p.nextToken()
-
-	parameter := ast.Identifier{
-		Token: p.curToken,
-		Value: p.curToken.Literal,
-	}
+	for {
+		p.nextToken()
 
-	parameters = append(parameters, &parameter)
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return
+			}
+			fn.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			typ, ok := p.parseTypeAnnotation()
+			if !ok || !p.checkRestType(typ) {
+				return
+			}
+			fn.RestType = typ
+			if p.peekTokenIs(token.COMMA) {
+				p.errors = append(p.errors, "rest parameter must be the last parameter")
+				return
+			}
+			break
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken() // go to comma
-		p.nextToken() // go to next parameter
 		parameter := ast.Identifier{
 			Token: p.curToken,
 			Value: p.curToken.Literal,
 		}
+		if p.curTokenIs(token.LBRACKET) || p.curTokenIs(token.LBRACE) {
+			pattern := p.parsePrimaryPattern()
+			if pattern == nil || !p.checkPatternBindings(pattern) {
+				return
+			}
+			for len(fn.Patterns) < len(fn.Parameters) {
+				fn.Patterns = append(fn.Patterns, nil)
+			}
+			fn.Patterns = append(fn.Patterns, pattern)
+			parameter.Value = pattern.String()
+		}
+		fn.Parameters = append(fn.Parameters, &parameter)
+
+		typ, ok := p.parseTypeAnnotation()
+		if !ok {
+			return
+		}
+		if typ != nil {
+			for len(fn.ParamTypes) < len(fn.Parameters)-1 {
+				fn.ParamTypes = append(fn.ParamTypes, nil)
+			}
+			fn.ParamTypes = append(fn.ParamTypes, typ)
+		}
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken() // go to =
+			p.nextToken() // go to the default value
+			for len(fn.Defaults) < len(fn.Parameters)-1 {
+				fn.Defaults = append(fn.Defaults, nil)
+			}
+			fn.Defaults = append(fn.Defaults, p.parseExpression(LOWEST))
+		} else if fn.Defaults != nil {
+			msg := fmt.Sprintf("parameter %s without a default follows a parameter with one", parameter.Value)
+			p.errors = append(p.errors, msg)
+			return
+		}
 
-		parameters = append(parameters, &parameter)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken() // go to comma
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+	if p.expectPeek(token.RPAREN) {
+		p.parseReturnType(fn)
 	}
+}
 
-	return parameters
+// parseReturnType parses the -> T that may follow a parameter list.
+func (p *Parser) parseReturnType(fn *ast.FunctionLiteral) {
+	if p.peekTokenIs(token.RARROW) {
+		p.nextToken() // go to ->
+		p.nextToken() // go to the return type
+		fn.ReturnType = p.parseType()
+	}
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
@@ -357,7 +590,60 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	exp.Parameters = p.parseFunctionParameters()
+	p.parseFunctionParameters(&exp)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Body = p.parseBlockStatement()
+	exp.Generator = ast.Yields(exp.Body)
+
+	return &exp
+}
+
+func (p *Parser) parseFunctionStatement() ast.Statement {
+	stmt := &ast.FunctionStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	fn := ast.FunctionLiteral{Token: stmt.Token}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.parseFunctionParameters(&fn)
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	fn.Body = p.parseBlockStatement()
+	fn.Generator = ast.Yields(fn.Body)
+	stmt.Function = &fn
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	exp := ast.MacroLiteral{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	var params ast.FunctionLiteral
+	p.parseFunctionParameters(&params)
+	if params.Defaults != nil || params.Patterns != nil || params.Rest != nil ||
+		params.ParamTypes != nil || params.ReturnType != nil {
+		p.errors = append(p.errors, "macro parameters must be plain names")
+		return nil
+	}
+	exp.Parameters = params.Parameters
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -370,10 +656,72 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
 	exp := ast.CallExpression{Token: p.curToken, Function: fn}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Arguments = p.parseCallArguments()
 	return &exp
 }
 
+// parseCallArguments parses the argument list of a call. Besides plain
+// expressions it accepts spread arguments (...xs) and keyword arguments
+// (name: value); keyword arguments must come after all positional ones and
+// may not repeat a name.
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return args
+	}
+
+	seen := map[string]bool{}
+	for {
+		p.nextToken()
+
+		keyword := p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON)
+		if !keyword && len(seen) > 0 {
+			p.errors = append(p.errors, "positional argument follows keyword argument")
+			return nil
+		}
+
+		var arg ast.Expression
+		switch {
+		case p.curTokenIs(token.ELLIPSIS):
+			spread := &ast.SpreadExpression{Token: p.curToken}
+			p.nextToken()
+			spread.Value = p.parseExpression(LOWEST)
+			arg = spread
+		case keyword:
+			kw := &ast.KeywordArgument{
+				Token: p.curToken,
+				Name:  &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+			}
+			if seen[kw.Name.Value] {
+				msg := fmt.Sprintf("keyword argument %s repeated", kw.Name.Value)
+				p.errors = append(p.errors, msg)
+				return nil
+			}
+			seen[kw.Name.Value] = true
+			p.nextToken() // go to :
+			p.nextToken() // go to the value
+			kw.Value = p.parseExpression(LOWEST)
+			arg = kw
+		default:
+			arg = p.parseExpression(LOWEST)
+		}
+		args = append(args, arg)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken() // go to comma
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return args
+}
+
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefixFn, ok := p.prefixParsefns[p.curToken.Type]
 	if !ok {
@@ -396,11 +744,15 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	return leftExp
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := ast.ExpressionStatement{Token: p.curToken}
 
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.ASSIGN) {
+		return p.parseAssignStatement(stmt.Expression)
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -408,12 +760,170 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return &stmt
 }
 
+// parseAssignStatement parses the rest of an assignment to target, which
+// must be a field or an index expression.
+func (p *Parser) parseAssignStatement(target ast.Expression) ast.Statement {
+	p.nextToken()
+	stmt := &ast.AssignStatement{Token: p.curToken, Target: target}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if isOptionalChain(target) {
+		p.errors = append(p.errors, fmt.Sprintf("cannot assign to optional chain %s", target))
+		return nil
+	}
+
+	switch target := target.(type) {
+	case *ast.FieldExpression:
+		return stmt
+	case *ast.IndexExpression:
+		if !target.Slice {
+			return stmt
+		}
+	}
+
+	p.errors = append(p.errors, fmt.Sprintf("cannot assign to %s", target))
+	return nil
+}
+
+// isOptionalChain reports whether exp is a chain of field accesses, indexes
+// and calls with an optional link, such as a?.b[0].
+func isOptionalChain(exp ast.Expression) bool {
+	for {
+		switch link := exp.(type) {
+		case *ast.FieldExpression:
+			if link.Optional {
+				return true
+			}
+			exp = link.Left
+		case *ast.IndexExpression:
+			if link.Optional {
+				return true
+			}
+			exp = link.Left
+		case *ast.CallExpression:
+			exp = link.Function
+		default:
+			return false
+		}
+	}
+}
+
+// parseStructStatement parses a declaration such as struct Point { x, y }.
+func (p *Parser) parseStructStatement() ast.Statement {
+	stmt := &ast.StructStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if seen[field.Value] {
+			p.errors = append(p.errors, fmt.Sprintf("struct %s has duplicate field %s", stmt.Name, field))
+			return nil
+		}
+		seen[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // advance to }
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseClassStatement parses a class declaration, whose body holds method
+// declarations only.
+func (p *Parser) parseClassStatement() ast.Statement {
+	stmt := &ast.ClassStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.EXTENDS) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Super = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		if p.curTokenIs(token.SEMICOLON) {
+			continue
+		}
+		if !p.curTokenIs(token.FUNCTION) || !p.peekTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("expected a method declaration in class %s, got %s", stmt.Name, p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		method, ok := p.parseFunctionStatement().(*ast.FunctionStatement)
+		if !ok {
+			return nil
+		}
+		if seen[method.Name.Value] {
+			msg := fmt.Sprintf("class %s has duplicate method %s", stmt.Name, method.Name)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		seen[method.Name.Value] = true
+		stmt.Methods = append(stmt.Methods, method)
+	}
+	p.nextToken() // advance to }
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.YIELD:
+		return p.parseYieldStatement()
+	case token.STRUCT:
+		return p.parseStructStatement()
+	case token.CLASS:
+		return p.parseClassStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -137,6 +137,26 @@ func TestParseIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestParseBigIntegerLiteralExpression(t *testing.T) {
+	input := "123456789012345678901234567890;"
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Big == nil || literal.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.Big not %s. got=%v", "123456789012345678901234567890", literal.Big)
+	}
+	if literal.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.String() wrong. got=%s", literal.String())
+	}
+}
+
 func TestParsePrefixExpression(t *testing.T) {
 	prefixTests := []struct {
 		input        string
@@ -502,6 +522,53 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral. got=%T",
+			stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro literal parameters wrong. want 2, got=%d\n",
+			len(macro.Parameters))
+	}
+
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements has not 1 statements. got=%d\n",
+			len(macro.Body.Statements))
+	}
+
+	bodyStmt, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro body stmt is not ast.ExpressionStatement. got=%T",
+			macro.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 
@@ -878,6 +945,35 @@ func TestParsingHashLiteralsIntegerKeys(t *testing.T) {
 	}
 }
 
+func TestParsingHashLiteralsPreserveOrder(t *testing.T) {
+	input := `{"z": 1, "a": 2, "m": 3 + 4, "b": 5, "y": 6}`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	expectedKeys := []string{"z", "a", "m", "b", "y"}
+	if len(hash.Keys) != len(expectedKeys) {
+		t.Fatalf("hash.Keys has wrong length. got=%d", len(hash.Keys))
+	}
+	for i, key := range hash.Keys {
+		if key.String() != expectedKeys[i] {
+			t.Errorf("hash.Keys[%d]: expected %q, got %q", i, expectedKeys[i], key.String())
+		}
+	}
+
+	if got, expected := hash.String(), "{z:1, a:2, m:(3 + 4), b:5, y:6}"; got != expected {
+		t.Errorf("expected hash.String() to be %q, got %q", expected, got)
+	}
+}
+
 func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	input := `{"one": 0 + 1, "two": 10 - 8, "three": 15 / 5}`
 
@@ -925,6 +1021,283 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	}
 }
 
+func TestSelectExpressionParsing(t *testing.T) {
+	input := `select {
+		case let v = recv(in) { v }
+		case send(out, 1) { 2 }
+		case recv(done) { 3 }
+		default { 4 }
+	}`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.SelectExpression)
+	if !ok {
+		t.Fatalf("exp is not ast.SelectExpression. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Cases) != 3 {
+		t.Fatalf("select has wrong number of cases. got=%d", len(exp.Cases))
+	}
+
+	tests := []struct {
+		binding   string
+		operation string
+		isSend    bool
+	}{
+		{"v", "recv(in)", false},
+		{"", "send(out, 1)", true},
+		{"", "recv(done)", false},
+	}
+
+	for i, tt := range tests {
+		c := exp.Cases[i]
+		if tt.binding == "" && c.Binding != nil {
+			t.Errorf("cases[%d]: expected no binding, got %q", i, c.Binding.Value)
+		}
+		if tt.binding != "" && (c.Binding == nil || c.Binding.Value != tt.binding) {
+			t.Errorf("cases[%d]: expected binding %q, got %v", i, tt.binding, c.Binding)
+		}
+		if got := c.Operation.String(); got != tt.operation {
+			t.Errorf("cases[%d]: expected operation %q, got %q", i, tt.operation, got)
+		}
+		if c.IsSend() != tt.isSend {
+			t.Errorf("cases[%d]: expected IsSend()=%t", i, tt.isSend)
+		}
+	}
+
+	if exp.Default == nil || exp.Default.String() != "4" {
+		t.Errorf("expected default body %q, got %v", "4", exp.Default)
+	}
+}
+
+func TestSelectExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"select { case len(x) { 1 } }",
+			"select case must be `recv(channel)`, `let name = recv(channel)` or `send(channel, value)`",
+		},
+		{
+			"select { case let v = send(c, 1) { 1 } }",
+			"select case must be `recv(channel)`, `let name = recv(channel)` or `send(channel, value)`",
+		},
+		{
+			"select { default { 1 } default { 2 } }",
+			"select expression has more than one default case",
+		},
+		{
+			"select { recv(c) }",
+			"expected case or default in select expression, got IDENT",
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestDefaultAndRestParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b = 10) { a + b }", "fn(a, b = 10) (a + b)"},
+		{"fn(first, ...others) { others }", "fn(first, ...others) others"},
+		{"fn(...all) { all }", "fn(...all) all"},
+		{"fn(a = 1, b = a + 1, ...c) { c }", "fn(a = 1, b = (a + 1), ...c) c"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("fn(a, b = 2) { a }"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if fn.Default(0) != nil {
+		t.Errorf("expected a to have no default, got %s", fn.Default(0))
+	}
+	testIntegerLiteral(t, fn.Default(1), 2)
+}
+
+func TestFunctionStatementParsing(t *testing.T) {
+	p := parser.New(lexer.New("fn add(a, b = 1) { a + b }; fn() { 1 }"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionStatement, got %T", program.Statements[0])
+	}
+	testIdentifier(t, stmt.Name, "add")
+	if len(stmt.Function.Parameters) != 2 {
+		t.Errorf("expected 2 parameters, got %d", len(stmt.Function.Parameters))
+	}
+	if got, expected := stmt.String(), "fn add(a, b = 1) (a + b)"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if _, ok := program.Statements[1].(*ast.ExpressionStatement); !ok {
+		t.Errorf("expected an anonymous function to stay an expression, got %T", program.Statements[1])
+	}
+}
+
+func TestCallArgumentParsing(t *testing.T) {
+	p := parser.New(lexer.New("f(1, ...xs, b: 2, c: d + 1)"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if len(call.Arguments) != 4 {
+		t.Fatalf("expected 4 arguments, got %d", len(call.Arguments))
+	}
+	testIntegerLiteral(t, call.Arguments[0], 1)
+
+	spread, ok := call.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("expected *ast.SpreadExpression, got %T", call.Arguments[1])
+	}
+	testIdentifier(t, spread.Value, "xs")
+
+	kw, ok := call.Arguments[2].(*ast.KeywordArgument)
+	if !ok {
+		t.Fatalf("expected *ast.KeywordArgument, got %T", call.Arguments[2])
+	}
+	testIdentifier(t, kw.Name, "b")
+	testIntegerLiteral(t, kw.Value, 2)
+
+	if got, expected := call.String(), "f(1, ...xs, b: 2, c: (d + 1))"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestParameterAndArgumentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a = 1, b) { a }", "parameter b without a default follows a parameter with one"},
+		{"fn(...a, b) { a }", "rest parameter must be the last parameter"},
+		{"macro(a = 1) { a }", "macro parameters must be plain names"},
+		{"f(a: 1, 2)", "positional argument follows keyword argument"},
+		{"f(a: 1, ...xs)", "positional argument follows keyword argument"},
+		{"f(a: 1, a: 2)", "keyword argument a repeated"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestMatchExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { 1 => a, _ => b }", "match (x) { 1 => a, _ => b }"},
+		{"match (x) { 1 | 2 | -3 => a, }", "match (x) { 1 | 2 | (-3) => a }"},
+		{"match (x) { [] => 0, [h, ...t] => h + 1 }", "match (x) { [] => 0, [h, ...t] => (h + 1) }"},
+		{`match (x) { {"type": "user", "name": n} => n }`, "match (x) { {type: user, name: n} => n }"},
+		{"match (f(x)) { n if n > 1 => n }", "match (f(x)) { n if (n > 1) => n }"},
+		{"match (x) { [a, b] | [b, a] => a }", "match (x) { [a, b] | [b, a] => a }"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("match (x) { [a, ...r] if a => r }"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	match := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+	arm := match.Arms[0]
+	pattern, ok := arm.Pattern.(*ast.ArrayPattern)
+	if !ok {
+		t.Fatalf("expected *ast.ArrayPattern, got %T", arm.Pattern)
+	}
+	if len(pattern.Elements) != 1 || pattern.Rest == nil || pattern.Rest.Value != "r" {
+		t.Errorf("expected [a, ...r], got %s", pattern)
+	}
+	testIdentifier(t, arm.Guard, "a")
+	testIdentifier(t, arm.Body, "r")
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { }", "match expression has no arms"},
+		{"match (x) { 1 => 2 3 => 4 }", "expected , or } after match arm, got INT"},
+		{"match (x) { 1 2 }", "expected next token to be =>, got INT instead"},
+		{"match (x) { f(a) => 1 }", "expected next token to be =>, got ( instead"},
+		{"match (x) { (a) => 1 }", "expected a pattern, got ("},
+		{"match (x) { [...r, a] => 1 }", "the rest element must be last in an array pattern"},
+		{"match (x) { {k: v} => 1 }", "hash pattern keys must be integer, string or boolean literals, got IDENT"},
+		{`match (x) { {"a": 1, "a": 2} => 1 }`, "hash pattern has duplicate key a"},
+		{"match (x) { [a, a] => 1 }", "a is bound more than once in pattern [a, a]"},
+		{"match (x) { [a, 1] | [1, b] => 1 }", "alternatives in pattern [a, 1] | [1, b] must bind the same names"},
+		{"match (x) { -a => 1 }", "expected next token to be INT, got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
 func testLetStatement(t *testing.T, stmt ast.Statement, name string) bool {
 	t.Helper()
 	if got, expected := stmt.TokenLiteral(), "let"; got != expected {
@@ -1070,3 +1443,480 @@ func checkParserErrors(p *parser.Parser, t *testing.T) {
 	}
 	t.FailNow()
 }
+
+func TestDestructuringParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b, ...rest] = xs;", "let [a, b, ...rest] = xs;"},
+		{`let {"name": n, "age": a} = person;`, "let {name: n, age: a} = person;"},
+		{"let [[a, b], {1: c}] = xs;", "let [[a, b], {1: c}] = xs;"},
+		{"let [a, b = 2, c = a + 1] = xs;", "let [a, b = 2, c = (a + 1)] = xs;"},
+		{`let {"port": port = 80} = config;`, "let {port: port = 80} = config;"},
+		{"fn([a, b], c) { a }", "fn([a, b], c) a"},
+		{"fn({1: a} = {}, ...rest) { a }", "fn({1: a} = {}, ...rest) a"},
+		{"match (x) { [a, b = 0] => a + b }", "match (x) { [a, b = 0] => (a + b) }"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("let f = fn(a, [b, c]) { b };"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	fn := program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if len(fn.Parameters) != 2 || fn.Parameters[1].Value != "[b, c]" {
+		t.Fatalf("expected parameters a and [b, c], got %v", fn.Parameters)
+	}
+	if fn.Pattern(0) != nil {
+		t.Errorf("expected parameter a to have no pattern, got %s", fn.Pattern(0))
+	}
+	if _, ok := fn.Pattern(1).(*ast.ArrayPattern); !ok {
+		t.Errorf("expected *ast.ArrayPattern, got %T", fn.Pattern(1))
+	}
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, a] = xs;", "a is bound more than once in pattern [a, a]"},
+		{"let [a = 1, b] = xs;", "array pattern element b without a default follows an element with one"},
+		{"let [a] xs;", "expected next token to be =, got IDENT instead"},
+		{"let 1 = xs;", "expected next token to be IDENT, got INT instead"},
+		{"fn([a, a]) { a }", "a is bound more than once in pattern [a, a]"},
+		{"fn([a] = [1], b) { a }", "parameter b without a default follows a parameter with one"},
+		{"macro([a]) { a }", "macro parameters must be plain names"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestStructParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Point { x, y }", "struct Point { x, y }"},
+		{"struct Point { x, y, };", "struct Point { x, y }"},
+		{"struct Empty {}", "struct Empty {}"},
+		{"p.x", "(p.x)"},
+		{"p.x.y + 1", "(((p.x).y) + 1)"},
+		{"f(a).b[0]", "((f(a).b)[0])"},
+		{"-p.x", "(-(p.x))"},
+		{"p.x = p.y * 2;", "(p.x) = ((p.y) * 2);"},
+		{"a.b.c = 1", "((a.b).c) = 1;"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("struct Point { x, y } p.x = 1;"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	decl, ok := program.Statements[0].(*ast.StructStatement)
+	if !ok {
+		t.Fatalf("expected *ast.StructStatement, got %T", program.Statements[0])
+	}
+	if decl.Name.Value != "Point" || len(decl.Fields) != 2 || decl.Fields[1].Value != "y" {
+		t.Errorf("expected struct Point { x, y }, got %s", decl)
+	}
+	assign, ok := program.Statements[1].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("expected *ast.AssignStatement, got %T", program.Statements[1])
+	}
+	field, ok := assign.Target.(*ast.FieldExpression)
+	if !ok {
+		t.Fatalf("expected *ast.FieldExpression, got %T", assign.Target)
+	}
+	testIdentifier(t, field.Left, "p")
+	testIdentifier(t, field.Field, "x")
+	testIntegerLiteral(t, assign.Value, 1)
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct { x }", "expected next token to be IDENT, got { instead"},
+		{"struct Point { x y }", "expected next token to be ,, got IDENT instead"},
+		{"struct Point { 1 }", "expected next token to be IDENT, got INT instead"},
+		{"struct Point { x, x }", "struct Point has duplicate field x"},
+		{"p.1", "expected next token to be IDENT, got INT instead"},
+		{"a + b = 1;", "cannot assign to (a + b)"},
+		{"x = 1;", "cannot assign to x"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestClassParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class A {}", "class A {}"},
+		{"class A { fn init(x) { self.x = x; } }", "class A { fn init(x) (self.x) = x; }"},
+		{"class B extends A { fn f() { super.f() + 1 }; fn g(a = 1) { a } }", "class B extends A { fn f() ((super.f)() + 1) fn g(a = 1) a }"},
+		{"b is A", "(b is A)"},
+		{"b is A == true", "((b is A) == true)"},
+		{"!b is A", "((!b) is A)"},
+		{"x.f(1).g()", "((x.f)(1).g)()"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("class Dog extends Animal { fn init(name) { self.name = name } fn speak() { name } }"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	class, ok := program.Statements[0].(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("expected *ast.ClassStatement, got %T", program.Statements[0])
+	}
+	testIdentifier(t, class.Name, "Dog")
+	testIdentifier(t, class.Super, "Animal")
+	if len(class.Methods) != 2 {
+		t.Fatalf("expected 2 methods, got %d", len(class.Methods))
+	}
+	if class.Methods[0].Name.Value != "init" || class.Methods[1].Name.Value != "speak" {
+		t.Errorf("expected methods init and speak, got %s and %s", class.Methods[0].Name, class.Methods[1].Name)
+	}
+}
+
+func TestClassErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class { }", "expected next token to be IDENT, got { instead"},
+		{"class A extends { }", "expected next token to be IDENT, got { instead"},
+		{"class A ( }", "expected next token to be {, got ( instead"},
+		{"class A { let x = 1; }", "expected a method declaration in class A, got LET"},
+		{"class A { fn(x) { x } }", "expected a method declaration in class A, got FUNCTION"},
+		{"class A { fn f() {} fn f() {} }", "class A has duplicate method f"},
+		{"class A { fn f() {}", "expected a method declaration in class A, got EOF"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestTypeAnnotationParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 5;", "let x: int = 5;"},
+		{"let xs: [string] = [];", "let xs: [string] = [];"},
+		{"let h: {string: [int]} = {};", "let h: {string: [int]} = {};"},
+		{"let x: int | string = 1", "let x: int | string = 1;"},
+		{"let x: int? = 1", "let x: int? = 1;"},
+		{"let x: (int | string)? = 1", "let x: (int | string)? = 1;"},
+		{"let [a, b]: [int] = xs", "let [a, b]: [int] = xs;"},
+		{"let f: fn(int, ...[string]) -> bool = g", "let f: fn(int, ...[string]) -> bool = g;"},
+		{"let f: fn() = g", "let f: fn() = g;"},
+		{"let f: fn() -> int | string = g", "let f: fn() -> int | string = g;"},
+		{"let f: (fn() -> int) | string = g", "let f: (fn() -> int) | string = g;"},
+		{"let f: (fn() -> int)? = g", "let f: (fn() -> int)? = g;"},
+		{"fn(a: int, b: string) -> bool { true }", "fn(a: int, b: string) -> bool true"},
+		{"fn(a: int = 1, ...rest: [int]) { a }", "fn(a: int = 1, ...rest: [int]) a"},
+		{"fn() -> [int] { [] }", "fn() -> [int] []"},
+		{"fn f([a, b]: [int], c) -> int { a }", "fn f([a, b]: [int], c) -> int a"},
+		{"fn f(p: Point) -> Point? { p }", "fn f(p: Point) -> Point? p"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("fn(a, b: [int]) -> int { a }"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if fn.ParamType(0) != nil {
+		t.Errorf("expected parameter a to have no type, got %s", fn.ParamType(0))
+	}
+	if _, ok := fn.ParamType(1).(*ast.ArrayType); !ok {
+		t.Errorf("expected *ast.ArrayType, got %T", fn.ParamType(1))
+	}
+	if _, ok := fn.ReturnType.(*ast.NamedType); !ok {
+		t.Errorf("expected *ast.NamedType, got %T", fn.ReturnType)
+	}
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: = 5;", "expected a type, got ="},
+		{"let x: [int = 5;", "expected next token to be ], got = instead"},
+		{"let x: {int} = 5;", "expected next token to be :, got } instead"},
+		{"let x: int | = 5;", "expected a type, got ="},
+		{"fn(a: 1) { a }", "expected a type, got INT"},
+		{"fn(...r: int) { r }", "rest parameter type must be an array type, got int"},
+		{"let f: fn(...int) = g", "rest parameter type must be an array type, got int"},
+		{"fn() -> 1 { 1 }", "expected a type, got INT"},
+		{"macro(x: int) { x }", "macro parameters must be plain names"},
+		{"macro(x) -> int { x }", "macro parameters must be plain names"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestConstAndIndexAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 5;", "const x = 5;"},
+		{"const limit: int = 10;", "const limit: int = 10;"},
+		{"const [a, b] = pair;", "const [a, b] = pair;"},
+		{"xs[0] = 1;", "(xs[0]) = 1;"},
+		{`h["a"][1] = x + 1`, "((h[a])[1]) = (x + 1);"},
+		{"p.xs[0] = p.y;", "((p.xs)[0]) = (p.y);"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("let a = 1; const b = 2;"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	for i, want := range []bool{false, true} {
+		stmt, ok := program.Statements[i].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("expected *ast.LetStatement, got %T", program.Statements[i])
+		}
+		if stmt.Const != want {
+			t.Errorf("%s: expected Const to be %t", stmt, want)
+		}
+	}
+}
+
+func TestOptionalChainingParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a?.b", "(a?.b)"},
+		{"a?[0]", "(a?[0])"},
+		{`a?.b[0]?.c`, "(((a?.b)[0])?.c)"},
+		{"a?.f(1)", "(a?.f)(1)"},
+		{"a ?? b", "(a ?? b)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a ?? b == c", "(a ?? (b == c))"},
+		{"a?.b ?? 1 + 2", "((a?.b) ?? (1 + 2))"},
+		{"let x: int? = a?[0] ?? 0;", "let x: int? = ((a?[0]) ?? 0);"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"a?.b = 1;", "cannot assign to optional chain (a?.b)"},
+		{"a?[0].b = 1;", "cannot assign to optional chain ((a?[0]).b)"},
+		{"a?.f().b = 1;", "cannot assign to optional chain ((a?.f)().b)"},
+		{"a?.1", "expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestSliceParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs[1:2]", "(xs[1:2])"},
+		{"xs[:]", "(xs[:])"},
+		{"xs[1:]", "(xs[1:])"},
+		{"xs[:-1]", "(xs[:(-1)])"},
+		{"xs[::2]", "(xs[::2])"},
+		{"xs[::-1]", "(xs[::(-1)])"},
+		{"xs[a + 1:b:c]", "(xs[(a + 1):b:c])"},
+		{"xs[1:][0]", "((xs[1:])[0])"},
+		{"xs?[1:2]", "(xs?[1:2])"},
+		{"xs[-1]", "(xs[(-1)])"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("xs[:2]"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	slice := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IndexExpression)
+	if !slice.Slice || slice.Index != nil || slice.Start != nil || slice.Step != nil {
+		t.Fatalf("unexpected slice %+v", slice)
+	}
+	testIntegerLiteral(t, slice.End, 2)
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs[1:2] = [];", "cannot assign to (xs[1:2])"},
+		{"xs[1:2:3:4]", "expected next token to be ], got : instead"},
+		{"xs[1:2", "expected next token to be ], got EOF instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("%s: expected parser errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got %q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestYieldStatementParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"yield 1;", "yield 1;"},
+		{"yield a + b", "yield (a + b);"},
+		{"fn() { yield x; yield y; }", "fn() yield x;yield y;"},
+		{"fn gen(n) { if (n > 0) { yield n; } }", "fn gen(n) if(n > 0) yield n;"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(p, t)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New("yield 5;"))
+	program := p.ParseProgram()
+	checkParserErrors(p, t)
+	stmt, ok := program.Statements[0].(*ast.YieldStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.YieldStatement. got=%T", program.Statements[0])
+	}
+	testIntegerLiteral(t, stmt.Value, 5)
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/token"
+)
+
+// parseTypeAnnotation parses the type after a : that follows the current
+// token, or returns nil without advancing if there is no :. ok is false if
+// the annotation is malformed.
+func (p *Parser) parseTypeAnnotation() (typ ast.TypeExpression, ok bool) {
+	if !p.peekTokenIs(token.COLON) {
+		return nil, true
+	}
+	p.nextToken() // go to :
+	p.nextToken() // go to the type
+	typ = p.parseType()
+	return typ, typ != nil
+}
+
+// parseType parses a type starting at the current token, including
+// alternatives separated by |.
+func (p *Parser) parseType() ast.TypeExpression {
+	tok := p.curToken
+	typ := p.parseNullableType()
+	if typ == nil || !p.peekTokenIs(token.PIPE) {
+		return typ
+	}
+
+	union := &ast.UnionType{Token: tok, Types: []ast.TypeExpression{typ}}
+	for p.peekTokenIs(token.PIPE) {
+		p.nextToken() // go to |
+		p.nextToken() // go to the next alternative
+		typ := p.parseNullableType()
+		if typ == nil {
+			return nil
+		}
+		union.Types = append(union.Types, typ)
+	}
+	return union
+}
+
+func (p *Parser) parseNullableType() ast.TypeExpression {
+	typ := p.parsePrimaryType()
+	for typ != nil && p.peekTokenIs(token.QUESTION) {
+		p.nextToken()
+		typ = &ast.NullableType{Token: p.curToken, Type: typ}
+	}
+	return typ
+}
+
+func (p *Parser) parsePrimaryType() ast.TypeExpression {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.NamedType{Token: p.curToken, Name: p.curToken.Literal}
+
+	case token.LBRACKET:
+		typ := &ast.ArrayType{Token: p.curToken}
+		p.nextToken()
+		if typ.Element = p.parseType(); typ.Element == nil {
+			return nil
+		}
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return typ
+
+	case token.LBRACE:
+		typ := &ast.HashType{Token: p.curToken}
+		p.nextToken()
+		if typ.Key = p.parseType(); typ.Key == nil {
+			return nil
+		}
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		if typ.Value = p.parseType(); typ.Value == nil {
+			return nil
+		}
+		if !p.expectPeek(token.RBRACE) {
+			return nil
+		}
+		return typ
+
+	case token.FUNCTION:
+		return p.parseFunctionType()
+
+	case token.LPAREN:
+		p.nextToken()
+		typ := p.parseType()
+		if typ == nil || !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return typ
+
+	default:
+		msg := fmt.Sprintf("expected a type, got %s", p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+// parseFunctionType parses fn(A, B, ...[C]) -> R. The return type is
+// optional.
+func (p *Parser) parseFunctionType() ast.TypeExpression {
+	typ := &ast.FunctionType{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if typ.Rest = p.parseType(); typ.Rest == nil || !p.checkRestType(typ.Rest) {
+				return nil
+			}
+			break
+		}
+
+		param := p.parseType()
+		if param == nil {
+			return nil
+		}
+		typ.Params = append(typ.Params, param)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken() // go to comma
+	}
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.RARROW) {
+		p.nextToken() // go to ->
+		p.nextToken() // go to the return type
+		if typ.Return = p.parseType(); typ.Return == nil {
+			return nil
+		}
+	}
+	return typ
+}
+
+// checkRestType reports an error unless typ, the type of a rest parameter,
+// is missing or an array type.
+func (p *Parser) checkRestType(typ ast.TypeExpression) bool {
+	if _, ok := typ.(*ast.ArrayType); typ != nil && !ok {
+		msg := fmt.Sprintf("rest parameter type must be an array type, got %s", typ)
+		p.errors = append(p.errors, msg)
+		return false
+	}
+	return true
+}
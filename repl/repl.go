@@ -1,28 +1,40 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 
+	"github.com/ekediala/jian/ast"
 	"github.com/ekediala/jian/evaluator"
 	"github.com/ekediala/jian/lexer"
 	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/optimizer"
 	"github.com/ekediala/jian/parser"
 )
 
 const PROMPT = ">> "
 
+// Start runs an interactive session. Source lines are read from in, and both
+// results and program output are written to out. Programs calling input or
+// read_line consume the lines that follow on in.
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
 	env := object.NewEnvironment()
+	env.SetIO(object.NewIO(in, out, out))
+	StartWithEnv(env)
+}
+
+// StartWithEnv runs an interactive session in env, reading source lines from
+// the environment's standard input and writing results to its output.
+func StartWithEnv(env *object.Environment) {
+	streams := env.IO()
+	out := streams.Stdout
 
 	for {
 		fmt.Fprintf(out, PROMPT)
-		if ok := scanner.Scan(); !ok {
+		line, err := streams.ReadLine()
+		if err != nil {
 			break
 		}
-		line := scanner.Text()
 		l := lexer.New(line)
 		p := parser.New(l)
 
@@ -32,7 +44,18 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
+		prepared, err := prepare(program, env)
+		if err != nil {
+			fmt.Fprintf(out, "ERROR: %s\n", err)
+			continue
+		}
+
+		evaluated := evaluator.Eval(prepared, env)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			io.WriteString(out, errObj.Traceback())
+			io.WriteString(out, "\n")
+			continue
+		}
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
@@ -41,6 +64,18 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
+// prepare readies a parsed program for evaluation. It binds the macros the
+// program defines in env, so they stay available to later input, expands
+// their call sites and optimizes the result.
+func prepare(program *ast.Program, env *object.Environment) (*ast.Program, error) {
+	evaluator.DefineMacros(program, env)
+	expanded, err := evaluator.ExpandMacros(program, env)
+	if err != nil {
+		return nil, err
+	}
+	return optimizer.OptimizeInput(expanded.(*ast.Program)), nil
+}
+
 const MONKEY_FACE = `            __,__
    .--.  .-"     "-.  .--.
   / .. \/  .-. .-.  \/ .. \
@@ -0,0 +1,45 @@
+package repl_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ekediala/jian/repl"
+)
+
+func TestStartWritesToOut(t *testing.T) {
+	in := strings.NewReader("puts(\"hi\");\nlet name = input();\njian\nname + \"!\";\n")
+	var out bytes.Buffer
+
+	repl.Start(in, &out)
+
+	expected := ">> hi\nnull\n>> >> jian!\n>> "
+	if got := out.String(); got != expected {
+		t.Errorf("expected output %q, got %q", expected, got)
+	}
+}
+
+func TestStartKeepsMacros(t *testing.T) {
+	in := strings.NewReader("let twice = macro(x) { quote(unquote(x) * 2) };\ntwice(21);\n")
+	var out bytes.Buffer
+
+	repl.Start(in, &out)
+
+	expected := ">> >> 42\n>> "
+	if got := out.String(); got != expected {
+		t.Errorf("expected output %q, got %q", expected, got)
+	}
+}
+
+func TestStartSeesRebindings(t *testing.T) {
+	in := strings.NewReader("let x = 1; let f = fn() { x };\nlet x = 2;\nf();\n")
+	var out bytes.Buffer
+
+	repl.Start(in, &out)
+
+	expected := ">> >> >> 2\n>> "
+	if got := out.String(); got != expected {
+		t.Errorf("expected output %q, got %q", expected, got)
+	}
+}
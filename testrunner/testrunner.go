@@ -0,0 +1,224 @@
+// Package testrunner discovers and runs tests written in Jian itself.
+//
+// A test file is any file ending in _test.jian. Every top-level binding whose
+// name starts with test_ and whose value is a function literal is a test.
+// Each test runs in a fresh environment: the whole file is evaluated again
+// and then the test function is called with no arguments. A test fails when
+// the call produces an error, typically from one of the assertion builtins.
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ekediala/jian/ast"
+	"github.com/ekediala/jian/evaluator"
+	"github.com/ekediala/jian/filesystem"
+	"github.com/ekediala/jian/lexer"
+	"github.com/ekediala/jian/object"
+	"github.com/ekediala/jian/optimizer"
+	"github.com/ekediala/jian/parser"
+)
+
+const (
+	FileSuffix = "_test.jian"
+	TestPrefix = "test_"
+)
+
+type Options struct {
+	// Run, when set, only runs tests whose name matches the expression.
+	Run *regexp.Regexp
+	// Out receives the report. Defaults to os.Stdout.
+	Out io.Writer
+	// FS, when set, gives tests access to files through the file builtins.
+	FS filesystem.FS
+}
+
+type Result struct {
+	File     string
+	Name     string
+	Passed   bool
+	Message  string
+	Duration time.Duration
+}
+
+type Summary struct {
+	Results []Result
+	Passed  int
+	Failed  int
+}
+
+func (s *Summary) OK() bool {
+	return s.Failed == 0
+}
+
+// Discover returns every test file below dir in lexical order.
+func Discover(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), FileSuffix) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// Run discovers the test files below dir, runs them and writes a report to
+// opts.Out.
+func Run(dir string, opts Options) (*Summary, error) {
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+
+	files, err := Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{}
+	start := time.Now()
+
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range RunFile(file, string(source), opts) {
+			summary.Results = append(summary.Results, result)
+			report(opts.Out, result)
+			if result.Passed {
+				summary.Passed++
+			} else {
+				summary.Failed++
+			}
+		}
+	}
+
+	status := "ok"
+	if !summary.OK() {
+		status = "FAIL"
+	}
+	fmt.Fprintf(opts.Out, "%s\t%d passed, %d failed (%s)\n",
+		status, summary.Passed, summary.Failed, formatDuration(time.Since(start)))
+
+	return summary, nil
+}
+
+// RunFile runs the tests defined in source. A file that fails to parse is
+// reported as a single failing result. opts.Out is not used.
+func RunFile(file string, source string, opts Options) []Result {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		return []Result{{
+			File:    file,
+			Name:    filepath.Base(file),
+			Message: "parser errors:\n" + strings.Join(errs, "\n"),
+		}}
+	}
+
+	// Macros are expanded once per file; each test then evaluates the
+	// expanded and optimized program in its own environment.
+	macros := object.NewEnvironment()
+	macros.SetFS(opts.FS)
+	evaluator.DefineMacros(program, macros)
+	expanded, err := evaluator.ExpandMacros(program, macros)
+	if err != nil {
+		return []Result{{
+			File:    file,
+			Name:    filepath.Base(file),
+			Message: "macro expansion: " + err.Error(),
+		}}
+	}
+	program = optimizer.Optimize(expanded.(*ast.Program))
+
+	var results []Result
+	for _, name := range testNames(program) {
+		if opts.Run != nil && !opts.Run.MatchString(name) {
+			continue
+		}
+		results = append(results, runTest(file, program, name, opts.FS))
+	}
+
+	return results
+}
+
+func runTest(file string, program *ast.Program, name string, fsys filesystem.FS) Result {
+	result := Result{File: file, Name: name}
+	start := time.Now()
+
+	env := object.NewEnvironment()
+	env.SetFS(fsys)
+	evaluated := evaluator.Eval(program, env)
+	if !isError(evaluated) {
+		fn, _ := env.Get(name)
+		evaluated = evaluator.Apply(fn, nil, env)
+	}
+
+	result.Duration = time.Since(start)
+	if errObj, ok := evaluated.(*object.Error); ok {
+		result.Message = errObj.Traceback()
+		return result
+	}
+
+	result.Passed = true
+	return result
+}
+
+// testNames returns the names of the top-level test functions in the order
+// they are declared.
+func testNames(program *ast.Program) []string {
+	var names []string
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			if _, ok := stmt.Value.(*ast.FunctionLiteral); ok && stmt.Name != nil && strings.HasPrefix(stmt.Name.Value, TestPrefix) {
+				names = append(names, stmt.Name.Value)
+			}
+		case *ast.FunctionStatement:
+			if strings.HasPrefix(stmt.Name.Value, TestPrefix) {
+				names = append(names, stmt.Name.Value)
+			}
+		}
+	}
+	return names
+}
+
+func report(out io.Writer, r Result) {
+	status := "PASS"
+	if !r.Passed {
+		status = "FAIL"
+	}
+	fmt.Fprintf(out, "--- %s: %s %s (%s)\n", status, r.File, r.Name, formatDuration(r.Duration))
+	if r.Message != "" {
+		for _, line := range strings.Split(r.Message, "\n") {
+			fmt.Fprintf(out, "    %s\n", line)
+		}
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR
+}
@@ -0,0 +1,189 @@
+package testrunner_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ekediala/jian/testrunner"
+)
+
+const mathTests = `
+let add = fn(a, b) { a + b };
+
+let test_add = fn() {
+	assert_eq(add(1, 2), 3);
+};
+
+let test_broken_add = fn() {
+	assert_eq(add(1, 2), 4, "one plus two");
+};
+
+let test_errors = fn() {
+	assert_error(fn() { 1 + true }, "type mismatch");
+};
+
+let helper = fn() { assert(false) };
+`
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "math_test.jian", mathTests)
+	writeFile(t, dir, "math.jian", "1")
+	writeFile(t, filepath.Join(dir, "nested"), "strings_test.jian", "")
+
+	files, err := testrunner.Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "math_test.jian"),
+		filepath.Join(dir, "nested", "strings_test.jian"),
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %v", len(expected), files)
+	}
+	for i, file := range expected {
+		if files[i] != file {
+			t.Errorf("files[%d]: expected %q, got %q", i, file, files[i])
+		}
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	results := testrunner.RunFile("math_test.jian", mathTests, testrunner.Options{})
+
+	expected := []struct {
+		name    string
+		passed  bool
+		message string
+	}{
+		{"test_add", true, ""},
+		{"test_broken_add", false, "assert_eq failed: one plus two\n    got:  3\n    want: 4"},
+		{"test_errors", true, ""},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+
+	for i, tt := range expected {
+		r := results[i]
+		if r.Name != tt.name {
+			t.Errorf("results[%d]: expected name %q, got %q", i, tt.name, r.Name)
+		}
+		if r.Passed != tt.passed {
+			t.Errorf("%s: expected passed=%t, got %t", tt.name, tt.passed, r.Passed)
+		}
+		if r.Message != tt.message {
+			t.Errorf("%s: expected message %q, got %q", tt.name, tt.message, r.Message)
+		}
+	}
+}
+
+func TestRunFileFreshEnvironment(t *testing.T) {
+	source := `
+let counter = [];
+let test_first = fn() { let counter = push(counter, 1); assert_eq(len(counter), 1); };
+let test_second = fn() { assert_eq(len(counter), 0); };
+`
+	for _, r := range testrunner.RunFile("env_test.jian", source, testrunner.Options{}) {
+		if !r.Passed {
+			t.Errorf("%s: expected to pass, got %q", r.Name, r.Message)
+		}
+	}
+}
+
+func TestRunFileMacros(t *testing.T) {
+	source := `
+let check = macro(cond) { quote(assert(unquote(cond), "check failed")) };
+let test_passes = fn() { check(1 < 2) };
+let test_fails = fn() { check(2 < 1) };
+`
+	results := testrunner.RunFile("macro_test.jian", source, testrunner.Options{})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if !results[0].Passed {
+		t.Errorf("test_passes: expected to pass, got %q", results[0].Message)
+	}
+	if results[1].Passed || !strings.Contains(results[1].Message, "check failed") {
+		t.Errorf("test_fails: expected failure from check, got %+v", results[1])
+	}
+
+	results = testrunner.RunFile("macro_test.jian", "let m = macro() { 1 }; let test_m = fn() { m() };", testrunner.Options{})
+	if len(results) != 1 || results[0].Passed || !strings.HasPrefix(results[0].Message, "macro expansion:") {
+		t.Errorf("expected a macro expansion failure, got %+v", results)
+	}
+}
+
+func TestRunFileDeclaredFunctions(t *testing.T) {
+	source := `
+fn test_declared() { check(2) }
+fn check(n) { assert_eq(n, 1) }
+`
+	results := testrunner.RunFile("decl_test.jian", source, testrunner.Options{})
+	if len(results) != 1 || results[0].Name != "test_declared" {
+		t.Fatalf("expected test_declared to run, got %+v", results)
+	}
+	expected := "assert_eq failed\n    got:  2\n    want: 1\n    at check (2:27)"
+	if results[0].Message != expected {
+		t.Errorf("expected message %q, got %q", expected, results[0].Message)
+	}
+}
+
+func TestRunFileFilter(t *testing.T) {
+	results := testrunner.RunFile("math_test.jian", mathTests, testrunner.Options{Run: regexp.MustCompile("err")})
+	if len(results) != 1 || results[0].Name != "test_errors" {
+		t.Fatalf("expected only test_errors to run, got %+v", results)
+	}
+}
+
+func TestRunFileParseError(t *testing.T) {
+	results := testrunner.RunFile("bad_test.jian", "let = 5;", testrunner.Options{})
+	if len(results) != 1 || results[0].Passed {
+		t.Fatalf("expected a single failing result, got %+v", results)
+	}
+	if !strings.HasPrefix(results[0].Message, "parser errors:") {
+		t.Errorf("expected parser errors, got %q", results[0].Message)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "math_test.jian", mathTests)
+
+	var out strings.Builder
+	summary, err := testrunner.Run(dir, testrunner.Options{Out: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.OK() || summary.Passed != 2 || summary.Failed != 1 {
+		t.Errorf("unexpected summary: %d passed, %d failed", summary.Passed, summary.Failed)
+	}
+
+	report := out.String()
+	for _, want := range []string{
+		"--- PASS: " + filepath.Join(dir, "math_test.jian") + " test_add (",
+		"--- FAIL: " + filepath.Join(dir, "math_test.jian") + " test_broken_add (",
+		"FAIL\t2 passed, 1 failed",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+}
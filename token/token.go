@@ -9,6 +9,8 @@ func (t TokenType) String() string {
 type Token struct {
 	Literal string
 	Type    TokenType
+	Line    int // 1-based line of the first character, 0 if unknown
+	Column  int // 1-based byte offset of the first character within its line
 }
 
 const (
@@ -33,6 +35,11 @@ const (
 	GT       = ">"
 	EQ       = "=="
 	NOT_EQ   = "!="
+	ARROW    = "=>"
+	PIPE     = "|"
+	RARROW   = "->"
+	QUESTION = "?"
+	COALESCE = "??"
 
 	// Delimiters
 	COMMA     = ","
@@ -44,25 +51,52 @@ const (
 	LBRACKET  = "["
 	RBRACKET  = "]"
 	COLON     = ":"
+	ELLIPSIS  = "..."
+	DOT       = "."
+
+	OPTIONAL_DOT      = "?."
+	OPTIONAL_LBRACKET = "?["
 
 	// Keywords
 	LET      = "LET"
+	CONST    = "CONST"
 	FUNCTION = "FUNCTION"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	YIELD    = "YIELD"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	SELECT   = "SELECT"
+	CASE     = "CASE"
+	DEFAULT  = "DEFAULT"
+	MACRO    = "MACRO"
+	MATCH    = "MATCH"
+	STRUCT   = "STRUCT"
+	CLASS    = "CLASS"
+	EXTENDS  = "EXTENDS"
+	IS       = "IS"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"true":   TRUE,
-	"false":  FALSE,
+	"fn":      FUNCTION,
+	"let":     LET,
+	"const":   CONST,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"yield":   YIELD,
+	"true":    TRUE,
+	"false":   FALSE,
+	"select":  SELECT,
+	"case":    CASE,
+	"default": DEFAULT,
+	"macro":   MACRO,
+	"match":   MATCH,
+	"struct":  STRUCT,
+	"class":   CLASS,
+	"extends": EXTENDS,
+	"is":      IS,
 }
 
 func LookupIdent(ident string) TokenType {